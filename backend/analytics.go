@@ -0,0 +1,306 @@
+package main
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// PROGRESSION ANALYTICS
+// =====================
+
+const defaultRollingWindow = 3
+
+// raceTime is a single parsed result used by the analytics calculations.
+type raceTime struct {
+	ResultID    int32
+	MeetID      int32
+	MeetName    string
+	Date        time.Time
+	EventTypeID int32
+	Event       string
+	Time        string
+	Seconds     float64
+}
+
+// season returns the cross country season a race belongs to. Seasons run
+// within a calendar year, so the meet year is used directly.
+func (r raceTime) season() int {
+	return r.Date.Year()
+}
+
+type progressionPoint struct {
+	ResultID              int32   `json:"resultId"`
+	MeetID                int32   `json:"meetId"`
+	MeetName              string  `json:"meetName"`
+	Date                  string  `json:"date"`
+	Season                int     `json:"season"`
+	Time                  string  `json:"time"`
+	Seconds               float64 `json:"seconds"`
+	RollingAverage        string  `json:"rollingAverage"`
+	RollingAverageSeconds float64 `json:"rollingAverageSeconds"`
+	SinceOpenerSeconds    float64 `json:"sinceOpenerSeconds"`
+}
+
+type seasonSummary struct {
+	Season             int     `json:"season"`
+	Races              int     `json:"races"`
+	OpenerTime         string  `json:"openerTime"`
+	LatestTime         string  `json:"latestTime"`
+	BestTime           string  `json:"bestTime"`
+	AverageTime        string  `json:"averageTime"`
+	ImprovementSeconds float64 `json:"improvementSeconds"`
+	ImprovementPercent float64 `json:"improvementPercent"`
+	StdDevSeconds      float64 `json:"stdDevSeconds"`
+}
+
+type yearOverYear struct {
+	Season             int     `json:"season"`
+	PreviousSeason     int     `json:"previousSeason"`
+	BestTime           string  `json:"bestTime"`
+	PreviousBestTime   string  `json:"previousBestTime"`
+	ImprovementSeconds float64 `json:"improvementSeconds"`
+	PercentImprovement float64 `json:"percentImprovement"`
+}
+
+type eventProgression struct {
+	EventTypeID   int32              `json:"eventTypeId"`
+	Event         string             `json:"event"`
+	Races         int                `json:"races"`
+	BestTime      string             `json:"bestTime"`
+	StdDevSeconds float64            `json:"stdDevSeconds"`
+	Series        []progressionPoint `json:"series"`
+	Seasons       []seasonSummary    `json:"seasons"`
+	YearOverYear  []yearOverYear     `json:"yearOverYear"`
+}
+
+func (srv *server) getAthleteProgressionHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid athlete ID"})
+		return
+	}
+
+	window := defaultRollingWindow
+	if w := c.Query("window"); w != "" {
+		window, err = strconv.Atoi(w)
+		if err != nil || window < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rolling window"})
+			return
+		}
+	}
+
+	athlete, err := srv.repo.GetAthleteByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Athlete not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	names, err := srv.loadAthleteNames(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkAthleteVisible(c, names, athlete.ID) {
+		return
+	}
+
+	results, err := srv.repo.GetAthleteResults(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	races := make([]raceTime, 0, len(results))
+	for _, r := range results {
+		seconds, err := parseRaceTime(r.Time)
+		if err != nil {
+			continue
+		}
+		races = append(races, raceTime{
+			ResultID:    r.ID,
+			MeetID:      r.MeetID,
+			MeetName:    r.MeetName,
+			Date:        r.MeetDate,
+			EventTypeID: r.EventTypeID.Int32,
+			Event:       r.EventName.String,
+			Time:        r.Time,
+			Seconds:     seconds,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"athleteId":   athlete.ID,
+		"athleteName": names.name(athlete.ID, athlete.Name),
+		"window":      window,
+		"events":      buildProgression(races, window),
+	})
+}
+
+// buildProgression groups races by event type and computes the chart series
+// and summary statistics for each group.
+func buildProgression(races []raceTime, window int) []eventProgression {
+	byEvent := make(map[int32][]raceTime)
+	var order []int32
+	for _, r := range races {
+		if _, ok := byEvent[r.EventTypeID]; !ok {
+			order = append(order, r.EventTypeID)
+		}
+		byEvent[r.EventTypeID] = append(byEvent[r.EventTypeID], r)
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+
+	events := make([]eventProgression, 0, len(order))
+	for _, eventTypeID := range order {
+		group := byEvent[eventTypeID]
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Date.Before(group[j].Date) })
+
+		seconds := raceSeconds(group)
+		ep := eventProgression{
+			EventTypeID:   eventTypeID,
+			Event:         group[0].Event,
+			Races:         len(group),
+			BestTime:      formatRaceTime(minSeconds(seconds)),
+			StdDevSeconds: round1(stdDev(seconds)),
+			Series:        make([]progressionPoint, len(group)),
+		}
+
+		openers := make(map[int]float64)
+		for i, r := range group {
+			if _, ok := openers[r.season()]; !ok {
+				openers[r.season()] = r.Seconds
+			}
+			avg := mean(seconds[max(0, i-window+1) : i+1])
+			ep.Series[i] = progressionPoint{
+				ResultID:              r.ResultID,
+				MeetID:                r.MeetID,
+				MeetName:              r.MeetName,
+				Date:                  r.Date.Format("2006-01-02"),
+				Season:                r.season(),
+				Time:                  r.Time,
+				Seconds:               r.Seconds,
+				RollingAverage:        formatRaceTime(avg),
+				RollingAverageSeconds: round1(avg),
+				SinceOpenerSeconds:    round1(openers[r.season()] - r.Seconds),
+			}
+		}
+
+		ep.Seasons = summarizeSeasons(group)
+		ep.YearOverYear = compareSeasons(ep.Seasons, group)
+		events = append(events, ep)
+	}
+	return events
+}
+
+// summarizeSeasons expects races sorted by date.
+func summarizeSeasons(races []raceTime) []seasonSummary {
+	var summaries []seasonSummary
+	for start := 0; start < len(races); {
+		end := start
+		for end < len(races) && races[end].season() == races[start].season() {
+			end++
+		}
+		seconds := raceSeconds(races[start:end])
+		opener, best := seconds[0], minSeconds(seconds)
+		summaries = append(summaries, seasonSummary{
+			Season:             races[start].season(),
+			Races:              end - start,
+			OpenerTime:         races[start].Time,
+			LatestTime:         races[end-1].Time,
+			BestTime:           formatRaceTime(best),
+			AverageTime:        formatRaceTime(mean(seconds)),
+			ImprovementSeconds: round1(opener - best),
+			ImprovementPercent: round2(percentFaster(opener, best)),
+			StdDevSeconds:      round1(stdDev(seconds)),
+		})
+		start = end
+	}
+	return summaries
+}
+
+// compareSeasons reports the change in season best between consecutive
+// seasons in which the athlete raced.
+func compareSeasons(seasons []seasonSummary, races []raceTime) []yearOverYear {
+	bests := make(map[int]float64)
+	for _, r := range races {
+		if b, ok := bests[r.season()]; !ok || r.Seconds < b {
+			bests[r.season()] = r.Seconds
+		}
+	}
+
+	comparisons := make([]yearOverYear, 0)
+	for i := 1; i < len(seasons); i++ {
+		prev, cur := seasons[i-1].Season, seasons[i].Season
+		comparisons = append(comparisons, yearOverYear{
+			Season:             cur,
+			PreviousSeason:     prev,
+			BestTime:           formatRaceTime(bests[cur]),
+			PreviousBestTime:   formatRaceTime(bests[prev]),
+			ImprovementSeconds: round1(bests[prev] - bests[cur]),
+			PercentImprovement: round2(percentFaster(bests[prev], bests[cur])),
+		})
+	}
+	return comparisons
+}
+
+func raceSeconds(races []raceTime) []float64 {
+	seconds := make([]float64, len(races))
+	for i, r := range races {
+		seconds[i] = r.Seconds
+	}
+	return seconds
+}
+
+// percentFaster returns how much faster "to" is than "from" as a percentage
+// of "from". Slower times yield a negative value.
+func percentFaster(from, to float64) float64 {
+	if from == 0 {
+		return 0
+	}
+	return (from - to) / from * 100
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func minSeconds(values []float64) float64 {
+	best := math.Inf(1)
+	for _, v := range values {
+		best = math.Min(best, v)
+	}
+	if math.IsInf(best, 1) {
+		return 0
+	}
+	return best
+}
+
+// stdDev is the population standard deviation, used as a consistency
+// measure: lower means more consistent races.
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
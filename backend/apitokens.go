@@ -0,0 +1,341 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// API TOKENS
+// =====================
+
+const (
+	// apiTokenPrefix tells API tokens apart from session tokens, which are
+	// sent the same way.
+	apiTokenPrefix        = "xct_"
+	apiTokenDefaultExpiry = 90
+	contextAPITokenKey    = "apiToken"
+
+	scopeReadAll = "read:all"
+)
+
+// tokenScopes maps each scope a token may be given to the permissions it
+// grants. A token never gets more than its owner's role allows, and never
+// the account permission, so it can't change passwords or mint more tokens.
+var tokenScopes = map[string][]permission{
+	scopeReadAll:               {permViewAllAthletes, permViewOwnAthletes, permViewAudit},
+	string(permEnterResults):   {permEnterResults},
+	string(permManageRoster):   {permManageRoster},
+	string(permManageMeets):    {permManageMeets},
+	string(permManageSettings): {permManageSettings},
+}
+
+func newAPIToken() (token, tokenHash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token = apiTokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return token, hashAPIToken(token), nil
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func tokenAllows(token db.ApiToken, perm permission) bool {
+	for _, scope := range strings.Fields(token.Scopes) {
+		if slices.Contains(tokenScopes[scope], perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadAPIToken resolves a bearer API token to its owner. It returns
+// sql.ErrNoRows for unknown, revoked or expired tokens and inactive owners.
+func (srv *server) loadAPIToken(c *gin.Context, token string) (db.ApiToken, db.User, error) {
+	ctx := c.Request.Context()
+	apiToken, err := srv.repo.GetAPITokenByHash(ctx, hashAPIToken(token))
+	if err != nil {
+		return db.ApiToken{}, db.User{}, err
+	}
+	if apiToken.RevokedAt.Valid || (apiToken.ExpiresAt.Valid && srv.now().After(apiToken.ExpiresAt.Time)) {
+		return db.ApiToken{}, db.User{}, sql.ErrNoRows
+	}
+
+	user, err := srv.repo.GetUserByID(ctx, apiToken.UserID)
+	if err != nil {
+		return db.ApiToken{}, db.User{}, err
+	}
+	if !user.Active {
+		return db.ApiToken{}, db.User{}, sql.ErrNoRows
+	}
+
+	if !apiToken.LastUsedAt.Valid || srv.now().Sub(apiToken.LastUsedAt.Time) > sessionTouchInterval {
+		now := sql.NullTime{Time: srv.now().UTC().Truncate(time.Second), Valid: true}
+		err := srv.repo.TouchAPIToken(ctx, db.TouchAPITokenParams{
+			LastUsedAt: now,
+			LastUsedIp: sql.NullString{String: c.ClientIP(), Valid: c.ClientIP() != ""},
+			ID:         apiToken.ID,
+		})
+		if err == nil {
+			apiToken.LastUsedAt = now
+		}
+	}
+	return apiToken, user, nil
+}
+
+func currentAPIToken(c *gin.Context) (db.ApiToken, bool) {
+	v, ok := c.Get(contextAPITokenKey)
+	if !ok {
+		return db.ApiToken{}, false
+	}
+	token, ok := v.(db.ApiToken)
+	return token, ok
+}
+
+// checkTokenMeet aborts the request and returns false when it was made with
+// an API token limited to a different meet.
+func checkTokenMeet(c *gin.Context, meetID int32) bool {
+	token, ok := currentAPIToken(c)
+	if !ok || !token.MeetID.Valid || token.MeetID.Int32 == meetID {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": "This API token is limited to meet " + strconv.Itoa(int(token.MeetID.Int32)),
+	})
+	return false
+}
+
+func apiTokenResponse(t db.ApiToken) gin.H {
+	response := gin.H{
+		"id":         t.ID,
+		"userId":     t.UserID,
+		"name":       t.Name,
+		"prefix":     t.TokenPrefix,
+		"scopes":     strings.Fields(t.Scopes),
+		"meetId":     nil,
+		"expiresAt":  nil,
+		"lastUsedAt": nil,
+		"lastUsedIp": t.LastUsedIp.String,
+		"revoked":    t.RevokedAt.Valid,
+		"createdAt":  t.CreatedAt.Time,
+	}
+	if t.MeetID.Valid {
+		response["meetId"] = t.MeetID.Int32
+	}
+	if t.ExpiresAt.Valid {
+		response["expiresAt"] = t.ExpiresAt.Time
+	}
+	if t.LastUsedAt.Valid {
+		response["lastUsedAt"] = t.LastUsedAt.Time
+	}
+	return response
+}
+
+type APITokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	MeetID        int32    `json:"meetId"`
+	ExpiresInDays int      `json:"expiresInDays" binding:"omitempty,min=1,max=365"`
+}
+
+// issueAPIToken creates a token owned by owner on behalf of the signed-in
+// user and writes the response, which is the only time the token is shown.
+func (srv *server) issueAPIToken(c *gin.Context, owner db.User) {
+	var req APITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		perms, ok := tokenScopes[scope]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope " + scope})
+			return
+		}
+		for _, perm := range perms {
+			// read:all only grants what the role has; write scopes must be
+			// fully held.
+			if scope != scopeReadAll && !hasPermission(owner.Role, perm) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "The " + owner.Role + " role cannot be given the " + scope + " scope"})
+				return
+			}
+		}
+	}
+	if req.MeetID > 0 {
+		if !slices.Contains(req.Scopes, string(permEnterResults)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "meetId only applies to the results:write scope"})
+			return
+		}
+		if _, err := srv.repo.GetMeetByID(c.Request.Context(), req.MeetID); err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Meet not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = apiTokenDefaultExpiry
+	}
+	// Otherwise a token would be a way around the enrollment requirement.
+	if twoFactorRequired(owner.Role) {
+		enabled, err := srv.twoFactorEnabled(c.Request.Context(), owner.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !enabled {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":                  "Set up two-factor authentication before creating API tokens",
+				"twoFactorSetupRequired": true,
+			})
+			return
+		}
+	}
+
+	token, tokenHash, err := newAPIToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	issuer, _ := currentUser(c)
+	slices.Sort(req.Scopes)
+	expires := srv.now().AddDate(0, 0, req.ExpiresInDays).UTC().Truncate(time.Second)
+
+	result, err := srv.repo.CreateAPIToken(c.Request.Context(), db.CreateAPITokenParams{
+		UserID:      owner.ID,
+		Name:        req.Name,
+		TokenHash:   tokenHash,
+		TokenPrefix: token[:len(apiTokenPrefix)+6],
+		Scopes:      strings.Join(slices.Compact(req.Scopes), " "),
+		MeetID:      sql.NullInt32{Int32: req.MeetID, Valid: req.MeetID > 0},
+		ExpiresAt:   sql.NullTime{Time: expires, Valid: true},
+		CreatedBy:   sql.NullInt32{Int32: issuer.ID, Valid: true},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	created, err := srv.repo.GetAPITokenByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	response := apiTokenResponse(created)
+	response["token"] = token
+	c.JSON(http.StatusCreated, response)
+}
+
+func (srv *server) listAPITokens(c *gin.Context, userID int32) {
+	tokens, err := srv.repo.GetUserAPITokens(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]gin.H, len(tokens))
+	for i, t := range tokens {
+		response[i] = apiTokenResponse(t)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// revokeAPIToken revokes one of userID's tokens given by the tokenId param.
+func (srv *server) revokeAPIToken(c *gin.Context, userID int32) {
+	id, err := strconv.Atoi(c.Param("tokenId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	token, err := srv.repo.GetAPITokenByID(c.Request.Context(), int32(id))
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err == sql.ErrNoRows || token.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+
+	if err := srv.repo.RevokeAPIToken(c.Request.Context(), token.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked"})
+}
+
+func (srv *server) getMyAPITokensHandler(c *gin.Context) {
+	user, _ := currentUser(c)
+	srv.listAPITokens(c, user.ID)
+}
+
+func (srv *server) createMyAPITokenHandler(c *gin.Context) {
+	user, _ := currentUser(c)
+	srv.issueAPIToken(c, user)
+}
+
+func (srv *server) revokeMyAPITokenHandler(c *gin.Context) {
+	user, _ := currentUser(c)
+	srv.revokeAPIToken(c, user.ID)
+}
+
+// tokenOwner loads the user named by the id param for the service token
+// routes, answering 404 if there is none.
+func (srv *server) tokenOwner(c *gin.Context) (db.User, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return db.User{}, false
+	}
+	user, err := srv.repo.GetUserByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return db.User{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return db.User{}, false
+	}
+	return user, true
+}
+
+// The /api/users/:id/tokens routes let a head coach manage service tokens,
+// e.g. for a "timing" account used by the finish-line laptop.
+func (srv *server) getUserAPITokensHandler(c *gin.Context) {
+	if owner, ok := srv.tokenOwner(c); ok {
+		srv.listAPITokens(c, owner.ID)
+	}
+}
+
+func (srv *server) createUserAPITokenHandler(c *gin.Context) {
+	if owner, ok := srv.tokenOwner(c); ok {
+		srv.issueAPIToken(c, owner)
+	}
+}
+
+func (srv *server) revokeUserAPITokenHandler(c *gin.Context) {
+	if owner, ok := srv.tokenOwner(c); ok {
+		srv.revokeAPIToken(c, owner.ID)
+	}
+}
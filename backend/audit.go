@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// AUDIT LOG
+// =====================
+
+const (
+	auditCreate = "create"
+	auditUpdate = "update"
+	auditDelete = "delete"
+
+	auditAthlete   = "athlete"
+	auditMeet      = "meet"
+	auditResult    = "result"
+	auditEventType = "event_type"
+	// Race conditions are logged against their meet's ID; the snapshots
+	// carry the event type.
+	auditRaceConditions = "race_conditions"
+
+	auditDefaultLimit = 100
+	auditMaxLimit     = 500
+)
+
+var auditEntities = []string{auditAthlete, auditMeet, auditResult, auditEventType, auditRaceConditions}
+
+// recordAudit appends an entry to the audit log for the signed-in user.
+// before is nil for a create and after is nil for a delete. Pass the
+// transaction's queries so the entry is only kept if the change is.
+func recordAudit(c *gin.Context, q db.Querier, entity string, id int32, before, after any) error {
+	action := auditUpdate
+	switch {
+	case before == nil:
+		action = auditCreate
+	case after == nil:
+		action = auditDelete
+	}
+
+	beforeData, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	afterData, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+
+	params := db.CreateAuditEntryParams{
+		Action:     action,
+		EntityType: entity,
+		EntityID:   id,
+		BeforeData: beforeData,
+		AfterData:  afterData,
+	}
+	if user, ok := currentUser(c); ok {
+		params.UserID = sql.NullInt32{Int32: user.ID, Valid: true}
+		params.Username = sql.NullString{String: user.Username, Valid: true}
+	}
+	return q.CreateAuditEntry(c.Request.Context(), params)
+}
+
+func auditJSON(v any) (sql.NullString, error) {
+	if v == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// resultSnapshot is the form results are recorded in. Names of the athlete,
+// meet and event are left out since those have their own history.
+func resultSnapshot(r db.Result) gin.H {
+	return gin.H{
+		"id":          r.ID,
+		"athleteId":   r.AthleteID,
+		"meetId":      r.MeetID,
+		"eventTypeId": r.EventTypeID.Int32,
+		"time":        r.Time,
+		"place":       r.Place.Int32,
+	}
+}
+
+func resultFromRow(r db.GetResultByIDRow) db.Result {
+	return db.Result{
+		ID:          r.ID,
+		AthleteID:   r.AthleteID,
+		MeetID:      r.MeetID,
+		EventTypeID: r.EventTypeID,
+		Time:        r.Time,
+		Place:       r.Place,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+// recordResultDeletes logs results that are about to be removed along with
+// their athlete or meet, so result history doesn't silently end.
+func recordResultDeletes(c *gin.Context, q db.Querier, results []db.Result) error {
+	for _, r := range results {
+		if err := recordAudit(c, q, auditResult, r.ID, resultSnapshot(r), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordResultsDetached logs results whose event type is about to be
+// deleted. The database keeps them but clears their event type.
+func recordResultsDetached(c *gin.Context, q db.Querier, results []db.Result) error {
+	for _, r := range results {
+		after := r
+		after.EventTypeID = sql.NullInt32{}
+		if err := recordAudit(c, q, auditResult, r.ID, resultSnapshot(r), resultSnapshot(after)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func auditEntryResponse(e db.AuditLog) gin.H {
+	response := gin.H{
+		"id":        e.ID,
+		"userId":    nil,
+		"username":  e.Username.String,
+		"action":    e.Action,
+		"entity":    e.EntityType,
+		"entityId":  e.EntityID,
+		"before":    rawJSON(e.BeforeData),
+		"after":     rawJSON(e.AfterData),
+		"changedAt": e.CreatedAt.Time,
+	}
+	if e.UserID.Valid {
+		response["userId"] = e.UserID.Int32
+	}
+	if e.Action == auditUpdate {
+		response["changed"] = changedFields(e.BeforeData, e.AfterData)
+	}
+	return response
+}
+
+func rawJSON(s sql.NullString) json.RawMessage {
+	if !s.Valid {
+		return nil
+	}
+	return json.RawMessage(s.String)
+}
+
+// changedFields lists the top-level fields that differ between two snapshots.
+func changedFields(before, after sql.NullString) []string {
+	var b, a map[string]json.RawMessage
+	json.Unmarshal([]byte(before.String), &b)
+	json.Unmarshal([]byte(after.String), &a)
+
+	changed := []string{}
+	for key, value := range a {
+		if old, ok := b[key]; !ok || !bytes.Equal(old, value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// parseAuditTime accepts either a date or an RFC 3339 timestamp.
+func parseAuditTime(value string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, value)
+	}
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+}
+
+// getAuditLogHandler lists audit entries, newest first. All filters are
+// optional: entity, entityId, userId, action, since and until (a date or
+// RFC 3339 time; until is exclusive), plus limit and offset for paging.
+func (srv *server) getAuditLogHandler(c *gin.Context) {
+	params := db.ListAuditEntriesParams{
+		EntityType: c.Query("entity"),
+		Action:     c.Query("action"),
+	}
+
+	if params.EntityType != "" && !slices.Contains(auditEntities, params.EntityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity", "allowed": auditEntities})
+		return
+	}
+	if params.Action != "" && params.Action != auditCreate && params.Action != auditUpdate && params.Action != auditDelete {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action, expected create, update or delete"})
+		return
+	}
+
+	ints := []struct {
+		name string
+		dest *int32
+	}{
+		{"entityId", &params.EntityID},
+		{"userId", &params.UserID},
+		{"limit", &params.Limit},
+		{"offset", &params.Offset},
+	}
+	for _, p := range ints {
+		value := c.Query(p.name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + p.name})
+			return
+		}
+		*p.dest = int32(n)
+	}
+	switch {
+	case params.Limit == 0:
+		params.Limit = auditDefaultLimit
+	case params.Limit > auditMaxLimit:
+		params.Limit = auditMaxLimit
+	}
+
+	var err error
+	if params.Since, err = parseAuditTime(c.Query("since")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since, expected YYYY-MM-DD or an RFC 3339 time"})
+		return
+	}
+	if params.Until, err = parseAuditTime(c.Query("until")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid until, expected YYYY-MM-DD or an RFC 3339 time"})
+		return
+	}
+
+	entries, err := srv.repo.ListAuditEntries(c.Request.Context(), params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]gin.H, len(entries))
+	for i, e := range entries {
+		response[i] = auditEntryResponse(e)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"entries": response,
+		"limit":   params.Limit,
+		"offset":  params.Offset,
+	})
+}
+
+// getEntityHistoryHandler returns the full history of one record, oldest
+// first, including its deletion.
+func (srv *server) getEntityHistoryHandler(c *gin.Context) {
+	entity := c.Param("entity")
+	if !slices.Contains(auditEntities, entity) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity", "allowed": auditEntities})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	entries, err := srv.repo.GetEntityAuditEntries(c.Request.Context(), db.GetEntityAuditEntriesParams{
+		EntityType: entity,
+		EntityID:   int32(id),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]gin.H, len(entries))
+	for i, e := range entries {
+		response[i] = auditEntryResponse(e)
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,492 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// AWARD RULES
+// =====================
+
+const (
+	awardLetter        = "letter"
+	awardMostImproved  = "most_improved"
+	awardTopNewcomer   = "top_newcomer"
+	criterionVarsity   = "varsity_races"
+	criterionChampions = "championship_score"
+
+	// varsityRunners is how many of a team's finishers in a race count as
+	// its varsity runners.
+	varsityRunners = 7
+)
+
+type AwardRuleRequest struct {
+	Name        string `json:"name" binding:"required"`
+	AwardType   string `json:"awardType" binding:"required,oneof=letter most_improved top_newcomer"`
+	Criterion   string `json:"criterion" binding:"omitempty,oneof=varsity_races championship_score"`
+	Threshold   int32  `json:"threshold" binding:"omitempty,min=1"`
+	Winners     int32  `json:"winners" binding:"omitempty,min=1"`
+	Division    string `json:"division" binding:"omitempty,oneof=boys girls"`
+	Description string `json:"description"`
+}
+
+func awardRuleResponse(r db.AwardRule) gin.H {
+	return gin.H{
+		"id":          r.ID,
+		"name":        r.Name,
+		"awardType":   r.AwardType,
+		"criterion":   nullableString(r.Criterion),
+		"threshold":   r.Threshold,
+		"winners":     r.Winners,
+		"division":    nullableString(r.Division),
+		"description": r.Description.String,
+	}
+}
+
+// bindAwardRule binds a rule request and fills in defaults. Letter rules need
+// a criterion; the other award types rank athletes and ignore it.
+func bindAwardRule(c *gin.Context) (AwardRuleRequest, bool) {
+	var req AwardRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	if req.AwardType == awardLetter && req.Criterion == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Letter rules require a criterion"})
+		return req, false
+	}
+	if req.AwardType != awardLetter {
+		req.Criterion = ""
+	}
+	if req.Threshold == 0 {
+		req.Threshold = 1
+	}
+	if req.Winners == 0 {
+		req.Winners = 1
+	}
+	return req, true
+}
+
+func (srv *server) getAwardRulesHandler(c *gin.Context) {
+	rules, err := srv.repo.GetAllAwardRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]gin.H, len(rules))
+	for i, r := range rules {
+		response[i] = awardRuleResponse(r)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (srv *server) getAwardRuleByIDHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid award rule ID"})
+		return
+	}
+
+	rule, err := srv.repo.GetAwardRuleByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Award rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, awardRuleResponse(rule))
+}
+
+func (srv *server) createAwardRuleHandler(c *gin.Context) {
+	req, ok := bindAwardRule(c)
+	if !ok {
+		return
+	}
+
+	result, err := srv.repo.CreateAwardRule(c.Request.Context(), db.CreateAwardRuleParams{
+		Name:        req.Name,
+		AwardType:   req.AwardType,
+		Criterion:   sql.NullString{String: req.Criterion, Valid: req.Criterion != ""},
+		Threshold:   req.Threshold,
+		Winners:     req.Winners,
+		Division:    sql.NullString{String: req.Division, Valid: req.Division != ""},
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	c.JSON(http.StatusCreated, gin.H{
+		"id":          id,
+		"name":        req.Name,
+		"awardType":   req.AwardType,
+		"criterion":   req.Criterion,
+		"threshold":   req.Threshold,
+		"winners":     req.Winners,
+		"division":    req.Division,
+		"description": req.Description,
+	})
+}
+
+func (srv *server) updateAwardRuleHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid award rule ID"})
+		return
+	}
+
+	req, ok := bindAwardRule(c)
+	if !ok {
+		return
+	}
+
+	if _, err := srv.repo.GetAwardRuleByID(c.Request.Context(), int32(id)); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Award rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = srv.repo.UpdateAwardRule(c.Request.Context(), db.UpdateAwardRuleParams{
+		ID:          int32(id),
+		Name:        req.Name,
+		AwardType:   req.AwardType,
+		Criterion:   sql.NullString{String: req.Criterion, Valid: req.Criterion != ""},
+		Threshold:   req.Threshold,
+		Winners:     req.Winners,
+		Division:    sql.NullString{String: req.Division, Valid: req.Division != ""},
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          id,
+		"name":        req.Name,
+		"awardType":   req.AwardType,
+		"criterion":   req.Criterion,
+		"threshold":   req.Threshold,
+		"winners":     req.Winners,
+		"division":    req.Division,
+		"description": req.Description,
+	})
+}
+
+func (srv *server) deleteAwardRuleHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid award rule ID"})
+		return
+	}
+
+	if _, err := srv.repo.GetAwardRuleByID(c.Request.Context(), int32(id)); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Award rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = srv.repo.DeleteAwardRule(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Award rule deleted"})
+}
+
+// =====================
+// AWARDS REPORT
+// =====================
+
+type awardEarned struct {
+	RuleID    int32  `json:"ruleId"`
+	Name      string `json:"name"`
+	AwardType string `json:"awardType"`
+	Reason    string `json:"reason"`
+}
+
+// athleteSeason collects what the awards rules need to know about one
+// athlete's season.
+type athleteSeason struct {
+	AthleteID          int32         `json:"athleteId"`
+	Name               string        `json:"name"`
+	Grade              int32         `json:"grade"`
+	Division           string        `json:"division"`
+	Races              int           `json:"races"`
+	VarsityRaces       int           `json:"varsityRaces"`
+	ChampionshipScores int           `json:"championshipScores"`
+	Newcomer           bool          `json:"newcomer"`
+	Event              string        `json:"event"`
+	FirstTime          string        `json:"firstTime"`
+	BestTime           string        `json:"bestTime"`
+	ImprovementSeconds float64       `json:"improvementSeconds"`
+	ImprovementPercent float64       `json:"improvementPercent"`
+	Letter             bool          `json:"letter"`
+	Awards             []awardEarned `json:"awards"`
+
+	eventRaces     map[int32]int
+	bestSeconds    float64
+	improvement    float64
+	mainEventRaces int
+}
+
+// buildAthleteSeasons summarizes each rostered athlete's season. Results must
+// be ordered by meet date; results from earlier seasons only decide whether
+// an athlete is a newcomer.
+func (srv *server) buildAthleteSeasons(athletes []db.Athlete, results []historyResult, season int) []*athleteSeason {
+	seasons := make([]*athleteSeason, 0, len(athletes))
+	byID := make(map[int32]*athleteSeason, len(athletes))
+	for _, a := range athletes {
+		s := &athleteSeason{
+			AthleteID:  a.ID,
+			Name:       a.Name,
+			Grade:      a.Grade,
+			Division:   athleteDivision(a.Division.String),
+			Newcomer:   true,
+			Awards:     make([]awardEarned, 0),
+			eventRaces: make(map[int32]int),
+		}
+		seasons = append(seasons, s)
+		byID[a.ID] = s
+	}
+
+	// Group each race's finishers by team so varsity and scoring positions
+	// can be worked out.
+	type raceTeam struct {
+		MeetID      int32
+		EventTypeID int32
+		Division    string
+	}
+	var order []raceTeam
+	teams := make(map[raceTeam][]historyResult)
+	first := make(map[athleteEventKey]historyResult)
+	best := make(map[athleteEventKey]historyResult)
+
+	for _, r := range results {
+		s, ok := byID[r.AthleteID]
+		if !ok {
+			continue
+		}
+		if r.season() < season {
+			s.Newcomer = false
+			continue
+		}
+		if r.season() != season {
+			continue
+		}
+
+		s.Races++
+		s.eventRaces[r.EventTypeID]++
+		ak := athleteEventKey{r.AthleteID, r.EventTypeID}
+		if _, ok := first[ak]; !ok {
+			first[ak] = r
+		}
+		if b, ok := best[ak]; !ok || r.Seconds < b.Seconds {
+			best[ak] = r
+		}
+
+		rt := raceTeam{r.MeetID, r.EventTypeID, r.Division}
+		if _, ok := teams[rt]; !ok {
+			order = append(order, rt)
+		}
+		teams[rt] = append(teams[rt], r)
+	}
+
+	for _, rt := range order {
+		group := teams[rt]
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Seconds < group[j].Seconds })
+		for pos, r := range group {
+			s := byID[r.AthleteID]
+			if pos < varsityRunners {
+				s.VarsityRaces++
+			}
+			if pos < teamScorers && r.Championship {
+				s.ChampionshipScores++
+			}
+		}
+	}
+
+	// Improvement is measured in the event an athlete raced most, from their
+	// first time of the season to their best.
+	for _, s := range seasons {
+		var event int32
+		for id, n := range s.eventRaces {
+			if n > s.eventRaces[event] || (n == s.eventRaces[event] && id < event) {
+				event = id
+			}
+		}
+		ak := athleteEventKey{s.AthleteID, event}
+		f, ok := first[ak]
+		if !ok {
+			continue
+		}
+		b := best[ak]
+		s.Event = f.Event
+		s.FirstTime = f.Time
+		s.BestTime = b.Time
+		s.bestSeconds = b.Seconds
+		s.improvement = percentFaster(f.Seconds, b.Seconds)
+		s.mainEventRaces = s.eventRaces[event]
+		s.ImprovementSeconds = round1(f.Seconds - b.Seconds)
+		s.ImprovementPercent = round2(s.improvement)
+	}
+
+	return seasons
+}
+
+// applyAwardRules evaluates each rule against the season summaries. Letter
+// rules are pass/fail per athlete. Ranking awards go to the top athletes in
+// each division, or only the rule's division when it has one.
+func applyAwardRules(rules []db.AwardRule, seasons []*athleteSeason) {
+	for _, rule := range rules {
+		switch rule.AwardType {
+		case awardLetter:
+			for _, s := range seasons {
+				if rule.Division.Valid && rule.Division.String != s.Division {
+					continue
+				}
+				count, reason := s.VarsityRaces, "Ran %d varsity races"
+				if rule.Criterion.String == criterionChampions {
+					count, reason = s.ChampionshipScores, "Scored in %d championship races"
+				}
+				if count < int(rule.Threshold) {
+					continue
+				}
+				s.Letter = true
+				s.Awards = append(s.Awards, awardEarned{
+					RuleID:    rule.ID,
+					Name:      rule.Name,
+					AwardType: rule.AwardType,
+					Reason:    fmt.Sprintf(reason, count),
+				})
+			}
+
+		case awardMostImproved, awardTopNewcomer:
+			divisions := []string{divisionBoys, divisionGirls}
+			if rule.Division.Valid {
+				divisions = []string{rule.Division.String}
+			}
+			for _, division := range divisions {
+				for _, s := range rankAthletes(rule, division, seasons) {
+					reason := fmt.Sprintf("Improved %.2f%% from %s to %s", s.ImprovementPercent, s.FirstTime, s.BestTime)
+					if rule.AwardType == awardTopNewcomer {
+						reason = "Fastest first-year runner at " + s.BestTime
+					}
+					s.Awards = append(s.Awards, awardEarned{
+						RuleID:    rule.ID,
+						Name:      rule.Name,
+						AwardType: rule.AwardType,
+						Reason:    reason,
+					})
+				}
+			}
+		}
+	}
+}
+
+// rankAthletes returns the winners of a ranking award within a division.
+// Most improved needs at least threshold races in the athlete's main event.
+func rankAthletes(rule db.AwardRule, division string, seasons []*athleteSeason) []*athleteSeason {
+	var eligible []*athleteSeason
+	for _, s := range seasons {
+		if s.Division != division || s.BestTime == "" {
+			continue
+		}
+		switch rule.AwardType {
+		case awardMostImproved:
+			if s.mainEventRaces >= int(rule.Threshold) && s.improvement > 0 {
+				eligible = append(eligible, s)
+			}
+		case awardTopNewcomer:
+			if s.Newcomer && s.Races >= int(rule.Threshold) {
+				eligible = append(eligible, s)
+			}
+		}
+	}
+
+	sort.SliceStable(eligible, func(i, j int) bool {
+		if rule.AwardType == awardMostImproved {
+			return eligible[i].improvement > eligible[j].improvement
+		}
+		return eligible[i].bestSeconds < eligible[j].bestSeconds
+	})
+	return eligible[:min(int(rule.Winners), len(eligible))]
+}
+
+func (srv *server) getAwardsReportHandler(c *gin.Context) {
+	season := srv.now().Year()
+	if s := c.Query("season"); s != "" {
+		var err error
+		season, err = strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season"})
+			return
+		}
+	}
+
+	division := c.Query("division")
+	if division != "" && !validDivision(division) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid division"})
+		return
+	}
+
+	rules, err := srv.repo.GetAllAwardRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	athletes, err := srv.repo.GetAllAthletes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	results, err := srv.loadResultHistory(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	names, err := srv.loadAthleteNames(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	seasons := srv.buildAthleteSeasons(athletes, results, season)
+	applyAwardRules(rules, seasons)
+
+	report := make([]*athleteSeason, 0, len(seasons))
+	for _, s := range seasons {
+		if (division != "" && s.Division != division) || names.hidden(s.AthleteID) {
+			continue
+		}
+		s.Name = names.name(s.AthleteID, s.Name)
+		report = append(report, s)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":   season,
+		"athletes": report,
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// =====================
+// COMMANDS
+// =====================
+
+// runCommand runs a maintenance subcommand, e.g. `go run . create-admin`.
+func runCommand(cfg config, conn *sql.DB, name string, args []string) error {
+	switch name {
+	case "create-admin":
+		return createAdminCommand(newSQLRepository(conn, nil), args)
+	case "migrate":
+		return migrateCommand(cfg, conn, args)
+	default:
+		return fmt.Errorf("unknown command %q (available: config, create-admin, migrate)", name)
+	}
+}
+
+// createAdminCommand bootstraps the first head coach account. It refuses to
+// run once an active head coach exists; further accounts are managed through
+// the API. The password is read from XC_ADMIN_PASSWORD or prompted for on
+// stdin so it doesn't end up in shell history.
+func createAdminCommand(repo repository, args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	username := fs.String("username", "admin", "username for the head coach account")
+	displayName := fs.String("name", "", "display name")
+	email := fs.String("email", "", "email address")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	coaches, err := repo.CountHeadCoaches(ctx)
+	if err != nil {
+		return err
+	}
+	if coaches > 0 {
+		return fmt.Errorf("a head coach account already exists; manage users through /api/users")
+	}
+
+	password := os.Getenv("XC_ADMIN_PASSWORD")
+	if password == "" {
+		fmt.Fprint(os.Stderr, "Password for "+*username+": ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("reading password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	user, err := createUser(ctx, repo, CreateUserRequest{
+		Username:    *username,
+		Password:    password,
+		DisplayName: *displayName,
+		Email:       *email,
+		Role:        roleHeadCoach,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created head coach account %q (id %d)\n", user.Username, user.ID)
+	return nil
+}
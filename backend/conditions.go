@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// RACE CONDITIONS
+// =====================
+
+type ConditionsRequest struct {
+	TemperatureF  *int32 `json:"temperatureF"`
+	HumidityPct   *int32 `json:"humidityPct" binding:"omitempty,min=0,max=100"`
+	WindMph       *int32 `json:"windMph" binding:"omitempty,min=0"`
+	Precipitation string `json:"precipitation" binding:"omitempty,oneof=none drizzle rain heavy_rain snow"`
+	Footing       string `json:"footing" binding:"omitempty,oneof=firm good soft muddy flooded"`
+}
+
+type RaceConditionsRequest struct {
+	ConditionsRequest
+	Notes string `json:"notes"`
+}
+
+func nullInt32(v *int32) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *v, Valid: true}
+}
+
+func nullableInt32(v sql.NullInt32) any {
+	if !v.Valid {
+		return nil
+	}
+	return v.Int32
+}
+
+func nullableString(v sql.NullString) any {
+	if !v.Valid {
+		return nil
+	}
+	return v.String
+}
+
+func meetConditions(m db.Meet) gin.H {
+	return gin.H{
+		"temperatureF":  nullableInt32(m.TemperatureF),
+		"humidityPct":   nullableInt32(m.HumidityPct),
+		"windMph":       nullableInt32(m.WindMph),
+		"precipitation": nullableString(m.Precipitation),
+		"footing":       nullableString(m.Footing),
+	}
+}
+
+func raceConditions(rc db.RaceCondition) gin.H {
+	return gin.H{
+		"eventTypeId":   rc.EventTypeID,
+		"temperatureF":  nullableInt32(rc.TemperatureF),
+		"humidityPct":   nullableInt32(rc.HumidityPct),
+		"windMph":       nullableInt32(rc.WindMph),
+		"precipitation": nullableString(rc.Precipitation),
+		"footing":       nullableString(rc.Footing),
+		"notes":         nullableString(rc.Notes),
+	}
+}
+
+type raceKey struct {
+	MeetID      int32
+	EventTypeID int32
+}
+
+// conditionsIndex resolves the conditions a result was run in: a race-level
+// record wins field by field over the conditions recorded for the meet.
+type conditionsIndex struct {
+	meets map[int32]db.Meet
+	races map[raceKey]db.RaceCondition
+}
+
+func newConditionsIndex(meets []db.Meet, races []db.RaceCondition) *conditionsIndex {
+	idx := &conditionsIndex{
+		meets: make(map[int32]db.Meet, len(meets)),
+		races: make(map[raceKey]db.RaceCondition, len(races)),
+	}
+	for _, m := range meets {
+		idx.meets[m.ID] = m
+	}
+	for _, rc := range races {
+		idx.races[raceKey{rc.MeetID, rc.EventTypeID}] = rc
+	}
+	return idx
+}
+
+func (srv *server) loadConditions(ctx context.Context) (*conditionsIndex, error) {
+	meets, err := srv.repo.GetAllMeets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	races, err := srv.repo.GetAllRaceConditions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newConditionsIndex(meets, races), nil
+}
+
+func (srv *server) loadMeetConditions(ctx context.Context, meetID int32) (*conditionsIndex, error) {
+	meet, err := srv.repo.GetMeetByID(ctx, meetID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	races, err := srv.repo.GetRaceConditionsByMeet(ctx, meetID)
+	if err != nil {
+		return nil, err
+	}
+	return newConditionsIndex([]db.Meet{meet}, races), nil
+}
+
+func (idx *conditionsIndex) forResult(meetID int32, eventTypeID sql.NullInt32) gin.H {
+	m := idx.meets[meetID]
+	rc, ok := idx.races[raceKey{meetID, eventTypeID.Int32}]
+	if !ok || !eventTypeID.Valid {
+		return meetConditions(m)
+	}
+
+	pickInt := func(race, meet sql.NullInt32) any {
+		if race.Valid {
+			return race.Int32
+		}
+		return nullableInt32(meet)
+	}
+	pickString := func(race, meet sql.NullString) any {
+		if race.Valid {
+			return race.String
+		}
+		return nullableString(meet)
+	}
+	return gin.H{
+		"temperatureF":  pickInt(rc.TemperatureF, m.TemperatureF),
+		"humidityPct":   pickInt(rc.HumidityPct, m.HumidityPct),
+		"windMph":       pickInt(rc.WindMph, m.WindMph),
+		"precipitation": pickString(rc.Precipitation, m.Precipitation),
+		"footing":       pickString(rc.Footing, m.Footing),
+		"notes":         nullableString(rc.Notes),
+	}
+}
+
+func (srv *server) getMeetConditionsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+
+	meet, err := srv.repo.GetMeetByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Meet not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	races, err := srv.repo.GetRaceConditionsByMeet(c.Request.Context(), meet.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]gin.H, len(races))
+	for i, rc := range races {
+		response[i] = raceConditions(rc)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"meetId":     meet.ID,
+		"conditions": meetConditions(meet),
+		"races":      response,
+	})
+}
+
+func (srv *server) putRaceConditionsHandler(c *gin.Context) {
+	meetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+	eventTypeID, err := strconv.Atoi(c.Param("eventTypeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event type ID"})
+		return
+	}
+
+	var req RaceConditionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	before, found, ok := raceConditionsForUpdate(c, tx, int32(meetID), int32(eventTypeID))
+	if !ok {
+		return
+	}
+
+	err = tx.DeleteRaceConditions(c.Request.Context(), db.DeleteRaceConditionsParams{
+		MeetID:      int32(meetID),
+		EventTypeID: int32(eventTypeID),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	after := db.RaceCondition{
+		MeetID:        int32(meetID),
+		EventTypeID:   int32(eventTypeID),
+		TemperatureF:  nullInt32(req.TemperatureF),
+		HumidityPct:   nullInt32(req.HumidityPct),
+		WindMph:       nullInt32(req.WindMph),
+		Precipitation: sql.NullString{String: req.Precipitation, Valid: req.Precipitation != ""},
+		Footing:       sql.NullString{String: req.Footing, Valid: req.Footing != ""},
+		Notes:         sql.NullString{String: req.Notes, Valid: req.Notes != ""},
+	}
+	err = tx.CreateRaceConditions(c.Request.Context(), db.CreateRaceConditionsParams{
+		MeetID:        after.MeetID,
+		EventTypeID:   after.EventTypeID,
+		TemperatureF:  after.TemperatureF,
+		HumidityPct:   after.HumidityPct,
+		WindMph:       after.WindMph,
+		Precipitation: after.Precipitation,
+		Footing:       after.Footing,
+		Notes:         after.Notes,
+	})
+	if isForeignKeyViolation(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event type not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var beforeData any
+	if found {
+		beforeData = raceConditions(before)
+	}
+	if err := recordAudit(c, tx, auditRaceConditions, after.MeetID, beforeData, raceConditions(after)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meetId":        meetID,
+		"eventTypeId":   eventTypeID,
+		"temperatureF":  req.TemperatureF,
+		"humidityPct":   req.HumidityPct,
+		"windMph":       req.WindMph,
+		"precipitation": req.Precipitation,
+		"footing":       req.Footing,
+		"notes":         req.Notes,
+	})
+}
+
+func (srv *server) deleteRaceConditionsHandler(c *gin.Context) {
+	meetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+	eventTypeID, err := strconv.Atoi(c.Param("eventTypeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event type ID"})
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	before, found, ok := raceConditionsForUpdate(c, tx, int32(meetID), int32(eventTypeID))
+	if !ok {
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Race conditions not found"})
+		return
+	}
+
+	err = tx.DeleteRaceConditions(c.Request.Context(), db.DeleteRaceConditionsParams{
+		MeetID:      int32(meetID),
+		EventTypeID: int32(eventTypeID),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditRaceConditions, before.MeetID, raceConditions(before), nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Race conditions deleted"})
+}
+
+// raceConditionsForUpdate loads the conditions recorded for one race of a
+// meet that isn't official yet, reporting whether there were any. It
+// writes the error response and returns ok false if the meet is missing or
+// official.
+func raceConditionsForUpdate(c *gin.Context, q db.Querier, meetID, eventTypeID int32) (rc db.RaceCondition, found, ok bool) {
+	meet, err := q.GetMeetByID(c.Request.Context(), meetID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Meet not found"})
+			return rc, false, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return rc, false, false
+	}
+	if meet.Official {
+		c.JSON(http.StatusConflict, gin.H{"error": "Meet results are official and can no longer be changed"})
+		return rc, false, false
+	}
+
+	races, err := q.GetRaceConditionsByMeet(c.Request.Context(), meetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return rc, false, false
+	}
+	for _, r := range races {
+		if r.EventTypeID == eventTypeID {
+			return r, true, true
+		}
+	}
+	return rc, false, true
+}
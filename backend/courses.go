@@ -0,0 +1,347 @@
+package main
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// COURSES HANDLERS
+// =====================
+
+func courseResponse(c db.Course) gin.H {
+	return gin.H{
+		"id":               c.ID,
+		"name":             c.Name,
+		"location":         c.Location.String,
+		"description":      c.Description.String,
+		"correctionFactor": c.CorrectionFactor,
+		"sampleSize":       c.SampleSize,
+	}
+}
+
+func (srv *server) getCoursesHandler(c *gin.Context) {
+	courses, err := srv.repo.GetAllCourses(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]gin.H, len(courses))
+	for i, course := range courses {
+		result[i] = courseResponse(course)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (srv *server) getCourseByIDHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	course, err := srv.repo.GetCourseByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, courseResponse(course))
+}
+
+type CourseRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Location    string `json:"location"`
+	Description string `json:"description"`
+}
+
+func (srv *server) createCourseHandler(c *gin.Context) {
+	var req CourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := srv.repo.CreateCourse(c.Request.Context(), db.CreateCourseParams{
+		Name:        req.Name,
+		Location:    sql.NullString{String: req.Location, Valid: req.Location != ""},
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+	})
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A course with this name already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	c.JSON(http.StatusCreated, gin.H{
+		"id":               id,
+		"name":             req.Name,
+		"location":         req.Location,
+		"description":      req.Description,
+		"correctionFactor": 1.0,
+		"sampleSize":       0,
+	})
+}
+
+func (srv *server) updateCourseHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	var req CourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := srv.repo.GetCourseByID(c.Request.Context(), int32(id)); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = srv.repo.UpdateCourse(c.Request.Context(), db.UpdateCourseParams{
+		ID:          int32(id),
+		Name:        req.Name,
+		Location:    sql.NullString{String: req.Location, Valid: req.Location != ""},
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+	})
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A course with this name already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          id,
+		"name":        req.Name,
+		"location":    req.Location,
+		"description": req.Description,
+	})
+}
+
+func (srv *server) deleteCourseHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if _, err := srv.repo.GetCourseByID(c.Request.Context(), int32(id)); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = srv.repo.DeleteCourse(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Course deleted"})
+}
+
+// recomputeCourseFactorsHandler derives correction factors from every result
+// run on a known course and stores them on the courses table.
+func (srv *server) recomputeCourseFactorsHandler(c *gin.Context) {
+	rows, err := srv.repo.GetCourseRaces(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	races := make([]courseRace, 0, len(rows))
+	for _, r := range rows {
+		seconds, err := parseRaceTime(r.Time)
+		if err != nil {
+			continue
+		}
+		races = append(races, courseRace{
+			AthleteID:   r.AthleteID,
+			EventTypeID: r.EventTypeID.Int32,
+			CourseID:    r.CourseID.Int32,
+			Seconds:     seconds,
+		})
+	}
+
+	courses, err := srv.repo.GetAllCourses(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	factors := computeCourseFactors(races)
+	response := make([]gin.H, len(courses))
+	for i, course := range courses {
+		f, ok := factors[course.ID]
+		if !ok {
+			f = courseFactor{Factor: 1}
+		}
+		err := srv.repo.UpdateCourseFactor(c.Request.Context(), db.UpdateCourseFactorParams{
+			ID:               course.ID,
+			CorrectionFactor: f.Factor,
+			SampleSize:       int32(f.SampleSize),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		course.CorrectionFactor = f.Factor
+		course.SampleSize = int32(f.SampleSize)
+		response[i] = courseResponse(course)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// =====================
+// COURSE CORRECTIONS
+// =====================
+
+const courseFactorIterations = 50
+
+type courseRace struct {
+	AthleteID   int32
+	EventTypeID int32
+	CourseID    int32
+	Seconds     float64
+}
+
+type courseFactor struct {
+	Factor     float64
+	SampleSize int
+}
+
+// computeCourseFactors estimates how fast or slow each course runs using a
+// speed-rating style fit. Only athletes who raced the same event on two or
+// more courses link courses together. Each pass estimates an athlete's
+// course-neutral ability from their adjusted times, then sets each course
+// factor to the median ratio of raw time to ability on that course. Factors
+// are normalized so their geometric mean is 1, making an "average" course
+// neutral. Courses without linking runners are left out of the result.
+func computeCourseFactors(races []courseRace) map[int32]courseFactor {
+	type runnerKey struct {
+		AthleteID   int32
+		EventTypeID int32
+	}
+
+	courses := make(map[runnerKey]map[int32]bool)
+	for _, r := range races {
+		k := runnerKey{r.AthleteID, r.EventTypeID}
+		if courses[k] == nil {
+			courses[k] = make(map[int32]bool)
+		}
+		courses[k][r.CourseID] = true
+	}
+
+	linked := make([]courseRace, 0, len(races))
+	for _, r := range races {
+		if len(courses[runnerKey{r.AthleteID, r.EventTypeID}]) > 1 {
+			linked = append(linked, r)
+		}
+	}
+
+	factors := make(map[int32]float64)
+	samples := make(map[int32]int)
+	for _, r := range linked {
+		factors[r.CourseID] = 1
+		samples[r.CourseID]++
+	}
+	if len(factors) == 0 {
+		return map[int32]courseFactor{}
+	}
+
+	for iter := 0; iter < courseFactorIterations; iter++ {
+		sums := make(map[runnerKey]float64)
+		counts := make(map[runnerKey]int)
+		for _, r := range linked {
+			k := runnerKey{r.AthleteID, r.EventTypeID}
+			sums[k] += r.Seconds / factors[r.CourseID]
+			counts[k]++
+		}
+
+		ratios := make(map[int32][]float64)
+		for _, r := range linked {
+			k := runnerKey{r.AthleteID, r.EventTypeID}
+			ability := sums[k] / float64(counts[k])
+			ratios[r.CourseID] = append(ratios[r.CourseID], r.Seconds/ability)
+		}
+
+		logSum := 0.0
+		for id, rs := range ratios {
+			factors[id] = median(rs)
+			logSum += math.Log(factors[id])
+		}
+		scale := math.Exp(logSum / float64(len(factors)))
+		for id := range factors {
+			factors[id] /= scale
+		}
+	}
+
+	result := make(map[int32]courseFactor, len(factors))
+	for id, f := range factors {
+		result[id] = courseFactor{
+			Factor:     math.Round(f*10000) / 10000,
+			SampleSize: samples[id],
+		}
+	}
+	return result
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// adjustedRaceTime converts a raw time into a course-neutral time. Results on
+// meets without a course are returned unchanged.
+func adjustedRaceTime(raw string, factor sql.NullFloat64) string {
+	seconds, err := parseRaceTime(raw)
+	if err != nil || !factor.Valid || factor.Float64 <= 0 {
+		return raw
+	}
+	return formatRaceTime(seconds / factor.Float64)
+}
+
+func courseFactorValue(factor sql.NullFloat64) float64 {
+	if !factor.Valid || factor.Float64 <= 0 {
+		return 1
+	}
+	return factor.Float64
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+)
+
+// =====================
+// DATABASE
+// =====================
+
+// Supported database engines. The queries in db/queries.sql are written to
+// run on each, with placeholders rewritten for PostgreSQL; only the schema in
+// migrations/<driver> differs.
+const (
+	driverMySQL    = "mysql"
+	driverPostgres = "postgres"
+	driverSQLite   = "sqlite"
+)
+
+var drivers = []string{driverMySQL, driverPostgres, driverSQLite}
+
+func openDatabase(driver, dsn string, logger *slog.Logger) (*sql.DB, error) {
+	var conn *sql.DB
+	var err error
+	switch driver {
+	case driverPostgres:
+		conn, err = openPostgres(dsn)
+	case driverSQLite:
+		conn, err = sql.Open("sqlite3", sqliteDSN(dsn))
+	default:
+		conn, err = sql.Open("mysql", dsn)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if driver == driverSQLite {
+		// SQLite allows one writer at a time, and every connection to
+		// :memory: would otherwise get its own empty database.
+		conn.SetMaxOpenConns(1)
+	}
+
+	// Verify connection
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	logger.Info("connected to database", "driver", driver)
+	return conn, nil
+}
+
+// sqliteDSN turns a file path or ":memory:" into a DSN with foreign keys on,
+// which SQLite leaves off by default, and a busy timeout for file databases.
+func sqliteDSN(dsn string) string {
+	path, query, _ := strings.Cut(dsn, "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		params = url.Values{}
+	}
+	if !params.Has("_foreign_keys") && !params.Has("_fk") {
+		params.Set("_foreign_keys", "1")
+	}
+	if path != ":memory:" && !params.Has("_busy_timeout") {
+		params.Set("_busy_timeout", "5000")
+	}
+	return path + "?" + params.Encode()
+}
+
+// isUniqueViolation reports whether err is a unique constraint failure, so
+// handlers can answer 409 instead of passing the engine's message through.
+func isUniqueViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1062 // ER_DUP_ENTRY
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505" // unique_violation
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+	return false
+}
+
+// isForeignKeyViolation reports whether err is a foreign key failure, which
+// means a row refers to a parent that doesn't exist.
+func isForeignKeyViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1452 // ER_NO_REFERENCED_ROW_2
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23503" // foreign_key_violation
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+	}
+	return false
+}
@@ -9,36 +9,156 @@ import (
 	"time"
 )
 
+type AccountToken struct {
+	ID        int32
+	UserID    int32
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	CreatedBy sql.NullInt32
+	CreatedAt sql.NullTime
+}
+
+type ApiToken struct {
+	ID          int32
+	UserID      int32
+	Name        string
+	TokenHash   string
+	TokenPrefix string
+	Scopes      string
+	MeetID      sql.NullInt32
+	ExpiresAt   sql.NullTime
+	LastUsedAt  sql.NullTime
+	LastUsedIp  sql.NullString
+	RevokedAt   sql.NullTime
+	CreatedBy   sql.NullInt32
+	CreatedAt   sql.NullTime
+}
+
 type Athlete struct {
-	ID             int32
-	Name           string
-	Grade          int32
-	PersonalRecord sql.NullString
-	Events         sql.NullString
-	CreatedAt      sql.NullTime
-	UpdatedAt      sql.NullTime
+	ID              int32
+	Name            string
+	Grade           int32
+	Division        sql.NullString
+	PersonalRecord  sql.NullString
+	Events          sql.NullString
+	NameVisibility  string
+	ParentalConsent bool
+	CreatedAt       sql.NullTime
+	UpdatedAt       sql.NullTime
 }
 
-type EventType struct {
+type AuditLog struct {
+	ID         int32
+	UserID     sql.NullInt32
+	Username   sql.NullString
+	Action     string
+	EntityType string
+	EntityID   int32
+	BeforeData sql.NullString
+	AfterData  sql.NullString
+	CreatedAt  sql.NullTime
+}
+
+type AwardRule struct {
 	ID          int32
 	Name        string
-	Distance    sql.NullString
+	AwardType   string
+	Criterion   sql.NullString
+	Threshold   int32
+	Winners     int32
+	Division    sql.NullString
 	Description sql.NullString
 	CreatedAt   sql.NullTime
 	UpdatedAt   sql.NullTime
 }
 
-type Meet struct {
+type Course struct {
+	ID               int32
+	Name             string
+	Location         sql.NullString
+	Description      sql.NullString
+	CorrectionFactor float64
+	SampleSize       int32
+	FactorUpdatedAt  sql.NullTime
+	CreatedAt        sql.NullTime
+	UpdatedAt        sql.NullTime
+}
+
+type EventType struct {
 	ID          int32
 	Name        string
-	Date        time.Time
-	Time        sql.NullString
-	Location    sql.NullString
+	Distance    sql.NullString
 	Description sql.NullString
 	CreatedAt   sql.NullTime
 	UpdatedAt   sql.NullTime
 }
 
+type Meet struct {
+	ID            int32
+	Name          string
+	Date          time.Time
+	Time          sql.NullString
+	Location      sql.NullString
+	Description   sql.NullString
+	CourseID      sql.NullInt32
+	TemperatureF  sql.NullInt32
+	HumidityPct   sql.NullInt32
+	WindMph       sql.NullInt32
+	Precipitation sql.NullString
+	Footing       sql.NullString
+	Status        string
+	OriginalDate  sql.NullTime
+	Official      bool
+	FinalizedAt   sql.NullTime
+	Championship  bool
+	CreatedAt     sql.NullTime
+	UpdatedAt     sql.NullTime
+}
+
+type MeetDateChange struct {
+	ID           int32
+	MeetID       int32
+	PreviousDate time.Time
+	NewDate      time.Time
+	Reason       sql.NullString
+	ChangedAt    sql.NullTime
+}
+
+type QualificationRule struct {
+	ID                       int32
+	MeetID                   int32
+	TargetMeetID             int32
+	TopTeams                 int32
+	TopIndividuals           int32
+	TopIndividualsNotOnTeams int32
+	CreatedAt                sql.NullTime
+	UpdatedAt                sql.NullTime
+}
+
+type RaceCondition struct {
+	ID            int32
+	MeetID        int32
+	EventTypeID   int32
+	TemperatureF  sql.NullInt32
+	HumidityPct   sql.NullInt32
+	WindMph       sql.NullInt32
+	Precipitation sql.NullString
+	Footing       sql.NullString
+	Notes         sql.NullString
+	CreatedAt     sql.NullTime
+	UpdatedAt     sql.NullTime
+}
+
+type RecoveryCode struct {
+	ID        int32
+	UserID    int32
+	CodeHash  string
+	UsedAt    sql.NullTime
+	CreatedAt sql.NullTime
+}
+
 type Result struct {
 	ID          int32
 	AthleteID   int32
@@ -48,3 +168,61 @@ type Result struct {
 	Place       sql.NullInt32
 	CreatedAt   sql.NullTime
 }
+
+type Session struct {
+	ID         int32
+	TokenHash  string
+	UserID     int32
+	ExpiresAt  time.Time
+	LastSeenAt sql.NullTime
+	UserAgent  sql.NullString
+	IpAddress  sql.NullString
+	CreatedAt  sql.NullTime
+}
+
+type TeamFinish struct {
+	ID        int32
+	MeetID    int32
+	Division  string
+	Place     int32
+	Score     sql.NullInt32
+	CreatedAt sql.NullTime
+}
+
+type TimeStandard struct {
+	ID          int32
+	Name        string
+	EventTypeID int32
+	Division    sql.NullString
+	Time        string
+	Category    string
+	Description sql.NullString
+	CreatedAt   sql.NullTime
+	UpdatedAt   sql.NullTime
+}
+
+type User struct {
+	ID                int32
+	Username          string
+	PasswordHash      string
+	DisplayName       sql.NullString
+	Email             sql.NullString
+	Role              string
+	Active            bool
+	PasswordChangedAt sql.NullTime
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+}
+
+type UserAthlete struct {
+	UserID    int32
+	AthleteID int32
+}
+
+type UserTotp struct {
+	UserID       int32
+	Secret       string
+	ConfirmedAt  sql.NullTime
+	LastUsedStep int64
+	CreatedAt    sql.NullTime
+}
@@ -29,53 +29,239 @@ DELETE FROM event_types WHERE id = ?;
 -- =====================
 
 -- name: GetAllAthletes :many
-SELECT id, name, grade, personal_record, events, created_at, updated_at
+SELECT id, name, grade, division, personal_record, events, name_visibility, parental_consent, created_at, updated_at
 FROM athletes
 ORDER BY name;
 
 -- name: GetAthleteByID :one
-SELECT id, name, grade, personal_record, events, created_at, updated_at
+SELECT id, name, grade, division, personal_record, events, name_visibility, parental_consent, created_at, updated_at
 FROM athletes
 WHERE id = ?;
 
 -- name: CreateAthlete :execresult
-INSERT INTO athletes (name, grade, personal_record, events)
-VALUES (?, ?, ?, ?);
+INSERT INTO athletes (name, grade, division, personal_record, events)
+VALUES (?, ?, ?, ?, ?);
 
 -- name: UpdateAthlete :exec
 UPDATE athletes
-SET name = ?, grade = ?, personal_record = ?, events = ?
+SET name = ?, grade = ?, division = ?, personal_record = ?, events = ?
+WHERE id = ?;
+
+-- name: UpdateAthletePrivacy :exec
+UPDATE athletes
+SET name_visibility = ?, parental_consent = ?
 WHERE id = ?;
 
+-- name: GetAthletePrivacy :many
+SELECT id, name_visibility, parental_consent FROM athletes;
+
 -- name: DeleteAthlete :exec
 DELETE FROM athletes WHERE id = ?;
 
+-- =====================
+-- COURSES
+-- =====================
+
+-- name: GetAllCourses :many
+SELECT id, name, location, description, correction_factor, sample_size, factor_updated_at, created_at, updated_at
+FROM courses
+ORDER BY name;
+
+-- name: GetCourseByID :one
+SELECT id, name, location, description, correction_factor, sample_size, factor_updated_at, created_at, updated_at
+FROM courses
+WHERE id = ?;
+
+-- name: CreateCourse :execresult
+INSERT INTO courses (name, location, description)
+VALUES (?, ?, ?);
+
+-- name: UpdateCourse :exec
+UPDATE courses
+SET name = ?, location = ?, description = ?
+WHERE id = ?;
+
+-- name: UpdateCourseFactor :exec
+UPDATE courses
+SET correction_factor = ?, sample_size = ?, factor_updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteCourse :exec
+DELETE FROM courses WHERE id = ?;
+
+-- name: GetCourseRaces :many
+SELECT r.athlete_id, r.event_type_id, r.time, m.course_id
+FROM results r
+JOIN meets m ON r.meet_id = m.id
+WHERE m.course_id IS NOT NULL;
+
 -- =====================
 -- MEETS
 -- =====================
 
 -- name: GetAllMeets :many
-SELECT id, name, date, time, location, description, created_at, updated_at
+SELECT id, name, date, time, location, description, course_id, temperature_f, humidity_pct, wind_mph, precipitation, footing,
+    status, original_date, official, finalized_at, championship, created_at, updated_at
 FROM meets
 ORDER BY date;
 
 -- name: GetMeetByID :one
-SELECT id, name, date, time, location, description, created_at, updated_at
+SELECT id, name, date, time, location, description, course_id, temperature_f, humidity_pct, wind_mph, precipitation, footing,
+    status, original_date, official, finalized_at, championship, created_at, updated_at
 FROM meets
 WHERE id = ?;
 
 -- name: CreateMeet :execresult
-INSERT INTO meets (name, date, time, location, description)
-VALUES (?, ?, ?, ?, ?);
+INSERT INTO meets (name, date, time, location, description, course_id, temperature_f, humidity_pct, wind_mph, precipitation, footing, championship)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: UpdateMeet :exec
 UPDATE meets
-SET name = ?, date = ?, time = ?, location = ?, description = ?
+SET name = ?, date = ?, time = ?, location = ?, description = ?, course_id = ?
+WHERE id = ?;
+
+-- name: UpdateMeetConditions :exec
+UPDATE meets
+SET temperature_f = ?, humidity_pct = ?, wind_mph = ?, precipitation = ?, footing = ?
+WHERE id = ?;
+
+-- name: SetMeetChampionship :exec
+UPDATE meets
+SET championship = ?
 WHERE id = ?;
 
 -- name: DeleteMeet :exec
 DELETE FROM meets WHERE id = ?;
 
+-- name: UpdateMeetStatus :exec
+UPDATE meets
+SET status = ?
+WHERE id = ?;
+
+-- name: RescheduleMeet :exec
+UPDATE meets
+SET date = sqlc.arg(new_date), original_date = COALESCE(original_date, sqlc.arg(previous_date)), status = sqlc.arg(status)
+WHERE id = sqlc.arg(id);
+
+-- name: MarkMeetOfficial :exec
+UPDATE meets
+SET official = TRUE, finalized_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: CreateMeetDateChange :exec
+INSERT INTO meet_date_changes (meet_id, previous_date, new_date, reason)
+VALUES (?, ?, ?, ?);
+
+-- name: GetMeetDateChanges :many
+SELECT id, meet_id, previous_date, new_date, reason, changed_at
+FROM meet_date_changes
+WHERE meet_id = ?
+ORDER BY changed_at, id;
+
+-- name: GetAllRaceConditions :many
+SELECT id, meet_id, event_type_id, temperature_f, humidity_pct, wind_mph, precipitation, footing, notes, created_at, updated_at
+FROM race_conditions;
+
+-- name: GetRaceConditionsByMeet :many
+SELECT id, meet_id, event_type_id, temperature_f, humidity_pct, wind_mph, precipitation, footing, notes, created_at, updated_at
+FROM race_conditions
+WHERE meet_id = ?
+ORDER BY event_type_id;
+
+-- name: CreateRaceConditions :exec
+INSERT INTO race_conditions (meet_id, event_type_id, temperature_f, humidity_pct, wind_mph, precipitation, footing, notes)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: DeleteRaceConditions :exec
+DELETE FROM race_conditions WHERE meet_id = ? AND event_type_id = ?;
+
+-- =====================
+-- QUALIFICATION
+-- =====================
+
+-- name: GetQualificationRule :one
+SELECT id, meet_id, target_meet_id, top_teams, top_individuals, top_individuals_not_on_teams, created_at, updated_at
+FROM qualification_rules
+WHERE meet_id = ?;
+
+-- name: GetQualificationRulesForTarget :many
+SELECT id, meet_id, target_meet_id, top_teams, top_individuals, top_individuals_not_on_teams, created_at, updated_at
+FROM qualification_rules
+WHERE target_meet_id = ?
+ORDER BY meet_id;
+
+-- name: CreateQualificationRule :exec
+INSERT INTO qualification_rules (meet_id, target_meet_id, top_teams, top_individuals, top_individuals_not_on_teams)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: DeleteQualificationRule :exec
+DELETE FROM qualification_rules WHERE meet_id = ?;
+
+-- name: GetTeamFinishesByMeet :many
+SELECT id, meet_id, division, place, score, created_at
+FROM team_finishes
+WHERE meet_id = ?
+ORDER BY division;
+
+-- name: CreateTeamFinish :exec
+INSERT INTO team_finishes (meet_id, division, place, score)
+VALUES (?, ?, ?, ?);
+
+-- name: DeleteTeamFinish :exec
+DELETE FROM team_finishes WHERE meet_id = ? AND division = ?;
+
+-- =====================
+-- TIME STANDARDS
+-- =====================
+
+-- name: GetAllTimeStandards :many
+SELECT id, name, event_type_id, division, time, category, description, created_at, updated_at
+FROM time_standards
+ORDER BY event_type_id, division, time;
+
+-- name: GetTimeStandardByID :one
+SELECT id, name, event_type_id, division, time, category, description, created_at, updated_at
+FROM time_standards
+WHERE id = ?;
+
+-- name: CreateTimeStandard :execresult
+INSERT INTO time_standards (name, event_type_id, division, time, category, description)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: UpdateTimeStandard :exec
+UPDATE time_standards
+SET name = ?, event_type_id = ?, division = ?, time = ?, category = ?, description = ?
+WHERE id = ?;
+
+-- name: DeleteTimeStandard :exec
+DELETE FROM time_standards WHERE id = ?;
+
+-- =====================
+-- AWARD RULES
+-- =====================
+
+-- name: GetAllAwardRules :many
+SELECT id, name, award_type, criterion, threshold, winners, division, description, created_at, updated_at
+FROM award_rules
+ORDER BY award_type, id;
+
+-- name: GetAwardRuleByID :one
+SELECT id, name, award_type, criterion, threshold, winners, division, description, created_at, updated_at
+FROM award_rules
+WHERE id = ?;
+
+-- name: CreateAwardRule :execresult
+INSERT INTO award_rules (name, award_type, criterion, threshold, winners, division, description)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: UpdateAwardRule :exec
+UPDATE award_rules
+SET name = ?, award_type = ?, criterion = ?, threshold = ?, winners = ?, division = ?, description = ?
+WHERE id = ?;
+
+-- name: DeleteAwardRule :exec
+DELETE FROM award_rules WHERE id = ?;
+
 -- =====================
 -- RESULTS
 -- =====================
@@ -90,10 +276,14 @@ SELECT
     r.place,
     r.created_at,
     a.name as athlete_name,
-    et.name as event_name
+    et.name as event_name,
+    c.correction_factor as course_factor,
+    a.division as athlete_division
 FROM results r
 JOIN athletes a ON r.athlete_id = a.id
+JOIN meets m ON r.meet_id = m.id
 LEFT JOIN event_types et ON r.event_type_id = et.id
+LEFT JOIN courses c ON m.course_id = c.id
 WHERE r.meet_id = ?
 ORDER BY r.place;
 
@@ -108,10 +298,12 @@ SELECT
     r.created_at,
     m.name as meet_name,
     m.date as meet_date,
-    et.name as event_name
+    et.name as event_name,
+    c.correction_factor as course_factor
 FROM results r
 JOIN meets m ON r.meet_id = m.id
 LEFT JOIN event_types et ON r.event_type_id = et.id
+LEFT JOIN courses c ON m.course_id = c.id
 WHERE r.athlete_id = ?
 ORDER BY m.date DESC;
 
@@ -145,6 +337,24 @@ WHERE id = ?;
 -- name: DeleteResult :exec
 DELETE FROM results WHERE id = ?;
 
+-- name: GetResultsForAthlete :many
+SELECT id, athlete_id, meet_id, event_type_id, time, place, created_at
+FROM results
+WHERE athlete_id = ?
+ORDER BY id;
+
+-- name: GetResultsForEventType :many
+SELECT id, athlete_id, meet_id, event_type_id, time, place, created_at
+FROM results
+WHERE event_type_id = ?
+ORDER BY id;
+
+-- name: GetResultsForMeet :many
+SELECT id, athlete_id, meet_id, event_type_id, time, place, created_at
+FROM results
+WHERE meet_id = ?
+ORDER BY id;
+
 -- name: GetAllResults :many
 SELECT
     r.id,
@@ -157,14 +367,56 @@ SELECT
     a.name as athlete_name,
     m.name as meet_name,
     m.date as meet_date,
-    et.name as event_name
+    et.name as event_name,
+    c.correction_factor as course_factor
 FROM results r
 JOIN athletes a ON r.athlete_id = a.id
 JOIN meets m ON r.meet_id = m.id
 LEFT JOIN event_types et ON r.event_type_id = et.id
+LEFT JOIN courses c ON m.course_id = c.id
 ORDER BY m.date DESC, r.place;
 
--- name: GetTopTenFastestTimes :many
+-- name: GetResultsThrough :many
+SELECT
+    r.id,
+    r.athlete_id,
+    r.meet_id,
+    r.event_type_id,
+    r.time,
+    r.place,
+    a.name as athlete_name,
+    a.division as athlete_division,
+    m.name as meet_name,
+    m.date as meet_date,
+    et.name as event_name
+FROM results r
+JOIN athletes a ON r.athlete_id = a.id
+JOIN meets m ON r.meet_id = m.id
+LEFT JOIN event_types et ON r.event_type_id = et.id
+WHERE m.date <= ?
+ORDER BY m.date, r.meet_id, r.place;
+
+-- name: GetResultHistory :many
+SELECT
+    r.id,
+    r.athlete_id,
+    r.meet_id,
+    r.event_type_id,
+    r.time,
+    r.place,
+    a.name as athlete_name,
+    a.division as athlete_division,
+    m.name as meet_name,
+    m.date as meet_date,
+    m.championship as meet_championship,
+    et.name as event_name
+FROM results r
+JOIN athletes a ON r.athlete_id = a.id
+JOIN meets m ON r.meet_id = m.id
+LEFT JOIN event_types et ON r.event_type_id = et.id
+ORDER BY m.date, r.meet_id, r.place;
+
+-- name: GetLeaderboardTimes :many
 SELECT
     r.id,
     r.time,
@@ -175,10 +427,217 @@ SELECT
     m.id as meet_id,
     m.name as meet_name,
     m.date as meet_date,
-    et.name as event_name
+    et.name as event_name,
+    c.correction_factor as course_factor
 FROM results r
 JOIN athletes a ON r.athlete_id = a.id
 JOIN meets m ON r.meet_id = m.id
 LEFT JOIN event_types et ON r.event_type_id = et.id
-ORDER BY r.time ASC
-LIMIT 10;
+LEFT JOIN courses c ON m.course_id = c.id;
+
+-- =====================
+-- USERS
+-- =====================
+
+-- name: GetAllUsers :many
+SELECT id, username, password_hash, display_name, email, role, active, password_changed_at, created_at, updated_at
+FROM users
+ORDER BY username;
+
+-- name: GetUserByID :one
+SELECT id, username, password_hash, display_name, email, role, active, password_changed_at, created_at, updated_at
+FROM users
+WHERE id = ?;
+
+-- name: GetUserByUsername :one
+SELECT id, username, password_hash, display_name, email, role, active, password_changed_at, created_at, updated_at
+FROM users
+WHERE username = ?;
+
+-- name: GetUsersByEmail :many
+SELECT id, username, password_hash, display_name, email, role, active, password_changed_at, created_at, updated_at
+FROM users
+WHERE email = ?
+ORDER BY username;
+
+-- name: CountHeadCoaches :one
+SELECT COUNT(*) FROM users WHERE role = 'head_coach' AND active = TRUE;
+
+-- name: CreateUser :execresult
+INSERT INTO users (username, password_hash, display_name, email, role, password_changed_at)
+VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP);
+
+-- name: UpdateUser :exec
+UPDATE users
+SET username = ?, display_name = ?, email = ?, role = ?, active = ?
+WHERE id = ?;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET password_hash = ?, password_changed_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteUser :exec
+DELETE FROM users WHERE id = ?;
+
+-- name: GetUserAthleteIDs :many
+SELECT athlete_id FROM user_athletes WHERE user_id = ? ORDER BY athlete_id;
+
+-- name: GetUserAthletes :many
+SELECT a.id, a.name, a.grade, a.division, a.personal_record, a.events, a.name_visibility, a.parental_consent, a.created_at, a.updated_at
+FROM athletes a
+JOIN user_athletes ua ON ua.athlete_id = a.id
+WHERE ua.user_id = ?
+ORDER BY a.name;
+
+-- name: AddUserAthlete :exec
+INSERT INTO user_athletes (user_id, athlete_id) VALUES (?, ?);
+
+-- name: DeleteUserAthletes :exec
+DELETE FROM user_athletes WHERE user_id = ?;
+
+-- =====================
+-- SESSIONS
+-- =====================
+
+-- name: GetSessionByTokenHash :one
+SELECT id, token_hash, user_id, expires_at, last_seen_at, user_agent, ip_address, created_at
+FROM sessions
+WHERE token_hash = ?;
+
+-- name: CreateSession :execresult
+INSERT INTO sessions (token_hash, user_id, expires_at, user_agent, ip_address)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: TouchSession :exec
+UPDATE sessions SET last_seen_at = ? WHERE id = ?;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions WHERE id = ?;
+
+-- name: DeleteUserSessions :exec
+DELETE FROM sessions WHERE user_id = ?;
+
+-- name: DeleteOtherUserSessions :exec
+DELETE FROM sessions WHERE user_id = ? AND id <> ?;
+
+-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions WHERE expires_at < ?;
+
+-- =====================
+-- ACCOUNT TOKENS
+-- =====================
+
+-- name: GetAccountTokenByHash :one
+SELECT id, user_id, purpose, token_hash, expires_at, used_at, created_by, created_at
+FROM account_tokens
+WHERE token_hash = ?;
+
+-- name: CreateAccountToken :exec
+INSERT INTO account_tokens (user_id, purpose, token_hash, expires_at, created_by)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: UseAccountToken :execresult
+UPDATE account_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = ? AND used_at IS NULL;
+
+-- name: DeleteUnusedAccountTokens :exec
+DELETE FROM account_tokens WHERE user_id = ? AND purpose = ? AND used_at IS NULL;
+
+-- =====================
+-- API TOKENS
+-- =====================
+
+-- name: GetAPITokenByHash :one
+SELECT id, user_id, name, token_hash, token_prefix, scopes, meet_id, expires_at, last_used_at, last_used_ip, revoked_at, created_by, created_at
+FROM api_tokens
+WHERE token_hash = ?;
+
+-- name: GetAPITokenByID :one
+SELECT id, user_id, name, token_hash, token_prefix, scopes, meet_id, expires_at, last_used_at, last_used_ip, revoked_at, created_by, created_at
+FROM api_tokens
+WHERE id = ?;
+
+-- name: GetUserAPITokens :many
+SELECT id, user_id, name, token_hash, token_prefix, scopes, meet_id, expires_at, last_used_at, last_used_ip, revoked_at, created_by, created_at
+FROM api_tokens
+WHERE user_id = ?
+ORDER BY id DESC;
+
+-- name: CreateAPIToken :execresult
+INSERT INTO api_tokens (user_id, name, token_hash, token_prefix, scopes, meet_id, expires_at, created_by)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: TouchAPIToken :exec
+UPDATE api_tokens SET last_used_at = ?, last_used_ip = ? WHERE id = ?;
+
+-- name: RevokeAPIToken :exec
+UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL;
+
+-- name: RevokeUserAPITokens :exec
+UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = ? AND revoked_at IS NULL;
+
+-- =====================
+-- TWO-FACTOR AUTHENTICATION
+-- =====================
+
+-- name: GetUserTOTP :one
+SELECT user_id, secret, confirmed_at, last_used_step, created_at
+FROM user_totp
+WHERE user_id = ?;
+
+-- name: CreateUserTOTP :exec
+INSERT INTO user_totp (user_id, secret) VALUES (?, ?);
+
+-- name: ConfirmUserTOTP :execrows
+UPDATE user_totp SET confirmed_at = CURRENT_TIMESTAMP, last_used_step = sqlc.arg(last_used_step)
+WHERE user_id = sqlc.arg(user_id) AND confirmed_at IS NULL AND last_used_step < sqlc.arg(last_used_step);
+
+-- name: UpdateTOTPStep :execrows
+UPDATE user_totp SET last_used_step = sqlc.arg(last_used_step)
+WHERE user_id = sqlc.arg(user_id) AND last_used_step < sqlc.arg(last_used_step);
+
+-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp WHERE user_id = ?;
+
+-- name: GetRecoveryCode :one
+SELECT id, user_id, code_hash, used_at, created_at
+FROM recovery_codes
+WHERE user_id = ? AND code_hash = ? AND used_at IS NULL;
+
+-- name: CountRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?);
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes SET used_at = CURRENT_TIMESTAMP WHERE id = ? AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes WHERE user_id = ?;
+
+-- =====================
+-- AUDIT LOG
+-- =====================
+
+-- name: CreateAuditEntry :exec
+INSERT INTO audit_log (user_id, username, action, entity_type, entity_id, before_data, after_data)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListAuditEntries :many
+SELECT id, user_id, username, action, entity_type, entity_id, before_data, after_data, created_at
+FROM audit_log
+WHERE (sqlc.arg(entity_type) = '' OR entity_type = sqlc.arg(entity_type))
+  AND (sqlc.arg(entity_id) = 0 OR entity_id = sqlc.arg(entity_id))
+  AND (sqlc.arg(user_id) = 0 OR user_id = sqlc.arg(user_id))
+  AND (sqlc.arg(action) = '' OR action = sqlc.arg(action))
+  AND COALESCE(created_at >= sqlc.narg('since'), TRUE)
+  AND COALESCE(created_at < sqlc.narg('until'), TRUE)
+ORDER BY id DESC
+LIMIT ? OFFSET ?;
+
+-- name: GetEntityAuditEntries :many
+SELECT id, user_id, username, action, entity_type, entity_id, before_data, after_data, created_at
+FROM audit_log
+WHERE entity_type = ? AND entity_id = ?
+ORDER BY id;
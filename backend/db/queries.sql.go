@@ -11,14 +11,129 @@ import (
 	"time"
 )
 
+const addUserAthlete = `-- name: AddUserAthlete :exec
+INSERT INTO user_athletes (user_id, athlete_id) VALUES (?, ?)
+`
+
+type AddUserAthleteParams struct {
+	UserID    int32
+	AthleteID int32
+}
+
+func (q *Queries) AddUserAthlete(ctx context.Context, arg AddUserAthleteParams) error {
+	_, err := q.db.ExecContext(ctx, addUserAthlete,
+		arg.UserID,
+		arg.AthleteID,
+	)
+	return err
+}
+
+const confirmUserTOTP = `-- name: ConfirmUserTOTP :execrows
+UPDATE user_totp SET confirmed_at = CURRENT_TIMESTAMP, last_used_step = ?
+WHERE user_id = ? AND confirmed_at IS NULL AND last_used_step < ?
+`
+
+type ConfirmUserTOTPParams struct {
+	LastUsedStep int64
+	UserID       int32
+}
+
+func (q *Queries) ConfirmUserTOTP(ctx context.Context, arg ConfirmUserTOTPParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, confirmUserTOTP,
+		arg.LastUsedStep,
+		arg.UserID,
+		arg.LastUsedStep,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countHeadCoaches = `-- name: CountHeadCoaches :one
+SELECT COUNT(*) FROM users WHERE role = 'head_coach' AND active = TRUE
+`
+
+func (q *Queries) CountHeadCoaches(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countHeadCoaches)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRecoveryCodes = `-- name: CountRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL
+`
+
+func (q *Queries) CountRecoveryCodes(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRecoveryCodes, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAPIToken = `-- name: CreateAPIToken :execresult
+INSERT INTO api_tokens (user_id, name, token_hash, token_prefix, scopes, meet_id, expires_at, created_by)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateAPITokenParams struct {
+	UserID      int32
+	Name        string
+	TokenHash   string
+	TokenPrefix string
+	Scopes      string
+	MeetID      sql.NullInt32
+	ExpiresAt   sql.NullTime
+	CreatedBy   sql.NullInt32
+}
+
+func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createAPIToken,
+		arg.UserID,
+		arg.Name,
+		arg.TokenHash,
+		arg.TokenPrefix,
+		arg.Scopes,
+		arg.MeetID,
+		arg.ExpiresAt,
+		arg.CreatedBy,
+	)
+}
+
+const createAccountToken = `-- name: CreateAccountToken :exec
+INSERT INTO account_tokens (user_id, purpose, token_hash, expires_at, created_by)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateAccountTokenParams struct {
+	UserID    int32
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedBy sql.NullInt32
+}
+
+func (q *Queries) CreateAccountToken(ctx context.Context, arg CreateAccountTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createAccountToken,
+		arg.UserID,
+		arg.Purpose,
+		arg.TokenHash,
+		arg.ExpiresAt,
+		arg.CreatedBy,
+	)
+	return err
+}
+
 const createAthlete = `-- name: CreateAthlete :execresult
-INSERT INTO athletes (name, grade, personal_record, events)
-VALUES (?, ?, ?, ?)
+INSERT INTO athletes (name, grade, division, personal_record, events)
+VALUES (?, ?, ?, ?, ?)
 `
 
 type CreateAthleteParams struct {
 	Name           string
 	Grade          int32
+	Division       sql.NullString
 	PersonalRecord sql.NullString
 	Events         sql.NullString
 }
@@ -27,11 +142,86 @@ func (q *Queries) CreateAthlete(ctx context.Context, arg CreateAthleteParams) (s
 	return q.db.ExecContext(ctx, createAthlete,
 		arg.Name,
 		arg.Grade,
+		arg.Division,
 		arg.PersonalRecord,
 		arg.Events,
 	)
 }
 
+const createAuditEntry = `-- name: CreateAuditEntry :exec
+
+INSERT INTO audit_log (user_id, username, action, entity_type, entity_id, before_data, after_data)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateAuditEntryParams struct {
+	UserID     sql.NullInt32
+	Username   sql.NullString
+	Action     string
+	EntityType string
+	EntityID   int32
+	BeforeData sql.NullString
+	AfterData  sql.NullString
+}
+
+// =====================
+// AUDIT LOG
+// =====================
+func (q *Queries) CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createAuditEntry,
+		arg.UserID,
+		arg.Username,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.BeforeData,
+		arg.AfterData,
+	)
+	return err
+}
+
+const createAwardRule = `-- name: CreateAwardRule :execresult
+INSERT INTO award_rules (name, award_type, criterion, threshold, winners, division, description)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateAwardRuleParams struct {
+	Name        string
+	AwardType   string
+	Criterion   sql.NullString
+	Threshold   int32
+	Winners     int32
+	Division    sql.NullString
+	Description sql.NullString
+}
+
+func (q *Queries) CreateAwardRule(ctx context.Context, arg CreateAwardRuleParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createAwardRule,
+		arg.Name,
+		arg.AwardType,
+		arg.Criterion,
+		arg.Threshold,
+		arg.Winners,
+		arg.Division,
+		arg.Description,
+	)
+}
+
+const createCourse = `-- name: CreateCourse :execresult
+INSERT INTO courses (name, location, description)
+VALUES (?, ?, ?)
+`
+
+type CreateCourseParams struct {
+	Name        string
+	Location    sql.NullString
+	Description sql.NullString
+}
+
+func (q *Queries) CreateCourse(ctx context.Context, arg CreateCourseParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createCourse, arg.Name, arg.Location, arg.Description)
+}
+
 const createEventType = `-- name: CreateEventType :execresult
 INSERT INTO event_types (name, distance, description)
 VALUES (?, ?, ?)
@@ -48,16 +238,23 @@ func (q *Queries) CreateEventType(ctx context.Context, arg CreateEventTypeParams
 }
 
 const createMeet = `-- name: CreateMeet :execresult
-INSERT INTO meets (name, date, time, location, description)
-VALUES (?, ?, ?, ?, ?)
+INSERT INTO meets (name, date, time, location, description, course_id, temperature_f, humidity_pct, wind_mph, precipitation, footing, championship)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type CreateMeetParams struct {
-	Name        string
-	Date        time.Time
-	Time        sql.NullString
-	Location    sql.NullString
-	Description sql.NullString
+	Name          string
+	Date          time.Time
+	Time          sql.NullString
+	Location      sql.NullString
+	Description   sql.NullString
+	CourseID      sql.NullInt32
+	TemperatureF  sql.NullInt32
+	HumidityPct   sql.NullInt32
+	WindMph       sql.NullInt32
+	Precipitation sql.NullString
+	Footing       sql.NullString
+	Championship  bool
 }
 
 func (q *Queries) CreateMeet(ctx context.Context, arg CreateMeetParams) (sql.Result, error) {
@@ -67,7 +264,107 @@ func (q *Queries) CreateMeet(ctx context.Context, arg CreateMeetParams) (sql.Res
 		arg.Time,
 		arg.Location,
 		arg.Description,
+		arg.CourseID,
+		arg.TemperatureF,
+		arg.HumidityPct,
+		arg.WindMph,
+		arg.Precipitation,
+		arg.Footing,
+		arg.Championship,
+	)
+}
+
+const createMeetDateChange = `-- name: CreateMeetDateChange :exec
+INSERT INTO meet_date_changes (meet_id, previous_date, new_date, reason)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateMeetDateChangeParams struct {
+	MeetID       int32
+	PreviousDate time.Time
+	NewDate      time.Time
+	Reason       sql.NullString
+}
+
+func (q *Queries) CreateMeetDateChange(ctx context.Context, arg CreateMeetDateChangeParams) error {
+	_, err := q.db.ExecContext(ctx, createMeetDateChange,
+		arg.MeetID,
+		arg.PreviousDate,
+		arg.NewDate,
+		arg.Reason,
+	)
+	return err
+}
+
+const createQualificationRule = `-- name: CreateQualificationRule :exec
+INSERT INTO qualification_rules (meet_id, target_meet_id, top_teams, top_individuals, top_individuals_not_on_teams)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateQualificationRuleParams struct {
+	MeetID                   int32
+	TargetMeetID             int32
+	TopTeams                 int32
+	TopIndividuals           int32
+	TopIndividualsNotOnTeams int32
+}
+
+func (q *Queries) CreateQualificationRule(ctx context.Context, arg CreateQualificationRuleParams) error {
+	_, err := q.db.ExecContext(ctx, createQualificationRule,
+		arg.MeetID,
+		arg.TargetMeetID,
+		arg.TopTeams,
+		arg.TopIndividuals,
+		arg.TopIndividualsNotOnTeams,
+	)
+	return err
+}
+
+const createRaceConditions = `-- name: CreateRaceConditions :exec
+INSERT INTO race_conditions (meet_id, event_type_id, temperature_f, humidity_pct, wind_mph, precipitation, footing, notes)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateRaceConditionsParams struct {
+	MeetID        int32
+	EventTypeID   int32
+	TemperatureF  sql.NullInt32
+	HumidityPct   sql.NullInt32
+	WindMph       sql.NullInt32
+	Precipitation sql.NullString
+	Footing       sql.NullString
+	Notes         sql.NullString
+}
+
+func (q *Queries) CreateRaceConditions(ctx context.Context, arg CreateRaceConditionsParams) error {
+	_, err := q.db.ExecContext(ctx, createRaceConditions,
+		arg.MeetID,
+		arg.EventTypeID,
+		arg.TemperatureF,
+		arg.HumidityPct,
+		arg.WindMph,
+		arg.Precipitation,
+		arg.Footing,
+		arg.Notes,
+	)
+	return err
+}
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?)
+`
+
+type CreateRecoveryCodeParams struct {
+	UserID   int32
+	CodeHash string
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error {
+	_, err := q.db.ExecContext(ctx, createRecoveryCode,
+		arg.UserID,
+		arg.CodeHash,
 	)
+	return err
 }
 
 const createResult = `-- name: CreateResult :execresult
@@ -93,6 +390,116 @@ func (q *Queries) CreateResult(ctx context.Context, arg CreateResultParams) (sql
 	)
 }
 
+const createSession = `-- name: CreateSession :execresult
+INSERT INTO sessions (token_hash, user_id, expires_at, user_agent, ip_address)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateSessionParams struct {
+	TokenHash string
+	UserID    int32
+	ExpiresAt time.Time
+	UserAgent sql.NullString
+	IpAddress sql.NullString
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createSession,
+		arg.TokenHash,
+		arg.UserID,
+		arg.ExpiresAt,
+		arg.UserAgent,
+		arg.IpAddress,
+	)
+}
+
+const createTeamFinish = `-- name: CreateTeamFinish :exec
+INSERT INTO team_finishes (meet_id, division, place, score)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateTeamFinishParams struct {
+	MeetID   int32
+	Division string
+	Place    int32
+	Score    sql.NullInt32
+}
+
+func (q *Queries) CreateTeamFinish(ctx context.Context, arg CreateTeamFinishParams) error {
+	_, err := q.db.ExecContext(ctx, createTeamFinish,
+		arg.MeetID,
+		arg.Division,
+		arg.Place,
+		arg.Score,
+	)
+	return err
+}
+
+const createTimeStandard = `-- name: CreateTimeStandard :execresult
+INSERT INTO time_standards (name, event_type_id, division, time, category, description)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateTimeStandardParams struct {
+	Name        string
+	EventTypeID int32
+	Division    sql.NullString
+	Time        string
+	Category    string
+	Description sql.NullString
+}
+
+func (q *Queries) CreateTimeStandard(ctx context.Context, arg CreateTimeStandardParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createTimeStandard,
+		arg.Name,
+		arg.EventTypeID,
+		arg.Division,
+		arg.Time,
+		arg.Category,
+		arg.Description,
+	)
+}
+
+const createUser = `-- name: CreateUser :execresult
+INSERT INTO users (username, password_hash, display_name, email, role, password_changed_at)
+VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+`
+
+type CreateUserParams struct {
+	Username     string
+	PasswordHash string
+	DisplayName  sql.NullString
+	Email        sql.NullString
+	Role         string
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createUser,
+		arg.Username,
+		arg.PasswordHash,
+		arg.DisplayName,
+		arg.Email,
+		arg.Role,
+	)
+}
+
+const createUserTOTP = `-- name: CreateUserTOTP :exec
+INSERT INTO user_totp (user_id, secret) VALUES (?, ?)
+`
+
+type CreateUserTOTPParams struct {
+	UserID int32
+	Secret string
+}
+
+func (q *Queries) CreateUserTOTP(ctx context.Context, arg CreateUserTOTPParams) error {
+	_, err := q.db.ExecContext(ctx, createUserTOTP,
+		arg.UserID,
+		arg.Secret,
+	)
+	return err
+}
+
 const deleteAthlete = `-- name: DeleteAthlete :exec
 DELETE FROM athletes WHERE id = ?
 `
@@ -102,6 +509,24 @@ func (q *Queries) DeleteAthlete(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteAwardRule = `-- name: DeleteAwardRule :exec
+DELETE FROM award_rules WHERE id = ?
+`
+
+func (q *Queries) DeleteAwardRule(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteAwardRule, id)
+	return err
+}
+
+const deleteCourse = `-- name: DeleteCourse :exec
+DELETE FROM courses WHERE id = ?
+`
+
+func (q *Queries) DeleteCourse(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteCourse, id)
+	return err
+}
+
 const deleteEventType = `-- name: DeleteEventType :exec
 DELETE FROM event_types WHERE id = ?
 `
@@ -111,6 +536,15 @@ func (q *Queries) DeleteEventType(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteExpiredSessions = `-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions WHERE expires_at < ?
+`
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context, expiresAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredSessions, expiresAt)
+	return err
+}
+
 const deleteMeet = `-- name: DeleteMeet :exec
 DELETE FROM meets WHERE id = ?
 `
@@ -120,6 +554,55 @@ func (q *Queries) DeleteMeet(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteOtherUserSessions = `-- name: DeleteOtherUserSessions :exec
+DELETE FROM sessions WHERE user_id = ? AND id <> ?
+`
+
+type DeleteOtherUserSessionsParams struct {
+	UserID int32
+	ID     int32
+}
+
+func (q *Queries) DeleteOtherUserSessions(ctx context.Context, arg DeleteOtherUserSessionsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOtherUserSessions,
+		arg.UserID,
+		arg.ID,
+	)
+	return err
+}
+
+const deleteQualificationRule = `-- name: DeleteQualificationRule :exec
+DELETE FROM qualification_rules WHERE meet_id = ?
+`
+
+func (q *Queries) DeleteQualificationRule(ctx context.Context, meetID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteQualificationRule, meetID)
+	return err
+}
+
+const deleteRaceConditions = `-- name: DeleteRaceConditions :exec
+DELETE FROM race_conditions WHERE meet_id = ? AND event_type_id = ?
+`
+
+type DeleteRaceConditionsParams struct {
+	MeetID      int32
+	EventTypeID int32
+}
+
+func (q *Queries) DeleteRaceConditions(ctx context.Context, arg DeleteRaceConditionsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteRaceConditions, arg.MeetID, arg.EventTypeID)
+	return err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes WHERE user_id = ?
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodes, userID)
+	return err
+}
+
 const deleteResult = `-- name: DeleteResult :exec
 DELETE FROM results WHERE id = ?
 `
@@ -129,52 +612,311 @@ func (q *Queries) DeleteResult(ctx context.Context, id int32) error {
 	return err
 }
 
-const getAllAthletes = `-- name: GetAllAthletes :many
-
-SELECT id, name, grade, personal_record, events, created_at, updated_at
-FROM athletes
-ORDER BY name
+const deleteSession = `-- name: DeleteSession :exec
+DELETE FROM sessions WHERE id = ?
 `
 
-// =====================
-// ATHLETES
-// =====================
-func (q *Queries) GetAllAthletes(ctx context.Context) ([]Athlete, error) {
-	rows, err := q.db.QueryContext(ctx, getAllAthletes)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Athlete
-	for rows.Next() {
-		var i Athlete
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.Grade,
-			&i.PersonalRecord,
-			&i.Events,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) DeleteSession(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteSession, id)
+	return err
 }
 
-const getAllEventTypes = `-- name: GetAllEventTypes :many
-
-SELECT id, name, distance, description, created_at, updated_at
-FROM event_types
-ORDER BY name
+const deleteTeamFinish = `-- name: DeleteTeamFinish :exec
+DELETE FROM team_finishes WHERE meet_id = ? AND division = ?
+`
+
+type DeleteTeamFinishParams struct {
+	MeetID   int32
+	Division string
+}
+
+func (q *Queries) DeleteTeamFinish(ctx context.Context, arg DeleteTeamFinishParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTeamFinish, arg.MeetID, arg.Division)
+	return err
+}
+
+const deleteTimeStandard = `-- name: DeleteTimeStandard :exec
+DELETE FROM time_standards WHERE id = ?
+`
+
+func (q *Queries) DeleteTimeStandard(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteTimeStandard, id)
+	return err
+}
+
+const deleteUnusedAccountTokens = `-- name: DeleteUnusedAccountTokens :exec
+DELETE FROM account_tokens WHERE user_id = ? AND purpose = ? AND used_at IS NULL
+`
+
+type DeleteUnusedAccountTokensParams struct {
+	UserID  int32
+	Purpose string
+}
+
+func (q *Queries) DeleteUnusedAccountTokens(ctx context.Context, arg DeleteUnusedAccountTokensParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUnusedAccountTokens,
+		arg.UserID,
+		arg.Purpose,
+	)
+	return err
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users WHERE id = ?
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
+const deleteUserAthletes = `-- name: DeleteUserAthletes :exec
+DELETE FROM user_athletes WHERE user_id = ?
+`
+
+func (q *Queries) DeleteUserAthletes(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUserAthletes, userID)
+	return err
+}
+
+const deleteUserSessions = `-- name: DeleteUserSessions :exec
+DELETE FROM sessions WHERE user_id = ?
+`
+
+func (q *Queries) DeleteUserSessions(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUserSessions, userID)
+	return err
+}
+
+const deleteUserTOTP = `-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp WHERE user_id = ?
+`
+
+func (q *Queries) DeleteUserTOTP(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTOTP, userID)
+	return err
+}
+
+const getAPITokenByHash = `-- name: GetAPITokenByHash :one
+
+SELECT id, user_id, name, token_hash, token_prefix, scopes, meet_id, expires_at, last_used_at, last_used_ip, revoked_at, created_by, created_at
+FROM api_tokens
+WHERE token_hash = ?
+`
+
+// =====================
+// API TOKENS
+// =====================
+func (q *Queries) GetAPITokenByHash(ctx context.Context, tokenHash string) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, getAPITokenByHash, tokenHash)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenHash,
+		&i.TokenPrefix,
+		&i.Scopes,
+		&i.MeetID,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAPITokenByID = `-- name: GetAPITokenByID :one
+SELECT id, user_id, name, token_hash, token_prefix, scopes, meet_id, expires_at, last_used_at, last_used_ip, revoked_at, created_by, created_at
+FROM api_tokens
+WHERE id = ?
+`
+
+func (q *Queries) GetAPITokenByID(ctx context.Context, id int32) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, getAPITokenByID, id)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenHash,
+		&i.TokenPrefix,
+		&i.Scopes,
+		&i.MeetID,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAccountTokenByHash = `-- name: GetAccountTokenByHash :one
+
+SELECT id, user_id, purpose, token_hash, expires_at, used_at, created_by, created_at
+FROM account_tokens
+WHERE token_hash = ?
+`
+
+// =====================
+// ACCOUNT TOKENS
+// =====================
+func (q *Queries) GetAccountTokenByHash(ctx context.Context, tokenHash string) (AccountToken, error) {
+	row := q.db.QueryRowContext(ctx, getAccountTokenByHash, tokenHash)
+	var i AccountToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Purpose,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAllAthletes = `-- name: GetAllAthletes :many
+
+SELECT id, name, grade, division, personal_record, events, name_visibility, parental_consent, created_at, updated_at
+FROM athletes
+ORDER BY name
+`
+
+// =====================
+// ATHLETES
+// =====================
+func (q *Queries) GetAllAthletes(ctx context.Context) ([]Athlete, error) {
+	rows, err := q.db.QueryContext(ctx, getAllAthletes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Athlete
+	for rows.Next() {
+		var i Athlete
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Grade,
+			&i.Division,
+			&i.PersonalRecord,
+			&i.Events,
+			&i.NameVisibility,
+			&i.ParentalConsent,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllAwardRules = `-- name: GetAllAwardRules :many
+
+SELECT id, name, award_type, criterion, threshold, winners, division, description, created_at, updated_at
+FROM award_rules
+ORDER BY award_type, id
+`
+
+// =====================
+// AWARD RULES
+// =====================
+func (q *Queries) GetAllAwardRules(ctx context.Context) ([]AwardRule, error) {
+	rows, err := q.db.QueryContext(ctx, getAllAwardRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AwardRule
+	for rows.Next() {
+		var i AwardRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.AwardType,
+			&i.Criterion,
+			&i.Threshold,
+			&i.Winners,
+			&i.Division,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllCourses = `-- name: GetAllCourses :many
+
+SELECT id, name, location, description, correction_factor, sample_size, factor_updated_at, created_at, updated_at
+FROM courses
+ORDER BY name
+`
+
+// =====================
+// COURSES
+// =====================
+func (q *Queries) GetAllCourses(ctx context.Context) ([]Course, error) {
+	rows, err := q.db.QueryContext(ctx, getAllCourses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Course
+	for rows.Next() {
+		var i Course
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Location,
+			&i.Description,
+			&i.CorrectionFactor,
+			&i.SampleSize,
+			&i.FactorUpdatedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllEventTypes = `-- name: GetAllEventTypes :many
+
+SELECT id, name, distance, description, created_at, updated_at
+FROM event_types
+ORDER BY name
 `
 
 // =====================
@@ -212,32 +954,1117 @@ func (q *Queries) GetAllEventTypes(ctx context.Context) ([]EventType, error) {
 
 const getAllMeets = `-- name: GetAllMeets :many
 
-SELECT id, name, date, time, location, description, created_at, updated_at
+SELECT id, name, date, time, location, description, course_id, temperature_f, humidity_pct, wind_mph, precipitation, footing,
+    status, original_date, official, finalized_at, championship, created_at, updated_at
 FROM meets
 ORDER BY date
 `
 
-// =====================
-// MEETS
-// =====================
-func (q *Queries) GetAllMeets(ctx context.Context) ([]Meet, error) {
-	rows, err := q.db.QueryContext(ctx, getAllMeets)
+// =====================
+// MEETS
+// =====================
+func (q *Queries) GetAllMeets(ctx context.Context) ([]Meet, error) {
+	rows, err := q.db.QueryContext(ctx, getAllMeets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Meet
+	for rows.Next() {
+		var i Meet
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Date,
+			&i.Time,
+			&i.Location,
+			&i.Description,
+			&i.CourseID,
+			&i.TemperatureF,
+			&i.HumidityPct,
+			&i.WindMph,
+			&i.Precipitation,
+			&i.Footing,
+			&i.Status,
+			&i.OriginalDate,
+			&i.Official,
+			&i.FinalizedAt,
+			&i.Championship,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllRaceConditions = `-- name: GetAllRaceConditions :many
+SELECT id, meet_id, event_type_id, temperature_f, humidity_pct, wind_mph, precipitation, footing, notes, created_at, updated_at
+FROM race_conditions
+`
+
+func (q *Queries) GetAllRaceConditions(ctx context.Context) ([]RaceCondition, error) {
+	rows, err := q.db.QueryContext(ctx, getAllRaceConditions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RaceCondition
+	for rows.Next() {
+		var i RaceCondition
+		if err := rows.Scan(
+			&i.ID,
+			&i.MeetID,
+			&i.EventTypeID,
+			&i.TemperatureF,
+			&i.HumidityPct,
+			&i.WindMph,
+			&i.Precipitation,
+			&i.Footing,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllResults = `-- name: GetAllResults :many
+SELECT
+    r.id,
+    r.athlete_id,
+    r.meet_id,
+    r.event_type_id,
+    r.time,
+    r.place,
+    r.created_at,
+    a.name as athlete_name,
+    m.name as meet_name,
+    m.date as meet_date,
+    et.name as event_name,
+    c.correction_factor as course_factor
+FROM results r
+JOIN athletes a ON r.athlete_id = a.id
+JOIN meets m ON r.meet_id = m.id
+LEFT JOIN event_types et ON r.event_type_id = et.id
+LEFT JOIN courses c ON m.course_id = c.id
+ORDER BY m.date DESC, r.place
+`
+
+type GetAllResultsRow struct {
+	ID           int32
+	AthleteID    int32
+	MeetID       int32
+	EventTypeID  sql.NullInt32
+	Time         string
+	Place        sql.NullInt32
+	CreatedAt    sql.NullTime
+	AthleteName  string
+	MeetName     string
+	MeetDate     time.Time
+	EventName    sql.NullString
+	CourseFactor sql.NullFloat64
+}
+
+func (q *Queries) GetAllResults(ctx context.Context) ([]GetAllResultsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAllResultsRow
+	for rows.Next() {
+		var i GetAllResultsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.MeetID,
+			&i.EventTypeID,
+			&i.Time,
+			&i.Place,
+			&i.CreatedAt,
+			&i.AthleteName,
+			&i.MeetName,
+			&i.MeetDate,
+			&i.EventName,
+			&i.CourseFactor,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllTimeStandards = `-- name: GetAllTimeStandards :many
+
+SELECT id, name, event_type_id, division, time, category, description, created_at, updated_at
+FROM time_standards
+ORDER BY event_type_id, division, time
+`
+
+// =====================
+// TIME STANDARDS
+// =====================
+func (q *Queries) GetAllTimeStandards(ctx context.Context) ([]TimeStandard, error) {
+	rows, err := q.db.QueryContext(ctx, getAllTimeStandards)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TimeStandard
+	for rows.Next() {
+		var i TimeStandard
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.EventTypeID,
+			&i.Division,
+			&i.Time,
+			&i.Category,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllUsers = `-- name: GetAllUsers :many
+
+SELECT id, username, password_hash, display_name, email, role, active, password_changed_at, created_at, updated_at
+FROM users
+ORDER BY username
+`
+
+// =====================
+// USERS
+// =====================
+func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getAllUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.PasswordHash,
+			&i.DisplayName,
+			&i.Email,
+			&i.Role,
+			&i.Active,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAthleteByID = `-- name: GetAthleteByID :one
+SELECT id, name, grade, division, personal_record, events, name_visibility, parental_consent, created_at, updated_at
+FROM athletes
+WHERE id = ?
+`
+
+func (q *Queries) GetAthleteByID(ctx context.Context, id int32) (Athlete, error) {
+	row := q.db.QueryRowContext(ctx, getAthleteByID, id)
+	var i Athlete
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Grade,
+		&i.Division,
+		&i.PersonalRecord,
+		&i.Events,
+		&i.NameVisibility,
+		&i.ParentalConsent,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getAthletePrivacy = `-- name: GetAthletePrivacy :many
+SELECT id, name_visibility, parental_consent FROM athletes
+`
+
+type GetAthletePrivacyRow struct {
+	ID              int32
+	NameVisibility  string
+	ParentalConsent bool
+}
+
+func (q *Queries) GetAthletePrivacy(ctx context.Context) ([]GetAthletePrivacyRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAthletePrivacy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAthletePrivacyRow
+	for rows.Next() {
+		var i GetAthletePrivacyRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.NameVisibility,
+			&i.ParentalConsent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAthleteResults = `-- name: GetAthleteResults :many
+SELECT
+    r.id,
+    r.athlete_id,
+    r.meet_id,
+    r.event_type_id,
+    r.time,
+    r.place,
+    r.created_at,
+    m.name as meet_name,
+    m.date as meet_date,
+    et.name as event_name,
+    c.correction_factor as course_factor
+FROM results r
+JOIN meets m ON r.meet_id = m.id
+LEFT JOIN event_types et ON r.event_type_id = et.id
+LEFT JOIN courses c ON m.course_id = c.id
+WHERE r.athlete_id = ?
+ORDER BY m.date DESC
+`
+
+type GetAthleteResultsRow struct {
+	ID           int32
+	AthleteID    int32
+	MeetID       int32
+	EventTypeID  sql.NullInt32
+	Time         string
+	Place        sql.NullInt32
+	CreatedAt    sql.NullTime
+	MeetName     string
+	MeetDate     time.Time
+	EventName    sql.NullString
+	CourseFactor sql.NullFloat64
+}
+
+func (q *Queries) GetAthleteResults(ctx context.Context, athleteID int32) ([]GetAthleteResultsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAthleteResults, athleteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAthleteResultsRow
+	for rows.Next() {
+		var i GetAthleteResultsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.MeetID,
+			&i.EventTypeID,
+			&i.Time,
+			&i.Place,
+			&i.CreatedAt,
+			&i.MeetName,
+			&i.MeetDate,
+			&i.EventName,
+			&i.CourseFactor,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAwardRuleByID = `-- name: GetAwardRuleByID :one
+SELECT id, name, award_type, criterion, threshold, winners, division, description, created_at, updated_at
+FROM award_rules
+WHERE id = ?
+`
+
+func (q *Queries) GetAwardRuleByID(ctx context.Context, id int32) (AwardRule, error) {
+	row := q.db.QueryRowContext(ctx, getAwardRuleByID, id)
+	var i AwardRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.AwardType,
+		&i.Criterion,
+		&i.Threshold,
+		&i.Winners,
+		&i.Division,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getCourseByID = `-- name: GetCourseByID :one
+SELECT id, name, location, description, correction_factor, sample_size, factor_updated_at, created_at, updated_at
+FROM courses
+WHERE id = ?
+`
+
+func (q *Queries) GetCourseByID(ctx context.Context, id int32) (Course, error) {
+	row := q.db.QueryRowContext(ctx, getCourseByID, id)
+	var i Course
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Location,
+		&i.Description,
+		&i.CorrectionFactor,
+		&i.SampleSize,
+		&i.FactorUpdatedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getCourseRaces = `-- name: GetCourseRaces :many
+SELECT r.athlete_id, r.event_type_id, r.time, m.course_id
+FROM results r
+JOIN meets m ON r.meet_id = m.id
+WHERE m.course_id IS NOT NULL
+`
+
+type GetCourseRacesRow struct {
+	AthleteID   int32
+	EventTypeID sql.NullInt32
+	Time        string
+	CourseID    sql.NullInt32
+}
+
+func (q *Queries) GetCourseRaces(ctx context.Context) ([]GetCourseRacesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCourseRaces)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCourseRacesRow
+	for rows.Next() {
+		var i GetCourseRacesRow
+		if err := rows.Scan(
+			&i.AthleteID,
+			&i.EventTypeID,
+			&i.Time,
+			&i.CourseID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEntityAuditEntries = `-- name: GetEntityAuditEntries :many
+SELECT id, user_id, username, action, entity_type, entity_id, before_data, after_data, created_at
+FROM audit_log
+WHERE entity_type = ? AND entity_id = ?
+ORDER BY id
+`
+
+type GetEntityAuditEntriesParams struct {
+	EntityType string
+	EntityID   int32
+}
+
+func (q *Queries) GetEntityAuditEntries(ctx context.Context, arg GetEntityAuditEntriesParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, getEntityAuditEntries,
+		arg.EntityType,
+		arg.EntityID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.BeforeData,
+			&i.AfterData,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventTypeByID = `-- name: GetEventTypeByID :one
+SELECT id, name, distance, description, created_at, updated_at
+FROM event_types
+WHERE id = ?
+`
+
+func (q *Queries) GetEventTypeByID(ctx context.Context, id int32) (EventType, error) {
+	row := q.db.QueryRowContext(ctx, getEventTypeByID, id)
+	var i EventType
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Distance,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getLeaderboardTimes = `-- name: GetLeaderboardTimes :many
+SELECT
+    r.id,
+    r.time,
+    r.place,
+    a.id as athlete_id,
+    a.name as athlete_name,
+    a.grade as athlete_grade,
+    m.id as meet_id,
+    m.name as meet_name,
+    m.date as meet_date,
+    et.name as event_name,
+    c.correction_factor as course_factor
+FROM results r
+JOIN athletes a ON r.athlete_id = a.id
+JOIN meets m ON r.meet_id = m.id
+LEFT JOIN event_types et ON r.event_type_id = et.id
+LEFT JOIN courses c ON m.course_id = c.id
+`
+
+type GetLeaderboardTimesRow struct {
+	ID           int32
+	Time         string
+	Place        sql.NullInt32
+	AthleteID    int32
+	AthleteName  string
+	AthleteGrade int32
+	MeetID       int32
+	MeetName     string
+	MeetDate     time.Time
+	EventName    sql.NullString
+	CourseFactor sql.NullFloat64
+}
+
+func (q *Queries) GetLeaderboardTimes(ctx context.Context) ([]GetLeaderboardTimesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardTimes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLeaderboardTimesRow
+	for rows.Next() {
+		var i GetLeaderboardTimesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Time,
+			&i.Place,
+			&i.AthleteID,
+			&i.AthleteName,
+			&i.AthleteGrade,
+			&i.MeetID,
+			&i.MeetName,
+			&i.MeetDate,
+			&i.EventName,
+			&i.CourseFactor,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMeetByID = `-- name: GetMeetByID :one
+SELECT id, name, date, time, location, description, course_id, temperature_f, humidity_pct, wind_mph, precipitation, footing,
+    status, original_date, official, finalized_at, championship, created_at, updated_at
+FROM meets
+WHERE id = ?
+`
+
+func (q *Queries) GetMeetByID(ctx context.Context, id int32) (Meet, error) {
+	row := q.db.QueryRowContext(ctx, getMeetByID, id)
+	var i Meet
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Date,
+		&i.Time,
+		&i.Location,
+		&i.Description,
+		&i.CourseID,
+		&i.TemperatureF,
+		&i.HumidityPct,
+		&i.WindMph,
+		&i.Precipitation,
+		&i.Footing,
+		&i.Status,
+		&i.OriginalDate,
+		&i.Official,
+		&i.FinalizedAt,
+		&i.Championship,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getMeetDateChanges = `-- name: GetMeetDateChanges :many
+SELECT id, meet_id, previous_date, new_date, reason, changed_at
+FROM meet_date_changes
+WHERE meet_id = ?
+ORDER BY changed_at, id
+`
+
+func (q *Queries) GetMeetDateChanges(ctx context.Context, meetID int32) ([]MeetDateChange, error) {
+	rows, err := q.db.QueryContext(ctx, getMeetDateChanges, meetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MeetDateChange
+	for rows.Next() {
+		var i MeetDateChange
+		if err := rows.Scan(
+			&i.ID,
+			&i.MeetID,
+			&i.PreviousDate,
+			&i.NewDate,
+			&i.Reason,
+			&i.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMeetResults = `-- name: GetMeetResults :many
+
+SELECT
+    r.id,
+    r.athlete_id,
+    r.meet_id,
+    r.event_type_id,
+    r.time,
+    r.place,
+    r.created_at,
+    a.name as athlete_name,
+    et.name as event_name,
+    c.correction_factor as course_factor,
+    a.division as athlete_division
+FROM results r
+JOIN athletes a ON r.athlete_id = a.id
+JOIN meets m ON r.meet_id = m.id
+LEFT JOIN event_types et ON r.event_type_id = et.id
+LEFT JOIN courses c ON m.course_id = c.id
+WHERE r.meet_id = ?
+ORDER BY r.place
+`
+
+type GetMeetResultsRow struct {
+	ID              int32
+	AthleteID       int32
+	MeetID          int32
+	EventTypeID     sql.NullInt32
+	Time            string
+	Place           sql.NullInt32
+	CreatedAt       sql.NullTime
+	AthleteName     string
+	EventName       sql.NullString
+	CourseFactor    sql.NullFloat64
+	AthleteDivision sql.NullString
+}
+
+// =====================
+// RESULTS
+// =====================
+func (q *Queries) GetMeetResults(ctx context.Context, meetID int32) ([]GetMeetResultsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMeetResults, meetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMeetResultsRow
+	for rows.Next() {
+		var i GetMeetResultsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.MeetID,
+			&i.EventTypeID,
+			&i.Time,
+			&i.Place,
+			&i.CreatedAt,
+			&i.AthleteName,
+			&i.EventName,
+			&i.CourseFactor,
+			&i.AthleteDivision,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getQualificationRule = `-- name: GetQualificationRule :one
+
+SELECT id, meet_id, target_meet_id, top_teams, top_individuals, top_individuals_not_on_teams, created_at, updated_at
+FROM qualification_rules
+WHERE meet_id = ?
+`
+
+// =====================
+// QUALIFICATION
+// =====================
+func (q *Queries) GetQualificationRule(ctx context.Context, meetID int32) (QualificationRule, error) {
+	row := q.db.QueryRowContext(ctx, getQualificationRule, meetID)
+	var i QualificationRule
+	err := row.Scan(
+		&i.ID,
+		&i.MeetID,
+		&i.TargetMeetID,
+		&i.TopTeams,
+		&i.TopIndividuals,
+		&i.TopIndividualsNotOnTeams,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getQualificationRulesForTarget = `-- name: GetQualificationRulesForTarget :many
+SELECT id, meet_id, target_meet_id, top_teams, top_individuals, top_individuals_not_on_teams, created_at, updated_at
+FROM qualification_rules
+WHERE target_meet_id = ?
+ORDER BY meet_id
+`
+
+func (q *Queries) GetQualificationRulesForTarget(ctx context.Context, targetMeetID int32) ([]QualificationRule, error) {
+	rows, err := q.db.QueryContext(ctx, getQualificationRulesForTarget, targetMeetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []QualificationRule
+	for rows.Next() {
+		var i QualificationRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.MeetID,
+			&i.TargetMeetID,
+			&i.TopTeams,
+			&i.TopIndividuals,
+			&i.TopIndividualsNotOnTeams,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRaceConditionsByMeet = `-- name: GetRaceConditionsByMeet :many
+SELECT id, meet_id, event_type_id, temperature_f, humidity_pct, wind_mph, precipitation, footing, notes, created_at, updated_at
+FROM race_conditions
+WHERE meet_id = ?
+ORDER BY event_type_id
+`
+
+func (q *Queries) GetRaceConditionsByMeet(ctx context.Context, meetID int32) ([]RaceCondition, error) {
+	rows, err := q.db.QueryContext(ctx, getRaceConditionsByMeet, meetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RaceCondition
+	for rows.Next() {
+		var i RaceCondition
+		if err := rows.Scan(
+			&i.ID,
+			&i.MeetID,
+			&i.EventTypeID,
+			&i.TemperatureF,
+			&i.HumidityPct,
+			&i.WindMph,
+			&i.Precipitation,
+			&i.Footing,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecoveryCode = `-- name: GetRecoveryCode :one
+SELECT id, user_id, code_hash, used_at, created_at
+FROM recovery_codes
+WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+`
+
+type GetRecoveryCodeParams struct {
+	UserID   int32
+	CodeHash string
+}
+
+func (q *Queries) GetRecoveryCode(ctx context.Context, arg GetRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, getRecoveryCode,
+		arg.UserID,
+		arg.CodeHash,
+	)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getResultByID = `-- name: GetResultByID :one
+SELECT
+    r.id,
+    r.athlete_id,
+    r.meet_id,
+    r.event_type_id,
+    r.time,
+    r.place,
+    r.created_at,
+    a.name as athlete_name,
+    m.name as meet_name,
+    et.name as event_name
+FROM results r
+JOIN athletes a ON r.athlete_id = a.id
+JOIN meets m ON r.meet_id = m.id
+LEFT JOIN event_types et ON r.event_type_id = et.id
+WHERE r.id = ?
+`
+
+type GetResultByIDRow struct {
+	ID          int32
+	AthleteID   int32
+	MeetID      int32
+	EventTypeID sql.NullInt32
+	Time        string
+	Place       sql.NullInt32
+	CreatedAt   sql.NullTime
+	AthleteName string
+	MeetName    string
+	EventName   sql.NullString
+}
+
+func (q *Queries) GetResultByID(ctx context.Context, id int32) (GetResultByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getResultByID, id)
+	var i GetResultByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.AthleteID,
+		&i.MeetID,
+		&i.EventTypeID,
+		&i.Time,
+		&i.Place,
+		&i.CreatedAt,
+		&i.AthleteName,
+		&i.MeetName,
+		&i.EventName,
+	)
+	return i, err
+}
+
+const getResultHistory = `-- name: GetResultHistory :many
+SELECT
+    r.id,
+    r.athlete_id,
+    r.meet_id,
+    r.event_type_id,
+    r.time,
+    r.place,
+    a.name as athlete_name,
+    a.division as athlete_division,
+    m.name as meet_name,
+    m.date as meet_date,
+    m.championship as meet_championship,
+    et.name as event_name
+FROM results r
+JOIN athletes a ON r.athlete_id = a.id
+JOIN meets m ON r.meet_id = m.id
+LEFT JOIN event_types et ON r.event_type_id = et.id
+ORDER BY m.date, r.meet_id, r.place
+`
+
+type GetResultHistoryRow struct {
+	ID               int32
+	AthleteID        int32
+	MeetID           int32
+	EventTypeID      sql.NullInt32
+	Time             string
+	Place            sql.NullInt32
+	AthleteName      string
+	AthleteDivision  sql.NullString
+	MeetName         string
+	MeetDate         time.Time
+	MeetChampionship bool
+	EventName        sql.NullString
+}
+
+func (q *Queries) GetResultHistory(ctx context.Context) ([]GetResultHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getResultHistory)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetResultHistoryRow
+	for rows.Next() {
+		var i GetResultHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.MeetID,
+			&i.EventTypeID,
+			&i.Time,
+			&i.Place,
+			&i.AthleteName,
+			&i.AthleteDivision,
+			&i.MeetName,
+			&i.MeetDate,
+			&i.MeetChampionship,
+			&i.EventName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getResultsForAthlete = `-- name: GetResultsForAthlete :many
+SELECT id, athlete_id, meet_id, event_type_id, time, place, created_at
+FROM results
+WHERE athlete_id = ?
+ORDER BY id
+`
+
+func (q *Queries) GetResultsForAthlete(ctx context.Context, athleteID int32) ([]Result, error) {
+	rows, err := q.db.QueryContext(ctx, getResultsForAthlete, athleteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Result
+	for rows.Next() {
+		var i Result
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.MeetID,
+			&i.EventTypeID,
+			&i.Time,
+			&i.Place,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getResultsForEventType = `-- name: GetResultsForEventType :many
+SELECT id, athlete_id, meet_id, event_type_id, time, place, created_at
+FROM results
+WHERE event_type_id = ?
+ORDER BY id
+`
+
+func (q *Queries) GetResultsForEventType(ctx context.Context, eventTypeID sql.NullInt32) ([]Result, error) {
+	rows, err := q.db.QueryContext(ctx, getResultsForEventType, eventTypeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Result
+	for rows.Next() {
+		var i Result
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.MeetID,
+			&i.EventTypeID,
+			&i.Time,
+			&i.Place,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getResultsForMeet = `-- name: GetResultsForMeet :many
+SELECT id, athlete_id, meet_id, event_type_id, time, place, created_at
+FROM results
+WHERE meet_id = ?
+ORDER BY id
+`
+
+func (q *Queries) GetResultsForMeet(ctx context.Context, meetID int32) ([]Result, error) {
+	rows, err := q.db.QueryContext(ctx, getResultsForMeet, meetID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Meet
+	var items []Result
 	for rows.Next() {
-		var i Meet
+		var i Result
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Date,
+			&i.AthleteID,
+			&i.MeetID,
+			&i.EventTypeID,
 			&i.Time,
-			&i.Location,
-			&i.Description,
+			&i.Place,
 			&i.CreatedAt,
-			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -252,7 +2079,7 @@ func (q *Queries) GetAllMeets(ctx context.Context) ([]Meet, error) {
 	return items, nil
 }
 
-const getAllResults = `-- name: GetAllResults :many
+const getResultsThrough = `-- name: GetResultsThrough :many
 SELECT
     r.id,
     r.athlete_id,
@@ -260,8 +2087,8 @@ SELECT
     r.event_type_id,
     r.time,
     r.place,
-    r.created_at,
     a.name as athlete_name,
+    a.division as athlete_division,
     m.name as meet_name,
     m.date as meet_date,
     et.name as event_name
@@ -269,32 +2096,33 @@ FROM results r
 JOIN athletes a ON r.athlete_id = a.id
 JOIN meets m ON r.meet_id = m.id
 LEFT JOIN event_types et ON r.event_type_id = et.id
-ORDER BY m.date DESC, r.place
+WHERE m.date <= ?
+ORDER BY m.date, r.meet_id, r.place
 `
 
-type GetAllResultsRow struct {
-	ID          int32
-	AthleteID   int32
-	MeetID      int32
-	EventTypeID sql.NullInt32
-	Time        string
-	Place       sql.NullInt32
-	CreatedAt   sql.NullTime
-	AthleteName string
-	MeetName    string
-	MeetDate    time.Time
-	EventName   sql.NullString
+type GetResultsThroughRow struct {
+	ID              int32
+	AthleteID       int32
+	MeetID          int32
+	EventTypeID     sql.NullInt32
+	Time            string
+	Place           sql.NullInt32
+	AthleteName     string
+	AthleteDivision sql.NullString
+	MeetName        string
+	MeetDate        time.Time
+	EventName       sql.NullString
 }
 
-func (q *Queries) GetAllResults(ctx context.Context) ([]GetAllResultsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getAllResults)
+func (q *Queries) GetResultsThrough(ctx context.Context, date time.Time) ([]GetResultsThroughRow, error) {
+	rows, err := q.db.QueryContext(ctx, getResultsThrough, date)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetAllResultsRow
+	var items []GetResultsThroughRow
 	for rows.Next() {
-		var i GetAllResultsRow
+		var i GetResultsThroughRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.AthleteID,
@@ -302,8 +2130,8 @@ func (q *Queries) GetAllResults(ctx context.Context) ([]GetAllResultsRow, error)
 			&i.EventTypeID,
 			&i.Time,
 			&i.Place,
-			&i.CreatedAt,
 			&i.AthleteName,
+			&i.AthleteDivision,
 			&i.MeetName,
 			&i.MeetDate,
 			&i.EventName,
@@ -321,79 +2149,55 @@ func (q *Queries) GetAllResults(ctx context.Context) ([]GetAllResultsRow, error)
 	return items, nil
 }
 
-const getAthleteByID = `-- name: GetAthleteByID :one
-SELECT id, name, grade, personal_record, events, created_at, updated_at
-FROM athletes
-WHERE id = ?
+const getSessionByTokenHash = `-- name: GetSessionByTokenHash :one
+
+SELECT id, token_hash, user_id, expires_at, last_seen_at, user_agent, ip_address, created_at
+FROM sessions
+WHERE token_hash = ?
 `
 
-func (q *Queries) GetAthleteByID(ctx context.Context, id int32) (Athlete, error) {
-	row := q.db.QueryRowContext(ctx, getAthleteByID, id)
-	var i Athlete
+// =====================
+// SESSIONS
+// =====================
+func (q *Queries) GetSessionByTokenHash(ctx context.Context, tokenHash string) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSessionByTokenHash, tokenHash)
+	var i Session
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Grade,
-		&i.PersonalRecord,
-		&i.Events,
+		&i.TokenHash,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.UserAgent,
+		&i.IpAddress,
 		&i.CreatedAt,
-		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getAthleteResults = `-- name: GetAthleteResults :many
-SELECT
-    r.id,
-    r.athlete_id,
-    r.meet_id,
-    r.event_type_id,
-    r.time,
-    r.place,
-    r.created_at,
-    m.name as meet_name,
-    m.date as meet_date,
-    et.name as event_name
-FROM results r
-JOIN meets m ON r.meet_id = m.id
-LEFT JOIN event_types et ON r.event_type_id = et.id
-WHERE r.athlete_id = ?
-ORDER BY m.date DESC
+const getTeamFinishesByMeet = `-- name: GetTeamFinishesByMeet :many
+SELECT id, meet_id, division, place, score, created_at
+FROM team_finishes
+WHERE meet_id = ?
+ORDER BY division
 `
 
-type GetAthleteResultsRow struct {
-	ID          int32
-	AthleteID   int32
-	MeetID      int32
-	EventTypeID sql.NullInt32
-	Time        string
-	Place       sql.NullInt32
-	CreatedAt   sql.NullTime
-	MeetName    string
-	MeetDate    time.Time
-	EventName   sql.NullString
-}
-
-func (q *Queries) GetAthleteResults(ctx context.Context, athleteID int32) ([]GetAthleteResultsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getAthleteResults, athleteID)
+func (q *Queries) GetTeamFinishesByMeet(ctx context.Context, meetID int32) ([]TeamFinish, error) {
+	rows, err := q.db.QueryContext(ctx, getTeamFinishesByMeet, meetID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetAthleteResultsRow
+	var items []TeamFinish
 	for rows.Next() {
-		var i GetAthleteResultsRow
+		var i TeamFinish
 		if err := rows.Scan(
 			&i.ID,
-			&i.AthleteID,
 			&i.MeetID,
-			&i.EventTypeID,
-			&i.Time,
+			&i.Division,
 			&i.Place,
+			&i.Score,
 			&i.CreatedAt,
-			&i.MeetName,
-			&i.MeetDate,
-			&i.EventName,
 		); err != nil {
 			return nil, err
 		}
@@ -408,19 +2212,22 @@ func (q *Queries) GetAthleteResults(ctx context.Context, athleteID int32) ([]Get
 	return items, nil
 }
 
-const getEventTypeByID = `-- name: GetEventTypeByID :one
-SELECT id, name, distance, description, created_at, updated_at
-FROM event_types
+const getTimeStandardByID = `-- name: GetTimeStandardByID :one
+SELECT id, name, event_type_id, division, time, category, description, created_at, updated_at
+FROM time_standards
 WHERE id = ?
 `
 
-func (q *Queries) GetEventTypeByID(ctx context.Context, id int32) (EventType, error) {
-	row := q.db.QueryRowContext(ctx, getEventTypeByID, id)
-	var i EventType
+func (q *Queries) GetTimeStandardByID(ctx context.Context, id int32) (TimeStandard, error) {
+	row := q.db.QueryRowContext(ctx, getTimeStandardByID, id)
+	var i TimeStandard
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.Distance,
+		&i.EventTypeID,
+		&i.Division,
+		&i.Time,
+		&i.Category,
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -428,81 +2235,105 @@ func (q *Queries) GetEventTypeByID(ctx context.Context, id int32) (EventType, er
 	return i, err
 }
 
-const getMeetByID = `-- name: GetMeetByID :one
-SELECT id, name, date, time, location, description, created_at, updated_at
-FROM meets
-WHERE id = ?
+const getUserAPITokens = `-- name: GetUserAPITokens :many
+SELECT id, user_id, name, token_hash, token_prefix, scopes, meet_id, expires_at, last_used_at, last_used_ip, revoked_at, created_by, created_at
+FROM api_tokens
+WHERE user_id = ?
+ORDER BY id DESC
 `
 
-func (q *Queries) GetMeetByID(ctx context.Context, id int32) (Meet, error) {
-	row := q.db.QueryRowContext(ctx, getMeetByID, id)
-	var i Meet
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Date,
-		&i.Time,
-		&i.Location,
-		&i.Description,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+func (q *Queries) GetUserAPITokens(ctx context.Context, userID int32) ([]ApiToken, error) {
+	rows, err := q.db.QueryContext(ctx, getUserAPITokens, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiToken
+	for rows.Next() {
+		var i ApiToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.TokenHash,
+			&i.TokenPrefix,
+			&i.Scopes,
+			&i.MeetID,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.LastUsedIp,
+			&i.RevokedAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getMeetResults = `-- name: GetMeetResults :many
-
-SELECT
-    r.id,
-    r.athlete_id,
-    r.meet_id,
-    r.event_type_id,
-    r.time,
-    r.place,
-    r.created_at,
-    a.name as athlete_name,
-    et.name as event_name
-FROM results r
-JOIN athletes a ON r.athlete_id = a.id
-LEFT JOIN event_types et ON r.event_type_id = et.id
-WHERE r.meet_id = ?
-ORDER BY r.place
+const getUserAthleteIDs = `-- name: GetUserAthleteIDs :many
+SELECT athlete_id FROM user_athletes WHERE user_id = ? ORDER BY athlete_id
 `
 
-type GetMeetResultsRow struct {
-	ID          int32
-	AthleteID   int32
-	MeetID      int32
-	EventTypeID sql.NullInt32
-	Time        string
-	Place       sql.NullInt32
-	CreatedAt   sql.NullTime
-	AthleteName string
-	EventName   sql.NullString
+func (q *Queries) GetUserAthleteIDs(ctx context.Context, userID int32) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getUserAthleteIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var athlete_id int32
+		if err := rows.Scan(&athlete_id); err != nil {
+			return nil, err
+		}
+		items = append(items, athlete_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-// =====================
-// RESULTS
-// =====================
-func (q *Queries) GetMeetResults(ctx context.Context, meetID int32) ([]GetMeetResultsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getMeetResults, meetID)
+const getUserAthletes = `-- name: GetUserAthletes :many
+SELECT a.id, a.name, a.grade, a.division, a.personal_record, a.events, a.name_visibility, a.parental_consent, a.created_at, a.updated_at
+FROM athletes a
+JOIN user_athletes ua ON ua.athlete_id = a.id
+WHERE ua.user_id = ?
+ORDER BY a.name
+`
+
+func (q *Queries) GetUserAthletes(ctx context.Context, userID int32) ([]Athlete, error) {
+	rows, err := q.db.QueryContext(ctx, getUserAthletes, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetMeetResultsRow
+	var items []Athlete
 	for rows.Next() {
-		var i GetMeetResultsRow
+		var i Athlete
 		if err := rows.Scan(
 			&i.ID,
-			&i.AthleteID,
-			&i.MeetID,
-			&i.EventTypeID,
-			&i.Time,
-			&i.Place,
+			&i.Name,
+			&i.Grade,
+			&i.Division,
+			&i.PersonalRecord,
+			&i.Events,
+			&i.NameVisibility,
+			&i.ParentalConsent,
 			&i.CreatedAt,
-			&i.AthleteName,
-			&i.EventName,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -517,109 +2348,174 @@ func (q *Queries) GetMeetResults(ctx context.Context, meetID int32) ([]GetMeetRe
 	return items, nil
 }
 
-const getResultByID = `-- name: GetResultByID :one
-SELECT
-    r.id,
-    r.athlete_id,
-    r.meet_id,
-    r.event_type_id,
-    r.time,
-    r.place,
-    r.created_at,
-    a.name as athlete_name,
-    m.name as meet_name,
-    et.name as event_name
-FROM results r
-JOIN athletes a ON r.athlete_id = a.id
-JOIN meets m ON r.meet_id = m.id
-LEFT JOIN event_types et ON r.event_type_id = et.id
-WHERE r.id = ?
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, username, password_hash, display_name, email, role, active, password_changed_at, created_at, updated_at
+FROM users
+WHERE id = ?
 `
 
-type GetResultByIDRow struct {
-	ID          int32
-	AthleteID   int32
-	MeetID      int32
-	EventTypeID sql.NullInt32
-	Time        string
-	Place       sql.NullInt32
-	CreatedAt   sql.NullTime
-	AthleteName string
-	MeetName    string
-	EventName   sql.NullString
+func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.DisplayName,
+		&i.Email,
+		&i.Role,
+		&i.Active,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
-func (q *Queries) GetResultByID(ctx context.Context, id int32) (GetResultByIDRow, error) {
-	row := q.db.QueryRowContext(ctx, getResultByID, id)
-	var i GetResultByIDRow
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, username, password_hash, display_name, email, role, active, password_changed_at, created_at, updated_at
+FROM users
+WHERE username = ?
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByUsername, username)
+	var i User
 	err := row.Scan(
 		&i.ID,
-		&i.AthleteID,
-		&i.MeetID,
-		&i.EventTypeID,
-		&i.Time,
-		&i.Place,
+		&i.Username,
+		&i.PasswordHash,
+		&i.DisplayName,
+		&i.Email,
+		&i.Role,
+		&i.Active,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserTOTP = `-- name: GetUserTOTP :one
+
+SELECT user_id, secret, confirmed_at, last_used_step, created_at
+FROM user_totp
+WHERE user_id = ?
+`
+
+// =====================
+// TWO-FACTOR AUTHENTICATION
+// =====================
+func (q *Queries) GetUserTOTP(ctx context.Context, userID int32) (UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, getUserTOTP, userID)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
 		&i.CreatedAt,
-		&i.AthleteName,
-		&i.MeetName,
-		&i.EventName,
 	)
 	return i, err
 }
 
-const getTopTenFastestTimes = `-- name: GetTopTenFastestTimes :many
-SELECT
-    r.id,
-    r.time,
-    r.place,
-    a.id as athlete_id,
-    a.name as athlete_name,
-    a.grade as athlete_grade,
-    m.id as meet_id,
-    m.name as meet_name,
-    m.date as meet_date,
-    et.name as event_name
-FROM results r
-JOIN athletes a ON r.athlete_id = a.id
-JOIN meets m ON r.meet_id = m.id
-LEFT JOIN event_types et ON r.event_type_id = et.id
-ORDER BY r.time ASC
-LIMIT 10
+const getUsersByEmail = `-- name: GetUsersByEmail :many
+SELECT id, username, password_hash, display_name, email, role, active, password_changed_at, created_at, updated_at
+FROM users
+WHERE email = ?
+ORDER BY username
 `
 
-type GetTopTenFastestTimesRow struct {
-	ID           int32
-	Time         string
-	Place        sql.NullInt32
-	AthleteID    int32
-	AthleteName  string
-	AthleteGrade int32
-	MeetID       int32
-	MeetName     string
-	MeetDate     time.Time
-	EventName    sql.NullString
+func (q *Queries) GetUsersByEmail(ctx context.Context, email sql.NullString) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.PasswordHash,
+			&i.DisplayName,
+			&i.Email,
+			&i.Role,
+			&i.Active,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEntries = `-- name: ListAuditEntries :many
+SELECT id, user_id, username, action, entity_type, entity_id, before_data, after_data, created_at
+FROM audit_log
+WHERE (? = '' OR entity_type = ?)
+  AND (? = 0 OR entity_id = ?)
+  AND (? = 0 OR user_id = ?)
+  AND (? = '' OR action = ?)
+  AND COALESCE(created_at >= ?, TRUE)
+  AND COALESCE(created_at < ?, TRUE)
+ORDER BY id DESC
+LIMIT ? OFFSET ?
+`
+
+type ListAuditEntriesParams struct {
+	EntityType string
+	EntityID   int32
+	UserID     int32
+	Action     string
+	Since      sql.NullTime
+	Until      sql.NullTime
+	Limit      int32
+	Offset     int32
 }
 
-func (q *Queries) GetTopTenFastestTimes(ctx context.Context) ([]GetTopTenFastestTimesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getTopTenFastestTimes)
+func (q *Queries) ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEntries,
+		arg.EntityType,
+		arg.EntityType,
+		arg.EntityID,
+		arg.EntityID,
+		arg.UserID,
+		arg.UserID,
+		arg.Action,
+		arg.Action,
+		arg.Since,
+		arg.Until,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetTopTenFastestTimesRow
+	var items []AuditLog
 	for rows.Next() {
-		var i GetTopTenFastestTimesRow
+		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.Time,
-			&i.Place,
-			&i.AthleteID,
-			&i.AthleteName,
-			&i.AthleteGrade,
-			&i.MeetID,
-			&i.MeetName,
-			&i.MeetDate,
-			&i.EventName,
+			&i.UserID,
+			&i.Username,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.BeforeData,
+			&i.AfterData,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -634,15 +2530,123 @@ func (q *Queries) GetTopTenFastestTimes(ctx context.Context) ([]GetTopTenFastest
 	return items, nil
 }
 
+const markMeetOfficial = `-- name: MarkMeetOfficial :exec
+UPDATE meets
+SET official = TRUE, finalized_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+func (q *Queries) MarkMeetOfficial(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, markMeetOfficial, id)
+	return err
+}
+
+const rescheduleMeet = `-- name: RescheduleMeet :exec
+UPDATE meets
+SET date = ?, original_date = COALESCE(original_date, ?), status = ?
+WHERE id = ?
+`
+
+type RescheduleMeetParams struct {
+	NewDate      time.Time
+	PreviousDate sql.NullTime
+	Status       string
+	ID           int32
+}
+
+func (q *Queries) RescheduleMeet(ctx context.Context, arg RescheduleMeetParams) error {
+	_, err := q.db.ExecContext(ctx, rescheduleMeet,
+		arg.NewDate,
+		arg.PreviousDate,
+		arg.Status,
+		arg.ID,
+	)
+	return err
+}
+
+const revokeAPIToken = `-- name: RevokeAPIToken :exec
+UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAPIToken(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, revokeAPIToken, id)
+	return err
+}
+
+const revokeUserAPITokens = `-- name: RevokeUserAPITokens :exec
+UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeUserAPITokens(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, revokeUserAPITokens, userID)
+	return err
+}
+
+const setMeetChampionship = `-- name: SetMeetChampionship :exec
+UPDATE meets
+SET championship = ?
+WHERE id = ?
+`
+
+type SetMeetChampionshipParams struct {
+	Championship bool
+	ID           int32
+}
+
+func (q *Queries) SetMeetChampionship(ctx context.Context, arg SetMeetChampionshipParams) error {
+	_, err := q.db.ExecContext(ctx, setMeetChampionship,
+		arg.Championship,
+		arg.ID,
+	)
+	return err
+}
+
+const touchAPIToken = `-- name: TouchAPIToken :exec
+UPDATE api_tokens SET last_used_at = ?, last_used_ip = ? WHERE id = ?
+`
+
+type TouchAPITokenParams struct {
+	LastUsedAt sql.NullTime
+	LastUsedIp sql.NullString
+	ID         int32
+}
+
+func (q *Queries) TouchAPIToken(ctx context.Context, arg TouchAPITokenParams) error {
+	_, err := q.db.ExecContext(ctx, touchAPIToken,
+		arg.LastUsedAt,
+		arg.LastUsedIp,
+		arg.ID,
+	)
+	return err
+}
+
+const touchSession = `-- name: TouchSession :exec
+UPDATE sessions SET last_seen_at = ? WHERE id = ?
+`
+
+type TouchSessionParams struct {
+	LastSeenAt sql.NullTime
+	ID         int32
+}
+
+func (q *Queries) TouchSession(ctx context.Context, arg TouchSessionParams) error {
+	_, err := q.db.ExecContext(ctx, touchSession,
+		arg.LastSeenAt,
+		arg.ID,
+	)
+	return err
+}
+
 const updateAthlete = `-- name: UpdateAthlete :exec
 UPDATE athletes
-SET name = ?, grade = ?, personal_record = ?, events = ?
+SET name = ?, grade = ?, division = ?, personal_record = ?, events = ?
 WHERE id = ?
 `
 
 type UpdateAthleteParams struct {
 	Name           string
 	Grade          int32
+	Division       sql.NullString
 	PersonalRecord sql.NullString
 	Events         sql.NullString
 	ID             int32
@@ -652,6 +2656,7 @@ func (q *Queries) UpdateAthlete(ctx context.Context, arg UpdateAthleteParams) er
 	_, err := q.db.ExecContext(ctx, updateAthlete,
 		arg.Name,
 		arg.Grade,
+		arg.Division,
 		arg.PersonalRecord,
 		arg.Events,
 		arg.ID,
@@ -659,6 +2664,98 @@ func (q *Queries) UpdateAthlete(ctx context.Context, arg UpdateAthleteParams) er
 	return err
 }
 
+const updateAthletePrivacy = `-- name: UpdateAthletePrivacy :exec
+UPDATE athletes
+SET name_visibility = ?, parental_consent = ?
+WHERE id = ?
+`
+
+type UpdateAthletePrivacyParams struct {
+	NameVisibility  string
+	ParentalConsent bool
+	ID              int32
+}
+
+func (q *Queries) UpdateAthletePrivacy(ctx context.Context, arg UpdateAthletePrivacyParams) error {
+	_, err := q.db.ExecContext(ctx, updateAthletePrivacy,
+		arg.NameVisibility,
+		arg.ParentalConsent,
+		arg.ID,
+	)
+	return err
+}
+
+const updateAwardRule = `-- name: UpdateAwardRule :exec
+UPDATE award_rules
+SET name = ?, award_type = ?, criterion = ?, threshold = ?, winners = ?, division = ?, description = ?
+WHERE id = ?
+`
+
+type UpdateAwardRuleParams struct {
+	Name        string
+	AwardType   string
+	Criterion   sql.NullString
+	Threshold   int32
+	Winners     int32
+	Division    sql.NullString
+	Description sql.NullString
+	ID          int32
+}
+
+func (q *Queries) UpdateAwardRule(ctx context.Context, arg UpdateAwardRuleParams) error {
+	_, err := q.db.ExecContext(ctx, updateAwardRule,
+		arg.Name,
+		arg.AwardType,
+		arg.Criterion,
+		arg.Threshold,
+		arg.Winners,
+		arg.Division,
+		arg.Description,
+		arg.ID,
+	)
+	return err
+}
+
+const updateCourse = `-- name: UpdateCourse :exec
+UPDATE courses
+SET name = ?, location = ?, description = ?
+WHERE id = ?
+`
+
+type UpdateCourseParams struct {
+	Name        string
+	Location    sql.NullString
+	Description sql.NullString
+	ID          int32
+}
+
+func (q *Queries) UpdateCourse(ctx context.Context, arg UpdateCourseParams) error {
+	_, err := q.db.ExecContext(ctx, updateCourse,
+		arg.Name,
+		arg.Location,
+		arg.Description,
+		arg.ID,
+	)
+	return err
+}
+
+const updateCourseFactor = `-- name: UpdateCourseFactor :exec
+UPDATE courses
+SET correction_factor = ?, sample_size = ?, factor_updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateCourseFactorParams struct {
+	CorrectionFactor float64
+	SampleSize       int32
+	ID               int32
+}
+
+func (q *Queries) UpdateCourseFactor(ctx context.Context, arg UpdateCourseFactorParams) error {
+	_, err := q.db.ExecContext(ctx, updateCourseFactor, arg.CorrectionFactor, arg.SampleSize, arg.ID)
+	return err
+}
+
 const updateEventType = `-- name: UpdateEventType :exec
 UPDATE event_types
 SET name = ?, distance = ?, description = ?
@@ -684,7 +2781,7 @@ func (q *Queries) UpdateEventType(ctx context.Context, arg UpdateEventTypeParams
 
 const updateMeet = `-- name: UpdateMeet :exec
 UPDATE meets
-SET name = ?, date = ?, time = ?, location = ?, description = ?
+SET name = ?, date = ?, time = ?, location = ?, description = ?, course_id = ?
 WHERE id = ?
 `
 
@@ -694,6 +2791,7 @@ type UpdateMeetParams struct {
 	Time        sql.NullString
 	Location    sql.NullString
 	Description sql.NullString
+	CourseID    sql.NullInt32
 	ID          int32
 }
 
@@ -704,11 +2802,55 @@ func (q *Queries) UpdateMeet(ctx context.Context, arg UpdateMeetParams) error {
 		arg.Time,
 		arg.Location,
 		arg.Description,
+		arg.CourseID,
+		arg.ID,
+	)
+	return err
+}
+
+const updateMeetConditions = `-- name: UpdateMeetConditions :exec
+UPDATE meets
+SET temperature_f = ?, humidity_pct = ?, wind_mph = ?, precipitation = ?, footing = ?
+WHERE id = ?
+`
+
+type UpdateMeetConditionsParams struct {
+	TemperatureF  sql.NullInt32
+	HumidityPct   sql.NullInt32
+	WindMph       sql.NullInt32
+	Precipitation sql.NullString
+	Footing       sql.NullString
+	ID            int32
+}
+
+func (q *Queries) UpdateMeetConditions(ctx context.Context, arg UpdateMeetConditionsParams) error {
+	_, err := q.db.ExecContext(ctx, updateMeetConditions,
+		arg.TemperatureF,
+		arg.HumidityPct,
+		arg.WindMph,
+		arg.Precipitation,
+		arg.Footing,
 		arg.ID,
 	)
 	return err
 }
 
+const updateMeetStatus = `-- name: UpdateMeetStatus :exec
+UPDATE meets
+SET status = ?
+WHERE id = ?
+`
+
+type UpdateMeetStatusParams struct {
+	Status string
+	ID     int32
+}
+
+func (q *Queries) UpdateMeetStatus(ctx context.Context, arg UpdateMeetStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateMeetStatus, arg.Status, arg.ID)
+	return err
+}
+
 const updateResult = `-- name: UpdateResult :exec
 UPDATE results
 SET athlete_id = ?, meet_id = ?, event_type_id = ?, time = ?, place = ?
@@ -735,3 +2877,120 @@ func (q *Queries) UpdateResult(ctx context.Context, arg UpdateResultParams) erro
 	)
 	return err
 }
+
+const updateTOTPStep = `-- name: UpdateTOTPStep :execrows
+UPDATE user_totp SET last_used_step = ?
+WHERE user_id = ? AND last_used_step < ?
+`
+
+type UpdateTOTPStepParams struct {
+	LastUsedStep int64
+	UserID       int32
+}
+
+func (q *Queries) UpdateTOTPStep(ctx context.Context, arg UpdateTOTPStepParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateTOTPStep,
+		arg.LastUsedStep,
+		arg.UserID,
+		arg.LastUsedStep,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateTimeStandard = `-- name: UpdateTimeStandard :exec
+UPDATE time_standards
+SET name = ?, event_type_id = ?, division = ?, time = ?, category = ?, description = ?
+WHERE id = ?
+`
+
+type UpdateTimeStandardParams struct {
+	Name        string
+	EventTypeID int32
+	Division    sql.NullString
+	Time        string
+	Category    string
+	Description sql.NullString
+	ID          int32
+}
+
+func (q *Queries) UpdateTimeStandard(ctx context.Context, arg UpdateTimeStandardParams) error {
+	_, err := q.db.ExecContext(ctx, updateTimeStandard,
+		arg.Name,
+		arg.EventTypeID,
+		arg.Division,
+		arg.Time,
+		arg.Category,
+		arg.Description,
+		arg.ID,
+	)
+	return err
+}
+
+const updateUser = `-- name: UpdateUser :exec
+UPDATE users
+SET username = ?, display_name = ?, email = ?, role = ?, active = ?
+WHERE id = ?
+`
+
+type UpdateUserParams struct {
+	Username    string
+	DisplayName sql.NullString
+	Email       sql.NullString
+	Role        string
+	Active      bool
+	ID          int32
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
+	_, err := q.db.ExecContext(ctx, updateUser,
+		arg.Username,
+		arg.DisplayName,
+		arg.Email,
+		arg.Role,
+		arg.Active,
+		arg.ID,
+	)
+	return err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users
+SET password_hash = ?, password_changed_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateUserPasswordParams struct {
+	PasswordHash string
+	ID           int32
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPassword,
+		arg.PasswordHash,
+		arg.ID,
+	)
+	return err
+}
+
+const useAccountToken = `-- name: UseAccountToken :execresult
+UPDATE account_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = ? AND used_at IS NULL
+`
+
+func (q *Queries) UseAccountToken(ctx context.Context, id int32) (sql.Result, error) {
+	return q.db.ExecContext(ctx, useAccountToken, id)
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes SET used_at = CURRENT_TIMESTAMP WHERE id = ? AND used_at IS NULL
+`
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useRecoveryCode, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
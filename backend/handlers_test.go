@@ -0,0 +1,641 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// handlerCase is one request against a fresh testServer.
+type handlerCase struct {
+	// route is the endpoint as declared in routes(), e.g.
+	// "GET /api/athletes/:id".
+	route string
+	name  string
+	// path defaults to the route's path.
+	path string
+	role string
+	// body is sent as JSON; a func(*testServer) any is called first, for
+	// bodies that depend on setup.
+	body  any
+	setup func(ts *testServer)
+	want  int
+	check func(t *testing.T, ts *testServer, rec *httptest.ResponseRecorder)
+}
+
+// Fixture user IDs, in the order testUsers creates them.
+const (
+	coachID int32 = iota + 1
+	assistantID
+	runnerID
+	parentID
+)
+
+// nextResultID is the ID the first result created in a test gets, after
+// the 32 fixture results.
+const nextResultID = "33"
+
+// startMeet moves the scheduled region meet (meet 5) in progress so results
+// can be entered for it.
+func startMeet(ts *testServer) {
+	ts.t.Helper()
+	rec := ts.do(http.MethodPut, "/api/meets/5/status", roleHeadCoach, obj{"status": meetInProgress})
+	if rec.Code != http.StatusOK {
+		ts.t.Fatalf("starting meet: %d %s", rec.Code, rec.Body)
+	}
+}
+
+// addResult starts meet 5 and enters a result for Jaylen Carter in it; the
+// result gets nextResultID.
+func addResult(ts *testServer) {
+	ts.t.Helper()
+	startMeet(ts)
+	rec := ts.do(http.MethodPost, "/api/results", roleHeadCoach, newResult)
+	if rec.Code != http.StatusCreated {
+		ts.t.Fatalf("adding result: %d %s", rec.Code, rec.Body)
+	}
+}
+
+// obj is a JSON object body.
+type obj = map[string]any
+
+var newResult = obj{"athleteId": 1, "meetId": 5, "eventTypeId": 1, "time": "16:10", "place": 1}
+
+// wantCount checks the number of rows in table matching where.
+func wantCount(table, where string, want int, args ...any) func(*testing.T, *testServer, *httptest.ResponseRecorder) {
+	return func(t *testing.T, ts *testServer, _ *httptest.ResponseRecorder) {
+		t.Helper()
+		if got := ts.count(table, where, args...); got != want {
+			t.Errorf("%s where %q: %d rows, want %d", table, where, got, want)
+		}
+	}
+}
+
+// wantError checks that the error message contains substr.
+func wantError(substr string) func(*testing.T, *testServer, *httptest.ResponseRecorder) {
+	return func(t *testing.T, _ *testServer, rec *httptest.ResponseRecorder) {
+		t.Helper()
+		var body struct{ Error string }
+		decode(t, rec, &body)
+		if !strings.Contains(body.Error, substr) {
+			t.Errorf("error %q does not mention %q", body.Error, substr)
+		}
+	}
+}
+
+// wantLength checks the length of a JSON array response.
+func wantLength(want int) func(*testing.T, *testServer, *httptest.ResponseRecorder) {
+	return func(t *testing.T, _ *testServer, rec *httptest.ResponseRecorder) {
+		t.Helper()
+		var body []any
+		decode(t, rec, &body)
+		if len(body) != want {
+			t.Errorf("got %d items, want %d", len(body), want)
+		}
+	}
+}
+
+var handlerCases = []handlerCase{
+	// Probes
+	{route: "GET /health", role: "", want: http.StatusOK},
+	{route: "GET /livez", want: http.StatusOK},
+	{route: "GET /readyz", want: http.StatusOK},
+
+	// Event types
+	{route: "GET /api/event-types", want: http.StatusOK, check: wantLength(5)},
+	{route: "GET /api/event-types/:id", path: "/api/event-types/1", want: http.StatusOK},
+	{route: "GET /api/event-types/:id", name: "invalid id", path: "/api/event-types/abc", want: http.StatusBadRequest},
+	{route: "GET /api/event-types/:id", name: "not found", path: "/api/event-types/99", want: http.StatusNotFound},
+	{route: "POST /api/event-types", role: roleHeadCoach, body: obj{"name": "6K", "distance": "6000m"},
+		want: http.StatusCreated, check: wantCount("event_types", "name = '6K'", 1)},
+	{route: "POST /api/event-types", name: "missing body", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/event-types", name: "duplicate", role: roleHeadCoach, body: obj{"name": "5K"},
+		want: http.StatusConflict, check: wantError("already exists")},
+	{route: "PUT /api/event-types/:id", path: "/api/event-types/2", role: roleHeadCoach, body: obj{"name": "2 Mile"},
+		want: http.StatusOK, check: wantCount("event_types", "name = '2 Mile'", 1)},
+	{route: "PUT /api/event-types/:id", name: "invalid id", path: "/api/event-types/abc", role: roleHeadCoach, body: obj{"name": "2 Mile"}, want: http.StatusBadRequest},
+	{route: "PUT /api/event-types/:id", name: "missing body", path: "/api/event-types/2", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/event-types/:id", name: "not found", path: "/api/event-types/99", role: roleHeadCoach, body: obj{"name": "2 Mile"}, want: http.StatusNotFound},
+	{route: "PUT /api/event-types/:id", name: "duplicate", path: "/api/event-types/2", role: roleHeadCoach, body: obj{"name": "5K"}, want: http.StatusConflict},
+	{route: "DELETE /api/event-types/:id", path: "/api/event-types/5", role: roleHeadCoach,
+		want: http.StatusOK, check: wantCount("event_types", "", 4)},
+	{route: "DELETE /api/event-types/:id", name: "cascades to standards", path: "/api/event-types/1", role: roleHeadCoach,
+		want: http.StatusOK, check: wantCount("time_standards", "", 0)},
+	{route: "DELETE /api/event-types/:id", name: "audits results", path: "/api/event-types/1", role: roleHeadCoach,
+		want: http.StatusOK, check: wantCount("audit_log", "entity_type = 'result' AND action = 'update' AND after_data LIKE '%\"eventTypeId\":0%'", 32)},
+	{route: "DELETE /api/event-types/:id", name: "invalid id", path: "/api/event-types/abc", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "DELETE /api/event-types/:id", name: "not found", path: "/api/event-types/99", role: roleHeadCoach, want: http.StatusNotFound},
+
+	// Athletes
+	{route: "GET /api/athletes", want: http.StatusOK, check: wantLength(14)},
+	{route: "GET /api/athletes/:id", path: "/api/athletes/1", want: http.StatusOK},
+	{route: "GET /api/athletes/:id", name: "invalid id", path: "/api/athletes/abc", want: http.StatusBadRequest},
+	{route: "GET /api/athletes/:id", name: "not found", path: "/api/athletes/99", want: http.StatusNotFound},
+	{route: "GET /api/athletes/:id/results", path: "/api/athletes/1/results", want: http.StatusOK, check: wantLength(3)},
+	{route: "GET /api/athletes/:id/results", name: "invalid id", path: "/api/athletes/abc/results", want: http.StatusBadRequest},
+	{route: "GET /api/athletes/:id/results", name: "not found", path: "/api/athletes/99/results", want: http.StatusNotFound},
+	{route: "GET /api/athletes/:id/progression", path: "/api/athletes/1/progression", want: http.StatusOK},
+	{route: "GET /api/athletes/:id/progression", name: "invalid id", path: "/api/athletes/abc/progression", want: http.StatusBadRequest},
+	{route: "GET /api/athletes/:id/progression", name: "not found", path: "/api/athletes/99/progression", want: http.StatusNotFound},
+	{route: "GET /api/athletes/:id/standards", path: "/api/athletes/1/standards", want: http.StatusOK},
+	{route: "GET /api/athletes/:id/standards", name: "invalid id", path: "/api/athletes/abc/standards", want: http.StatusBadRequest},
+	{route: "GET /api/athletes/:id/standards", name: "not found", path: "/api/athletes/99/standards", want: http.StatusNotFound},
+	{route: "POST /api/athletes", role: roleHeadCoach, body: obj{"name": "New Runner", "grade": 9, "division": "girls"},
+		want: http.StatusCreated, check: wantCount("athletes", "", 15)},
+	{route: "POST /api/athletes", name: "missing body", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/athletes", name: "missing grade", role: roleHeadCoach, body: obj{"name": "New Runner"}, want: http.StatusBadRequest},
+	{route: "PUT /api/athletes/:id", path: "/api/athletes/1", role: roleHeadCoach, body: obj{"name": "Jaylen Carter", "grade": 12, "division": "boys", "personalRecord": "16:10"},
+		want: http.StatusOK, check: wantCount("athletes", "id = 1 AND personal_record = '16:10'", 1)},
+	{route: "PUT /api/athletes/:id", name: "invalid id", path: "/api/athletes/abc", role: roleHeadCoach, body: obj{"name": "X", "grade": 9}, want: http.StatusBadRequest},
+	{route: "PUT /api/athletes/:id", name: "missing body", path: "/api/athletes/1", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/athletes/:id", name: "not found", path: "/api/athletes/99", role: roleHeadCoach, body: obj{"name": "X", "grade": 9}, want: http.StatusNotFound},
+	{route: "PUT /api/athletes/:id/privacy", path: "/api/athletes/1/privacy", role: roleHeadCoach, body: obj{"nameVisibility": "initial", "parentalConsent": true},
+		want: http.StatusOK, check: wantCount("athletes", "id = 1 AND name_visibility = 'initial'", 1)},
+	{route: "PUT /api/athletes/:id/privacy", name: "invalid id", path: "/api/athletes/abc/privacy", role: roleHeadCoach, body: obj{"nameVisibility": "initial", "parentalConsent": true}, want: http.StatusBadRequest},
+	{route: "PUT /api/athletes/:id/privacy", name: "missing body", path: "/api/athletes/1/privacy", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/athletes/:id/privacy", name: "not found", path: "/api/athletes/99/privacy", role: roleHeadCoach, body: obj{"nameVisibility": "initial", "parentalConsent": true}, want: http.StatusNotFound},
+	{route: "DELETE /api/athletes/:id", path: "/api/athletes/1", role: roleHeadCoach, want: http.StatusOK, check: wantCount("athletes", "id = 1", 0)},
+	{route: "DELETE /api/athletes/:id", name: "cascades to results", path: "/api/athletes/1", role: roleHeadCoach, want: http.StatusOK, check: wantCount("results", "athlete_id = 1", 0)},
+	{route: "DELETE /api/athletes/:id", name: "cascades to account links", path: "/api/athletes/1", role: roleHeadCoach, want: http.StatusOK, check: wantCount("user_athletes", "athlete_id = 1", 0)},
+	{route: "DELETE /api/athletes/:id", name: "invalid id", path: "/api/athletes/abc", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "DELETE /api/athletes/:id", name: "not found", path: "/api/athletes/99", role: roleHeadCoach, want: http.StatusNotFound},
+
+	// Courses
+	{route: "GET /api/courses", want: http.StatusOK, check: wantLength(5)},
+	{route: "GET /api/courses/:id", path: "/api/courses/1", want: http.StatusOK},
+	{route: "GET /api/courses/:id", name: "invalid id", path: "/api/courses/abc", want: http.StatusBadRequest},
+	{route: "GET /api/courses/:id", name: "not found", path: "/api/courses/99", want: http.StatusNotFound},
+	{route: "POST /api/courses", role: roleHeadCoach, body: obj{"name": "Wesleyan Woods", "location": "Macon, GA"},
+		want: http.StatusCreated, check: wantCount("courses", "", 6)},
+	{route: "POST /api/courses", name: "missing body", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/courses", name: "duplicate", role: roleHeadCoach, body: obj{"name": "Panther Creek"}, want: http.StatusConflict},
+	{route: "POST /api/courses/recompute", role: roleHeadCoach, want: http.StatusOK},
+	{route: "PUT /api/courses/:id", path: "/api/courses/1", role: roleHeadCoach, body: obj{"name": "Jones County HS", "location": "Gray, GA"},
+		want: http.StatusOK, check: wantCount("courses", "name = 'Jones County HS'", 1)},
+	{route: "PUT /api/courses/:id", name: "invalid id", path: "/api/courses/abc", role: roleHeadCoach, body: obj{"name": "X"}, want: http.StatusBadRequest},
+	{route: "PUT /api/courses/:id", name: "missing body", path: "/api/courses/1", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/courses/:id", name: "not found", path: "/api/courses/99", role: roleHeadCoach, body: obj{"name": "X"}, want: http.StatusNotFound},
+	{route: "PUT /api/courses/:id", name: "duplicate", path: "/api/courses/1", role: roleHeadCoach, body: obj{"name": "Panther Creek"}, want: http.StatusConflict},
+	{route: "DELETE /api/courses/:id", path: "/api/courses/5", role: roleHeadCoach, want: http.StatusOK, check: wantCount("courses", "", 4)},
+	{route: "DELETE /api/courses/:id", name: "leaves meets", path: "/api/courses/5", role: roleHeadCoach, want: http.StatusOK, check: wantCount("meets", "id = 5 AND course_id IS NULL", 1)},
+	{route: "DELETE /api/courses/:id", name: "invalid id", path: "/api/courses/abc", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "DELETE /api/courses/:id", name: "not found", path: "/api/courses/99", role: roleHeadCoach, want: http.StatusNotFound},
+
+	// Meets
+	{route: "GET /api/meets", want: http.StatusOK, check: wantLength(6)},
+	{route: "GET /api/meets/:id", path: "/api/meets/1", want: http.StatusOK},
+	{route: "GET /api/meets/:id", name: "invalid id", path: "/api/meets/abc", want: http.StatusBadRequest},
+	{route: "GET /api/meets/:id", name: "not found", path: "/api/meets/99", want: http.StatusNotFound},
+	{route: "GET /api/meets/:id/results", path: "/api/meets/1/results", want: http.StatusOK, check: wantLength(14)},
+	{route: "GET /api/meets/:id/results", name: "invalid id", path: "/api/meets/abc/results", want: http.StatusBadRequest},
+	{route: "GET /api/meets/:id/conditions", path: "/api/meets/5/conditions", want: http.StatusOK},
+	{route: "GET /api/meets/:id/conditions", name: "invalid id", path: "/api/meets/abc/conditions", want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id/races/:eventTypeId/conditions", path: "/api/meets/5/races/1/conditions", role: roleHeadCoach,
+		body: obj{"temperatureF": 58, "footing": "firm"}, want: http.StatusOK, check: wantCount("race_conditions", "meet_id = 5", 1)},
+	{route: "PUT /api/meets/:id/races/:eventTypeId/conditions", name: "invalid id", path: "/api/meets/5/races/abc/conditions", role: roleHeadCoach, body: obj{}, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id/races/:eventTypeId/conditions", name: "invalid footing", path: "/api/meets/5/races/1/conditions", role: roleHeadCoach, body: obj{"footing": "icy"}, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id/races/:eventTypeId/conditions", name: "not found", path: "/api/meets/99/races/1/conditions", role: roleHeadCoach, body: obj{}, want: http.StatusNotFound},
+	{route: "PUT /api/meets/:id/races/:eventTypeId/conditions", name: "audited", path: "/api/meets/5/races/1/conditions", role: roleHeadCoach,
+		setup: func(ts *testServer) {
+			ts.do(http.MethodPut, "/api/meets/5/races/1/conditions", roleHeadCoach, obj{"footing": "muddy"})
+		},
+		body: obj{"footing": "firm"}, want: http.StatusOK,
+		check: wantCount("audit_log", "entity_type = 'race_conditions' AND entity_id = 5 AND action = 'update' AND before_data LIKE '%muddy%'", 1)},
+	{route: "PUT /api/meets/:id/races/:eventTypeId/conditions", name: "official meet", path: "/api/meets/1/races/1/conditions", role: roleHeadCoach,
+		body: obj{"footing": "firm"}, want: http.StatusConflict, check: wantCount("race_conditions", "meet_id = 1", 0)},
+	{route: "DELETE /api/meets/:id/races/:eventTypeId/conditions", path: "/api/meets/5/races/1/conditions", role: roleHeadCoach,
+		setup: func(ts *testServer) {
+			ts.do(http.MethodPut, "/api/meets/5/races/1/conditions", roleHeadCoach, obj{"footing": "muddy"})
+		},
+		want: http.StatusOK, check: wantCount("race_conditions", "", 0)},
+	{route: "DELETE /api/meets/:id/races/:eventTypeId/conditions", name: "audited", path: "/api/meets/5/races/1/conditions", role: roleHeadCoach,
+		setup: func(ts *testServer) {
+			ts.do(http.MethodPut, "/api/meets/5/races/1/conditions", roleHeadCoach, obj{"footing": "muddy"})
+		},
+		want: http.StatusOK, check: wantCount("audit_log", "entity_type = 'race_conditions' AND entity_id = 5 AND action = 'delete'", 1)},
+	{route: "DELETE /api/meets/:id/races/:eventTypeId/conditions", name: "official meet", path: "/api/meets/1/races/1/conditions", role: roleHeadCoach,
+		setup: func(ts *testServer) {
+			if _, err := ts.conn.Exec("INSERT INTO race_conditions (meet_id, event_type_id, footing) VALUES (1, 1, 'firm')"); err != nil {
+				ts.t.Fatal(err)
+			}
+		},
+		want: http.StatusConflict, check: wantCount("race_conditions", "meet_id = 1", 1)},
+	{route: "DELETE /api/meets/:id/races/:eventTypeId/conditions", name: "not found", path: "/api/meets/5/races/1/conditions", role: roleHeadCoach, want: http.StatusNotFound},
+	{route: "DELETE /api/meets/:id/races/:eventTypeId/conditions", name: "invalid id", path: "/api/meets/abc/races/1/conditions", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/meets", role: roleHeadCoach, body: obj{"name": "Dual Meet", "date": "2026-10-10", "courseId": 1},
+		want: http.StatusCreated, check: wantCount("meets", "", 7)},
+	{route: "POST /api/meets", name: "missing body", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/meets", name: "invalid date", role: roleHeadCoach, body: obj{"name": "Dual Meet", "date": "next week"}, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id", path: "/api/meets/5", role: roleHeadCoach, body: obj{"name": "Region Championship", "date": "2026-10-22", "courseId": 5},
+		want: http.StatusOK, check: wantCount("meets", "name = 'Region Championship'", 1)},
+	{route: "PUT /api/meets/:id", name: "invalid id", path: "/api/meets/abc", role: roleHeadCoach, body: obj{"name": "X", "date": "2026-10-22"}, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id", name: "missing body", path: "/api/meets/5", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id", name: "date edited", path: "/api/meets/5", role: roleHeadCoach, body: obj{"name": "Region 4-AAAAA Championship", "date": "2026-10-24"},
+		want: http.StatusOK, check: wantCount("meets", "id = 5 AND original_date IS NOT NULL AND status = 'scheduled'", 1)},
+	{route: "PUT /api/meets/:id", name: "date of finished meet", path: "/api/meets/1", role: roleHeadCoach,
+		body: obj{"name": "Jones County Time Trial", "date": "2026-08-22"}, want: http.StatusConflict,
+		check: wantCount("meets", "id = 1 AND original_date IS NULL", 1)},
+	{route: "PUT /api/meets/:id", name: "not found", path: "/api/meets/99", role: roleHeadCoach, body: obj{"name": "X", "date": "2026-10-22"}, want: http.StatusNotFound},
+	{route: "DELETE /api/meets/:id", path: "/api/meets/1", role: roleHeadCoach, want: http.StatusOK, check: wantCount("results", "meet_id = 1", 0)},
+	{route: "DELETE /api/meets/:id", name: "cascades to qualification", path: "/api/meets/5", role: roleHeadCoach,
+		setup: func(ts *testServer) {
+			ts.do(http.MethodPut, "/api/meets/5/team-finishes/boys", roleHeadCoach, obj{"place": 2})
+			ts.do(http.MethodPut, "/api/meets/5/races/1/conditions", roleHeadCoach, obj{"footing": "soft"})
+		},
+		want: http.StatusOK,
+		check: func(t *testing.T, ts *testServer, rec *httptest.ResponseRecorder) {
+			for _, table := range []string{"qualification_rules", "team_finishes", "race_conditions"} {
+				wantCount(table, "meet_id = 5", 0)(t, ts, rec)
+			}
+		}},
+	{route: "DELETE /api/meets/:id", name: "invalid id", path: "/api/meets/abc", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "DELETE /api/meets/:id", name: "not found", path: "/api/meets/99", role: roleHeadCoach, want: http.StatusNotFound},
+	{route: "PUT /api/meets/:id/status", path: "/api/meets/5/status", role: roleHeadCoach, body: obj{"status": meetInProgress},
+		want: http.StatusOK, check: wantCount("meets", "id = 5 AND status = 'in_progress'", 1)},
+	{route: "PUT /api/meets/:id/status", name: "official", path: "/api/meets/1/status", role: roleHeadCoach, body: obj{"status": meetInProgress}, want: http.StatusConflict},
+	{route: "PUT /api/meets/:id/status", name: "not allowed", path: "/api/meets/5/status", role: roleHeadCoach, body: obj{"status": meetFinal}, want: http.StatusConflict},
+	{route: "PUT /api/meets/:id/status", name: "unknown status", path: "/api/meets/5/status", role: roleHeadCoach, body: obj{"status": "done"}, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id/status", name: "invalid id", path: "/api/meets/abc/status", role: roleHeadCoach, body: obj{"status": meetInProgress}, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id/status", name: "not found", path: "/api/meets/99/status", role: roleHeadCoach, body: obj{"status": meetInProgress}, want: http.StatusNotFound},
+	{route: "POST /api/meets/:id/reschedule", path: "/api/meets/5/reschedule", role: roleHeadCoach, body: obj{"date": "2026-10-29", "reason": "Storms"},
+		want: http.StatusOK, check: wantCount("meet_date_changes", "meet_id = 5", 1)},
+	{route: "POST /api/meets/:id/reschedule", name: "final", path: "/api/meets/1/reschedule", role: roleHeadCoach, body: obj{"date": "2026-10-29"}, want: http.StatusConflict},
+	{route: "POST /api/meets/:id/reschedule", name: "invalid date", path: "/api/meets/5/reschedule", role: roleHeadCoach, body: obj{"date": "soon"}, want: http.StatusBadRequest},
+	{route: "POST /api/meets/:id/reschedule", name: "missing body", path: "/api/meets/5/reschedule", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/meets/:id/reschedule", name: "not found", path: "/api/meets/99/reschedule", role: roleHeadCoach, body: obj{"date": "2026-10-29"}, want: http.StatusNotFound},
+	{route: "POST /api/meets/:id/official", path: "/api/meets/5/official", role: roleHeadCoach,
+		setup: func(ts *testServer) {
+			startMeet(ts)
+			ts.do(http.MethodPut, "/api/meets/5/status", roleHeadCoach, obj{"status": meetFinal})
+		},
+		want: http.StatusOK, check: wantCount("meets", "id = 5 AND official", 1)},
+	{route: "POST /api/meets/:id/official", name: "not final", path: "/api/meets/5/official", role: roleHeadCoach, want: http.StatusConflict},
+	{route: "POST /api/meets/:id/official", name: "invalid id", path: "/api/meets/abc/official", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/meets/:id/official", name: "not found", path: "/api/meets/99/official", role: roleHeadCoach, want: http.StatusNotFound},
+	{route: "GET /api/meets/:id/history", path: "/api/meets/5/history",
+		setup: func(ts *testServer) {
+			ts.do(http.MethodPost, "/api/meets/5/reschedule", roleHeadCoach, obj{"date": "2026-10-29"})
+		},
+		want: http.StatusOK, check: wantLength(1)},
+	{route: "GET /api/meets/:id/history", name: "invalid id", path: "/api/meets/abc/history", want: http.StatusBadRequest},
+
+	// Time standards
+	{route: "GET /api/standards", want: http.StatusOK, check: wantLength(6)},
+	{route: "GET /api/standards/report", want: http.StatusOK},
+	{route: "GET /api/standards/:id", path: "/api/standards/1", want: http.StatusOK},
+	{route: "GET /api/standards/:id", name: "invalid id", path: "/api/standards/abc", want: http.StatusBadRequest},
+	{route: "GET /api/standards/:id", name: "not found", path: "/api/standards/99", want: http.StatusNotFound},
+	{route: "POST /api/standards", role: roleHeadCoach, body: obj{"name": "Sub-18 Club", "eventTypeId": 1, "division": "boys", "time": "17:59", "category": "milestone"},
+		want: http.StatusCreated, check: wantCount("time_standards", "", 7)},
+	{route: "POST /api/standards", name: "missing body", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/standards", name: "unknown event type", role: roleHeadCoach, body: obj{"name": "Sub-18 Club", "eventTypeId": 99, "time": "17:59"},
+		want: http.StatusBadRequest, check: wantError("Event type not found")},
+	{route: "PUT /api/standards/:id", path: "/api/standards/1", role: roleHeadCoach, body: obj{"name": "Sub-17 Club", "eventTypeId": 1, "division": "boys", "time": "16:58"},
+		want: http.StatusOK, check: wantCount("time_standards", "id = 1 AND time = '16:58'", 1)},
+	{route: "PUT /api/standards/:id", name: "invalid id", path: "/api/standards/abc", role: roleHeadCoach, body: obj{"name": "X", "eventTypeId": 1, "time": "17:00"}, want: http.StatusBadRequest},
+	{route: "PUT /api/standards/:id", name: "missing body", path: "/api/standards/1", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/standards/:id", name: "not found", path: "/api/standards/99", role: roleHeadCoach, body: obj{"name": "X", "eventTypeId": 1, "time": "17:00"}, want: http.StatusNotFound},
+	{route: "DELETE /api/standards/:id", path: "/api/standards/1", role: roleHeadCoach, want: http.StatusOK, check: wantCount("time_standards", "", 5)},
+	{route: "DELETE /api/standards/:id", name: "invalid id", path: "/api/standards/abc", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "DELETE /api/standards/:id", name: "not found", path: "/api/standards/99", role: roleHeadCoach, want: http.StatusNotFound},
+
+	// Awards
+	{route: "GET /api/awards/rules", want: http.StatusOK, check: wantLength(6)},
+	{route: "GET /api/awards/rules/:id", path: "/api/awards/rules/1", want: http.StatusOK},
+	{route: "GET /api/awards/rules/:id", name: "invalid id", path: "/api/awards/rules/abc", want: http.StatusBadRequest},
+	{route: "GET /api/awards/rules/:id", name: "not found", path: "/api/awards/rules/99", want: http.StatusNotFound},
+	{route: "POST /api/awards/rules", role: roleHeadCoach, body: obj{"name": "Iron Runner", "awardType": "letter", "criterion": "varsity_races", "threshold": 5},
+		want: http.StatusCreated, check: wantCount("award_rules", "", 7)},
+	{route: "POST /api/awards/rules", name: "missing body", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/awards/rules", name: "unknown type", role: roleHeadCoach, body: obj{"name": "MVP", "awardType": "mvp"}, want: http.StatusBadRequest},
+	{route: "PUT /api/awards/rules/:id", path: "/api/awards/rules/1", role: roleHeadCoach, body: obj{"name": "Varsity Letter", "awardType": "letter", "criterion": "varsity_races", "threshold": 4},
+		want: http.StatusOK, check: wantCount("award_rules", "id = 1 AND threshold = 4", 1)},
+	{route: "PUT /api/awards/rules/:id", name: "invalid id", path: "/api/awards/rules/abc", role: roleHeadCoach, body: obj{"name": "X", "awardType": "letter", "criterion": "varsity_races"}, want: http.StatusBadRequest},
+	{route: "PUT /api/awards/rules/:id", name: "missing body", path: "/api/awards/rules/1", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/awards/rules/:id", name: "not found", path: "/api/awards/rules/99", role: roleHeadCoach, body: obj{"name": "X", "awardType": "letter", "criterion": "varsity_races"}, want: http.StatusNotFound},
+	{route: "DELETE /api/awards/rules/:id", path: "/api/awards/rules/1", role: roleHeadCoach, want: http.StatusOK, check: wantCount("award_rules", "", 5)},
+	{route: "DELETE /api/awards/rules/:id", name: "invalid id", path: "/api/awards/rules/abc", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "DELETE /api/awards/rules/:id", name: "not found", path: "/api/awards/rules/99", role: roleHeadCoach, want: http.StatusNotFound},
+	{route: "GET /api/awards/report", want: http.StatusOK},
+
+	// Postseason qualification
+	{route: "GET /api/meets/:id/qualification", path: "/api/meets/5/qualification", want: http.StatusOK},
+	{route: "GET /api/meets/:id/qualification", name: "invalid id", path: "/api/meets/abc/qualification", want: http.StatusBadRequest},
+	{route: "GET /api/meets/:id/qualification", name: "not found", path: "/api/meets/1/qualification", want: http.StatusNotFound},
+	{route: "PUT /api/meets/:id/qualification", path: "/api/meets/5/qualification", role: roleHeadCoach, body: obj{"targetMeetId": 6, "topTeams": 3, "topIndividuals": 7},
+		want: http.StatusOK, check: wantCount("qualification_rules", "meet_id = 5 AND top_teams = 3", 1)},
+	{route: "PUT /api/meets/:id/qualification", name: "invalid id", path: "/api/meets/abc/qualification", role: roleHeadCoach, body: obj{"targetMeetId": 6}, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id/qualification", name: "missing body", path: "/api/meets/5/qualification", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id/qualification", name: "not found", path: "/api/meets/99/qualification", role: roleHeadCoach, body: obj{"targetMeetId": 6}, want: http.StatusNotFound},
+	{route: "DELETE /api/meets/:id/qualification", path: "/api/meets/5/qualification", role: roleHeadCoach, want: http.StatusOK, check: wantCount("qualification_rules", "", 0)},
+	{route: "DELETE /api/meets/:id/qualification", name: "invalid id", path: "/api/meets/abc/qualification", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id/team-finishes/:division", path: "/api/meets/5/team-finishes/girls", role: roleHeadCoach, body: obj{"place": 2, "score": 61},
+		want: http.StatusOK, check: wantCount("team_finishes", "meet_id = 5 AND division = 'girls'", 1)},
+	{route: "PUT /api/meets/:id/team-finishes/:division", name: "invalid division", path: "/api/meets/5/team-finishes/mixed", role: roleHeadCoach, body: obj{"place": 2}, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id/team-finishes/:division", name: "missing body", path: "/api/meets/5/team-finishes/girls", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/meets/:id/team-finishes/:division", name: "not found", path: "/api/meets/99/team-finishes/girls", role: roleHeadCoach, body: obj{"place": 2}, want: http.StatusNotFound},
+	{route: "GET /api/meets/:id/advancers", path: "/api/meets/5/advancers", want: http.StatusOK},
+	{route: "GET /api/meets/:id/advancers", name: "invalid id", path: "/api/meets/abc/advancers", want: http.StatusBadRequest},
+	{route: "GET /api/meets/:id/qualified", path: "/api/meets/6/qualified", want: http.StatusOK},
+	{route: "GET /api/meets/:id/qualified", name: "invalid id", path: "/api/meets/abc/qualified", want: http.StatusBadRequest},
+
+	// Results
+	{route: "GET /api/results", want: http.StatusOK},
+	{route: "GET /api/results/all", want: http.StatusOK},
+	{route: "GET /api/results/top10", want: http.StatusOK},
+	{route: "POST /api/results", role: roleHeadCoach, setup: startMeet, body: newResult,
+		want: http.StatusCreated, check: wantCount("results", "meet_id = 5", 1)},
+	{route: "POST /api/results", name: "duplicate", role: roleHeadCoach, setup: addResult, body: newResult,
+		want: http.StatusConflict, check: wantError("already has a result")},
+	{route: "POST /api/results", name: "official meet", role: roleHeadCoach,
+		body: obj{"athleteId": 7, "meetId": 2, "eventTypeId": 1, "time": "19:00"}, want: http.StatusConflict},
+	{route: "POST /api/results", name: "unknown athlete", role: roleHeadCoach, setup: startMeet,
+		body: obj{"athleteId": 99, "meetId": 5, "eventTypeId": 1, "time": "19:00"}, want: http.StatusBadRequest},
+	{route: "POST /api/results", name: "missing body", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/results/:id", path: "/api/results/" + nextResultID, role: roleHeadCoach, setup: addResult,
+		body: obj{"athleteId": 1, "meetId": 5, "eventTypeId": 1, "time": "16:05", "place": 1},
+		want: http.StatusOK, check: wantCount("results", "id = "+nextResultID+" AND time = '16:05'", 1)},
+	{route: "PUT /api/results/:id", name: "duplicate", path: "/api/results/" + nextResultID, role: roleHeadCoach,
+		setup: func(ts *testServer) {
+			addResult(ts)
+			ts.do(http.MethodPost, "/api/results", roleHeadCoach, obj{"athleteId": 2, "meetId": 5, "eventTypeId": 1, "time": "16:30"})
+		},
+		body: obj{"athleteId": 2, "meetId": 5, "eventTypeId": 1, "time": "16:05"}, want: http.StatusConflict},
+	{route: "PUT /api/results/:id", name: "official meet", path: "/api/results/1", role: roleHeadCoach,
+		body: obj{"athleteId": 1, "meetId": 1, "eventTypeId": 1, "time": "16:00"}, want: http.StatusConflict},
+	{route: "PUT /api/results/:id", name: "invalid id", path: "/api/results/abc", role: roleHeadCoach, body: newResult, want: http.StatusBadRequest},
+	{route: "PUT /api/results/:id", name: "missing body", path: "/api/results/1", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/results/:id", name: "not found", path: "/api/results/999", role: roleHeadCoach, body: newResult, want: http.StatusNotFound},
+	{route: "DELETE /api/results/:id", path: "/api/results/" + nextResultID, role: roleHeadCoach, setup: addResult,
+		want: http.StatusOK, check: wantCount("results", "meet_id = 5", 0)},
+	{route: "DELETE /api/results/:id", name: "official meet", path: "/api/results/1", role: roleHeadCoach, want: http.StatusConflict},
+	{route: "DELETE /api/results/:id", name: "invalid id", path: "/api/results/abc", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "DELETE /api/results/:id", name: "not found", path: "/api/results/999", role: roleHeadCoach, want: http.StatusNotFound},
+
+	// Statistics
+	{route: "GET /api/stats/team", want: http.StatusOK},
+	{route: "GET /api/stats/team", name: "unknown standard", path: "/api/stats/team?standard=99", want: http.StatusNotFound},
+	{route: "GET /api/stats/team", name: "target without event", path: "/api/stats/team?target=18:00", want: http.StatusBadRequest},
+
+	// Audit log
+	{route: "GET /api/audit", role: roleHeadCoach,
+		setup: func(ts *testServer) { ts.do(http.MethodDelete, "/api/athletes/1", roleHeadCoach, nil) },
+		want:  http.StatusOK},
+	{route: "GET /api/audit/:entity/:id", path: "/api/audit/athlete/1", role: roleHeadCoach,
+		setup: func(ts *testServer) { ts.do(http.MethodDelete, "/api/athletes/1", roleHeadCoach, nil) },
+		want:  http.StatusOK, check: wantLength(1)},
+	{route: "GET /api/audit/:entity/:id", name: "unknown entity", path: "/api/audit/course/1", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "GET /api/audit/:entity/:id", name: "invalid id", path: "/api/audit/athlete/abc", role: roleHeadCoach, want: http.StatusBadRequest},
+
+	// Auth
+	{route: "POST /api/login", body: obj{"username": "assistant", "password": testPassword}, want: http.StatusOK,
+		check: wantCount("sessions", "user_id = ?", 2, assistantID)},
+	{route: "POST /api/login", name: "two-factor", body: obj{"username": "coach", "password": testPassword}, want: http.StatusOK,
+		check: func(t *testing.T, ts *testServer, rec *httptest.ResponseRecorder) {
+			var body struct {
+				TwoFactorRequired bool
+				Challenge         string
+				Token             string
+			}
+			decode(t, rec, &body)
+			if !body.TwoFactorRequired || body.Challenge == "" || body.Token != "" {
+				t.Errorf("got %s, want a challenge and no session", rec.Body)
+			}
+		}},
+	{route: "POST /api/login", name: "wrong password", body: obj{"username": "assistant", "password": "wrong-password"}, want: http.StatusUnauthorized},
+	{route: "POST /api/login", name: "missing body", want: http.StatusBadRequest},
+	{route: "POST /api/login/2fa",
+		body: func(ts *testServer) any {
+			challenge, _ := ts.newLoginChallenge(coachID, ts.now())
+			return obj{"challenge": challenge, "code": ts.totp(testTOTPSecret)}
+		},
+		want: http.StatusOK, check: wantCount("sessions", "user_id = ?", 2, coachID)},
+	{route: "POST /api/login/2fa", name: "wrong code",
+		body: func(ts *testServer) any {
+			challenge, _ := ts.newLoginChallenge(coachID, ts.now())
+			return obj{"challenge": challenge, "code": "000000"}
+		},
+		want: http.StatusUnauthorized},
+	{route: "POST /api/login/2fa", name: "invalid challenge", body: obj{"challenge": "forged", "code": "000000"}, want: http.StatusUnauthorized},
+	{route: "POST /api/login/2fa", name: "missing code", body: obj{"challenge": "forged"}, want: http.StatusBadRequest},
+	{route: "GET /api/auth/oidc", want: http.StatusOK},
+	{route: "GET /api/auth/oidc/login", name: "not configured", want: http.StatusNotFound},
+	{route: "GET /api/auth/oidc/callback", name: "not configured", want: http.StatusNotFound},
+	{route: "POST /api/invites/accept",
+		body: func(ts *testServer) any {
+			token, err := ts.issueAccountToken(context.Background(), assistantID, purposeInvite, inviteTTL, sql.NullInt32{})
+			if err != nil {
+				ts.t.Fatal(err)
+			}
+			return obj{"token": token, "password": "a-new-password"}
+		},
+		want: http.StatusOK},
+	{route: "POST /api/invites/accept", name: "invalid token", body: obj{"token": "forged", "password": "a-new-password"}, want: http.StatusBadRequest},
+	{route: "POST /api/invites/accept", name: "missing body", want: http.StatusBadRequest},
+	{route: "POST /api/password-reset", body: obj{"identifier": "assistant@example.org"}, want: http.StatusOK,
+		check: func(t *testing.T, ts *testServer, _ *httptest.ResponseRecorder) {
+			ts.background.Wait()
+			if sent := ts.mailbox.Sent(); len(sent) != 1 || sent[0].To != "assistant@example.org" {
+				t.Errorf("sent %+v, want one reset mail to the assistant", sent)
+			}
+		}},
+	{route: "POST /api/password-reset", name: "unknown account", body: obj{"identifier": "nobody"}, want: http.StatusOK,
+		check: func(t *testing.T, ts *testServer, _ *httptest.ResponseRecorder) {
+			ts.background.Wait()
+			if sent := ts.mailbox.Sent(); len(sent) != 0 {
+				t.Errorf("sent %+v, want nothing", sent)
+			}
+		}},
+	{route: "POST /api/password-reset", name: "missing body", want: http.StatusBadRequest},
+	{route: "POST /api/password-reset/confirm",
+		body: func(ts *testServer) any {
+			token, err := ts.issueAccountToken(context.Background(), assistantID, purposePasswordReset, passwordResetTTL, sql.NullInt32{})
+			if err != nil {
+				ts.t.Fatal(err)
+			}
+			ts.issueToken(roleAssistant, 0, string(permEnterResults))
+			return obj{"token": token, "password": "a-new-password"}
+		},
+		want: http.StatusOK, check: wantCount("api_tokens", "user_id = ? AND revoked_at IS NOT NULL", 1, assistantID)},
+	{route: "POST /api/password-reset/confirm", name: "short password",
+		body: func(ts *testServer) any {
+			token, err := ts.issueAccountToken(context.Background(), assistantID, purposePasswordReset, passwordResetTTL, sql.NullInt32{})
+			if err != nil {
+				ts.t.Fatal(err)
+			}
+			return obj{"token": token, "password": "short"}
+		},
+		want: http.StatusBadRequest},
+	{route: "POST /api/password-reset/confirm", name: "invalid token", body: obj{"token": "forged", "password": "a-new-password"}, want: http.StatusBadRequest},
+	{route: "GET /api/session", role: roleAssistant, want: http.StatusOK},
+	{route: "POST /api/session/refresh", role: roleAssistant, want: http.StatusOK},
+	{route: "POST /api/logout", role: roleAssistant, want: http.StatusOK, check: wantCount("sessions", "user_id = ?", 0, assistantID)},
+	{route: "POST /api/account/password", role: roleAssistant, body: obj{"currentPassword": testPassword, "newPassword": "a-new-password"}, want: http.StatusOK},
+	{route: "POST /api/account/password", name: "wrong password", role: roleAssistant, body: obj{"currentPassword": "wrong-password", "newPassword": "a-new-password"}, want: http.StatusUnauthorized},
+	{route: "POST /api/account/password", name: "missing body", role: roleAssistant, want: http.StatusBadRequest},
+	{route: "GET /api/account/2fa", role: roleHeadCoach, want: http.StatusOK},
+	{route: "POST /api/account/2fa/setup", role: roleAssistant, want: http.StatusOK, check: wantCount("user_totp", "user_id = ?", 1, assistantID)},
+	{route: "POST /api/account/2fa/setup", name: "already enabled", role: roleHeadCoach, want: http.StatusConflict},
+	{route: "POST /api/account/2fa/confirm", role: roleAssistant,
+		body: func(ts *testServer) any {
+			var setup struct{ Secret string }
+			decode(ts.t, ts.do(http.MethodPost, "/api/account/2fa/setup", roleAssistant, nil), &setup)
+			return obj{"code": ts.totp(setup.Secret)}
+		},
+		want: http.StatusOK, check: wantCount("recovery_codes", "user_id = ?", recoveryCodeCount, assistantID)},
+	{route: "POST /api/account/2fa/confirm", name: "wrong code", role: roleAssistant,
+		setup: func(ts *testServer) { ts.do(http.MethodPost, "/api/account/2fa/setup", roleAssistant, nil) },
+		body:  obj{"code": "000000"}, want: http.StatusBadRequest},
+	{route: "POST /api/account/2fa/confirm", name: "not set up", role: roleAssistant, body: obj{"code": "000000"}, want: http.StatusConflict},
+	{route: "POST /api/account/2fa/confirm", name: "missing body", role: roleAssistant, want: http.StatusBadRequest},
+	{route: "POST /api/account/2fa/recovery-codes", role: roleHeadCoach,
+		body: func(ts *testServer) any { return obj{"code": ts.totp(testTOTPSecret)} },
+		want: http.StatusOK, check: wantCount("recovery_codes", "user_id = ?", recoveryCodeCount, coachID)},
+	{route: "POST /api/account/2fa/recovery-codes", name: "wrong code", role: roleHeadCoach, body: obj{"code": "000000"}, want: http.StatusBadRequest},
+	{route: "POST /api/account/2fa/recovery-codes", name: "not enabled", role: roleAssistant, body: obj{"code": "000000"}, want: http.StatusConflict},
+	{route: "DELETE /api/account/2fa", role: roleAssistant, body: obj{"password": testPassword}, want: http.StatusOK},
+	{route: "DELETE /api/account/2fa", name: "required", role: roleHeadCoach, body: obj{"password": testPassword}, want: http.StatusConflict},
+	{route: "DELETE /api/account/2fa", name: "wrong password", role: roleAssistant, body: obj{"password": "wrong-password"}, want: http.StatusUnauthorized},
+	{route: "GET /api/account/tokens", role: roleAssistant,
+		setup: func(ts *testServer) { ts.issueToken(roleAssistant, 0, scopeReadAll) },
+		want:  http.StatusOK, check: wantLength(1)},
+	{route: "POST /api/account/tokens", role: roleAssistant, body: obj{"name": "Finish line", "scopes": []string{"results:write"}, "meetId": 5},
+		want: http.StatusCreated, check: wantCount("api_tokens", "user_id = ? AND meet_id = 5", 1, assistantID)},
+	{route: "POST /api/account/tokens", name: "scope beyond role", role: roleAssistant, body: obj{"name": "Meets", "scopes": []string{"meets:write"}}, want: http.StatusBadRequest},
+	{route: "POST /api/account/tokens", name: "unknown scope", role: roleAssistant, body: obj{"name": "All", "scopes": []string{"admin"}}, want: http.StatusBadRequest},
+	{route: "POST /api/account/tokens", name: "unknown meet", role: roleAssistant, body: obj{"name": "Timer", "scopes": []string{"results:write"}, "meetId": 99}, want: http.StatusBadRequest},
+	{route: "POST /api/account/tokens", name: "missing body", role: roleAssistant, want: http.StatusBadRequest},
+	{route: "DELETE /api/account/tokens/:tokenId", path: "/api/account/tokens/1", role: roleAssistant,
+		setup: func(ts *testServer) { ts.issueToken(roleAssistant, 0, scopeReadAll) },
+		want:  http.StatusOK, check: wantCount("api_tokens", "revoked_at IS NOT NULL", 1)},
+	{route: "DELETE /api/account/tokens/:tokenId", name: "someone else's", path: "/api/account/tokens/1", role: roleAssistant,
+		setup: func(ts *testServer) { ts.issueToken(roleHeadCoach, 0, scopeReadAll) },
+		want:  http.StatusNotFound},
+	{route: "DELETE /api/account/tokens/:tokenId", name: "invalid id", path: "/api/account/tokens/abc", role: roleAssistant, want: http.StatusBadRequest},
+	{route: "GET /api/me/athletes", role: roleParent, want: http.StatusOK, check: wantLength(1)},
+
+	// Users
+	{route: "GET /api/users", role: roleHeadCoach, want: http.StatusOK, check: wantLength(4)},
+	{route: "GET /api/users/:id", path: "/api/users/2", role: roleHeadCoach, want: http.StatusOK},
+	{route: "GET /api/users/:id", name: "invalid id", path: "/api/users/abc", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "GET /api/users/:id", name: "not found", path: "/api/users/99", role: roleHeadCoach, want: http.StatusNotFound},
+	{route: "POST /api/users", role: roleHeadCoach, body: obj{"username": "volunteer", "password": testPassword, "role": roleAssistant},
+		want: http.StatusCreated, check: wantCount("users", "", 5)},
+	{route: "POST /api/users", name: "duplicate", role: roleHeadCoach, body: obj{"username": "assistant", "password": testPassword}, want: http.StatusConflict},
+	{route: "POST /api/users", name: "short password", role: roleHeadCoach, body: obj{"username": "volunteer", "password": "short"}, want: http.StatusBadRequest},
+	{route: "POST /api/users", name: "missing body", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/users/invite", role: roleHeadCoach, body: obj{"username": "newparent", "email": "newparent@example.org", "role": roleParent},
+		want: http.StatusCreated,
+		check: func(t *testing.T, ts *testServer, _ *httptest.ResponseRecorder) {
+			if sent := ts.mailbox.Sent(); len(sent) != 1 || sent[0].To != "newparent@example.org" {
+				t.Errorf("sent %+v, want one invite", sent)
+			}
+		}},
+	{route: "POST /api/users/invite", name: "invalid email", role: roleHeadCoach, body: obj{"username": "newparent", "email": "newparent"}, want: http.StatusBadRequest},
+	{route: "POST /api/users/invite", name: "duplicate", role: roleHeadCoach, body: obj{"username": "parent", "email": "parent@example.org"}, want: http.StatusConflict},
+	{route: "POST /api/users/:id/invite", path: "/api/users/2/invite", role: roleHeadCoach, want: http.StatusOK},
+	{route: "POST /api/users/:id/invite", name: "invalid id", path: "/api/users/abc/invite", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/users/:id/invite", name: "not found", path: "/api/users/99/invite", role: roleHeadCoach, want: http.StatusNotFound},
+	{route: "PUT /api/users/:id", path: "/api/users/2", role: roleHeadCoach, body: obj{"username": "assistant2", "role": roleAssistant},
+		want: http.StatusOK, check: wantCount("users", "username = 'assistant2'", 1)},
+	{route: "PUT /api/users/:id", name: "duplicate", path: "/api/users/2", role: roleHeadCoach, body: obj{"username": "runner", "role": roleAssistant}, want: http.StatusConflict},
+	{route: "PUT /api/users/:id", name: "missing role", path: "/api/users/2", role: roleHeadCoach, body: obj{"username": "assistant2"}, want: http.StatusBadRequest},
+	{route: "PUT /api/users/:id", name: "invalid id", path: "/api/users/abc", role: roleHeadCoach, body: obj{"username": "x", "role": roleAssistant}, want: http.StatusBadRequest},
+	{route: "PUT /api/users/:id", name: "not found", path: "/api/users/99", role: roleHeadCoach, body: obj{"username": "x", "role": roleAssistant}, want: http.StatusNotFound},
+	{route: "PUT /api/users/:id/password", path: "/api/users/2/password", role: roleHeadCoach,
+		setup: func(ts *testServer) { ts.issueToken(roleAssistant, 0, string(permEnterResults)) },
+		body:  obj{"password": "a-new-password"}, want: http.StatusOK,
+		check: wantCount("api_tokens", "user_id = ? AND revoked_at IS NOT NULL", 1, assistantID)},
+	{route: "PUT /api/users/:id/password", name: "short password", path: "/api/users/2/password", role: roleHeadCoach, body: obj{"password": "short"}, want: http.StatusBadRequest},
+	{route: "PUT /api/users/:id/password", name: "invalid id", path: "/api/users/abc/password", role: roleHeadCoach, body: obj{"password": "a-new-password"}, want: http.StatusBadRequest},
+	{route: "PUT /api/users/:id/password", name: "not found", path: "/api/users/99/password", role: roleHeadCoach, body: obj{"password": "a-new-password"}, want: http.StatusNotFound},
+	{route: "POST /api/users/:id/unlock", path: "/api/users/2/unlock", role: roleHeadCoach, want: http.StatusOK},
+	{route: "POST /api/users/:id/unlock", name: "invalid id", path: "/api/users/abc/unlock", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "POST /api/users/:id/unlock", name: "not found", path: "/api/users/99/unlock", role: roleHeadCoach, want: http.StatusNotFound},
+	{route: "DELETE /api/users/:id/2fa", path: "/api/users/1/2fa", role: roleHeadCoach, want: http.StatusOK, check: wantCount("user_totp", "", 0)},
+	{route: "DELETE /api/users/:id/2fa", name: "invalid id", path: "/api/users/abc/2fa", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "DELETE /api/users/:id/2fa", name: "not found", path: "/api/users/99/2fa", role: roleHeadCoach, want: http.StatusNotFound},
+	{route: "GET /api/users/:id/tokens", path: "/api/users/2/tokens", role: roleHeadCoach,
+		setup: func(ts *testServer) { ts.issueToken(roleAssistant, 0, scopeReadAll) },
+		want:  http.StatusOK, check: wantLength(1)},
+	{route: "GET /api/users/:id/tokens", name: "invalid id", path: "/api/users/abc/tokens", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "GET /api/users/:id/tokens", name: "not found", path: "/api/users/99/tokens", role: roleHeadCoach, want: http.StatusNotFound},
+	{route: "POST /api/users/:id/tokens", path: "/api/users/2/tokens", role: roleHeadCoach, body: obj{"name": "Timer", "scopes": []string{"results:write"}},
+		want: http.StatusCreated, check: wantCount("api_tokens", "user_id = ? AND created_by = ?", 1, assistantID, coachID)},
+	{route: "POST /api/users/:id/tokens", name: "invalid id", path: "/api/users/abc/tokens", role: roleHeadCoach, body: obj{"name": "Timer", "scopes": []string{"results:write"}}, want: http.StatusBadRequest},
+	{route: "POST /api/users/:id/tokens", name: "not found", path: "/api/users/99/tokens", role: roleHeadCoach, body: obj{"name": "Timer", "scopes": []string{"results:write"}}, want: http.StatusNotFound},
+	{route: "DELETE /api/users/:id/tokens/:tokenId", path: "/api/users/2/tokens/1", role: roleHeadCoach,
+		setup: func(ts *testServer) { ts.issueToken(roleAssistant, 0, scopeReadAll) },
+		want:  http.StatusOK, check: wantCount("api_tokens", "revoked_at IS NOT NULL", 1)},
+	{route: "DELETE /api/users/:id/tokens/:tokenId", name: "wrong user", path: "/api/users/3/tokens/1", role: roleHeadCoach,
+		setup: func(ts *testServer) { ts.issueToken(roleAssistant, 0, scopeReadAll) },
+		want:  http.StatusNotFound},
+	{route: "DELETE /api/users/:id/tokens/:tokenId", name: "invalid id", path: "/api/users/abc/tokens/1", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/users/:id/athletes", path: "/api/users/4/athletes", role: roleHeadCoach, body: obj{"athleteIds": []int{9, 12}},
+		want: http.StatusOK, check: wantCount("user_athletes", "user_id = ?", 2, parentID)},
+	{route: "PUT /api/users/:id/athletes", name: "athlete with two", path: "/api/users/3/athletes", role: roleHeadCoach, body: obj{"athleteIds": []int{1, 2}}, want: http.StatusBadRequest},
+	{route: "PUT /api/users/:id/athletes", name: "staff account", path: "/api/users/2/athletes", role: roleHeadCoach, body: obj{"athleteIds": []int{1}}, want: http.StatusBadRequest},
+	{route: "PUT /api/users/:id/athletes", name: "unknown athlete", path: "/api/users/4/athletes", role: roleHeadCoach, body: obj{"athleteIds": []int{99}}, want: http.StatusBadRequest},
+	{route: "PUT /api/users/:id/athletes", name: "missing body", path: "/api/users/4/athletes", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "PUT /api/users/:id/athletes", name: "not found", path: "/api/users/99/athletes", role: roleHeadCoach, body: obj{"athleteIds": []int{1}}, want: http.StatusNotFound},
+	{route: "DELETE /api/users/:id", path: "/api/users/2", role: roleHeadCoach,
+		setup: func(ts *testServer) { ts.issueToken(roleAssistant, 0, scopeReadAll) },
+		want:  http.StatusOK,
+		check: func(t *testing.T, ts *testServer, rec *httptest.ResponseRecorder) {
+			wantCount("sessions", "user_id = ?", 0, assistantID)(t, ts, rec)
+			wantCount("api_tokens", "user_id = ?", 0, assistantID)(t, ts, rec)
+		}},
+	{route: "DELETE /api/users/:id", name: "last head coach", path: "/api/users/1", role: roleHeadCoach, want: http.StatusConflict},
+	{route: "DELETE /api/users/:id", name: "invalid id", path: "/api/users/abc", role: roleHeadCoach, want: http.StatusBadRequest},
+	{route: "DELETE /api/users/:id", name: "not found", path: "/api/users/99", role: roleHeadCoach, want: http.StatusNotFound},
+}
+
+func TestHandlers(t *testing.T) {
+	for _, tc := range handlerCases {
+		name := tc.route
+		if tc.name != "" {
+			name += " " + tc.name
+		}
+		t.Run(name, func(t *testing.T) {
+			ts := newTestServer(t)
+			if tc.setup != nil {
+				tc.setup(ts)
+			}
+			method, path, _ := strings.Cut(tc.route, " ")
+			if tc.path != "" {
+				path = tc.path
+			}
+			body := tc.body
+			if f, ok := body.(func(*testServer) any); ok {
+				body = f(ts)
+			}
+			rec := ts.do(method, path, tc.role, body)
+			if rec.Code != tc.want {
+				t.Fatalf("%s %s: got %d %s, want %d", method, path, rec.Code, rec.Body, tc.want)
+			}
+			if tc.check != nil {
+				tc.check(t, ts, rec)
+			}
+		})
+	}
+}
+
+// TestHandlerCasesCoverRoutes makes sure every route has a successful case,
+// so a new endpoint can't be added without one.
+func TestHandlerCasesCoverRoutes(t *testing.T) {
+	covered := make(map[string]bool)
+	for _, tc := range handlerCases {
+		if tc.want < 300 {
+			covered[tc.route] = true
+		}
+	}
+	srv := newServer(defaultConfig(), nil, discardLogger())
+	for _, rt := range srv.routes() {
+		if key := rt.Method + " " + rt.Path; !covered[key] && !strings.HasPrefix(rt.Path, "/api/auth/oidc/") {
+			t.Errorf("no successful case for %s", key)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// INVITES AND PASSWORD RESETS
+// =====================
+
+const (
+	purposeInvite        = "invite"
+	purposePasswordReset = "password_reset"
+
+	inviteTTL        = 7 * 24 * time.Hour
+	passwordResetTTL = time.Hour
+)
+
+func (srv *server) appLink(path, token string) string {
+	return srv.cfg.AppURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func hashAccountToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueAccountToken creates a single-use token for purpose, replacing any
+// unused one the user already has for the same purpose.
+func (srv *server) issueAccountToken(ctx context.Context, userID int32, purpose string, ttl time.Duration, createdBy sql.NullInt32) (string, error) {
+	token, err := randomString()
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := srv.repo.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	if err := tx.DeleteUnusedAccountTokens(ctx, db.DeleteUnusedAccountTokensParams{UserID: userID, Purpose: purpose}); err != nil {
+		return "", err
+	}
+	err = tx.CreateAccountToken(ctx, db.CreateAccountTokenParams{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashAccountToken(token),
+		ExpiresAt: srv.now().Add(ttl).UTC().Truncate(time.Second),
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, tx.Commit()
+}
+
+var errInvalidAccountToken = errors.New("this link is invalid or has expired")
+
+// redeemAccountToken uses up a token and sets the user's password. All of the
+// user's sessions end and their API tokens are revoked, since whoever held
+// them may not have known the new password.
+func (srv *server) redeemAccountToken(ctx context.Context, token, purpose, password string) (db.User, error) {
+	accountToken, err := srv.repo.GetAccountTokenByHash(ctx, hashAccountToken(token))
+	if err == sql.ErrNoRows {
+		return db.User{}, errInvalidAccountToken
+	}
+	if err != nil {
+		return db.User{}, err
+	}
+	if accountToken.Purpose != purpose || accountToken.UsedAt.Valid || srv.now().After(accountToken.ExpiresAt) {
+		return db.User{}, errInvalidAccountToken
+	}
+
+	user, err := srv.repo.GetUserByID(ctx, accountToken.UserID)
+	if err != nil {
+		return db.User{}, err
+	}
+	if !user.Active {
+		return db.User{}, errInvalidAccountToken
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	tx, err := srv.repo.Begin(ctx)
+	if err != nil {
+		return db.User{}, err
+	}
+	defer tx.Rollback()
+
+	// Marking the token used first makes two concurrent redemptions race
+	// on one row; only the one that updates it goes on.
+	result, err := tx.UseAccountToken(ctx, accountToken.ID)
+	if err != nil {
+		return db.User{}, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n != 1 {
+		return db.User{}, errInvalidAccountToken
+	}
+	if err := tx.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{ID: user.ID, PasswordHash: hash}); err != nil {
+		return db.User{}, err
+	}
+	if err := tx.DeleteUserSessions(ctx, user.ID); err != nil {
+		return db.User{}, err
+	}
+	if err := tx.RevokeUserAPITokens(ctx, user.ID); err != nil {
+		return db.User{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return db.User{}, err
+	}
+
+	srv.loginLimits.unlock(user.Username)
+	return user, nil
+}
+
+func redeemErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errInvalidAccountToken),
+		errors.Is(err, errPasswordTooShort), errors.Is(err, errPasswordTooLong):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func displayName(u db.User) string {
+	if u.DisplayName.Valid && u.DisplayName.String != "" {
+		return u.DisplayName.String
+	}
+	return u.Username
+}
+
+// sendInvite issues a fresh invite token for user and emails it.
+func (srv *server) sendInvite(c *gin.Context, user db.User) error {
+	inviter, _ := currentUser(c)
+	token, err := srv.issueAccountToken(c.Request.Context(), user.ID, purposeInvite, inviteTTL,
+		sql.NullInt32{Int32: inviter.ID, Valid: true})
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(`Hi %s,
+
+%s has invited you to the Jones County XC site as %s.
+Your username is %s.
+
+Choose a password to finish setting up your account:
+%s
+
+This link works once and expires in 7 days.
+`, displayName(user), displayName(inviter), strings.ReplaceAll(user.Role, "_", " "), user.Username,
+		srv.appLink("/accept-invite", token))
+
+	return srv.mail.Send(c.Request.Context(), mailMessage{
+		To:      user.Email.String,
+		Subject: "You're invited to Jones County XC",
+		Body:    body,
+	})
+}
+
+type InviteRequest struct {
+	Username    string `json:"username" binding:"required,max=50"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email" binding:"required,email"`
+	Role        string `json:"role" binding:"omitempty,oneof=head_coach assistant athlete parent"`
+}
+
+// inviteUserHandler creates an account with an unusable random password and
+// emails the new user a link to choose their own.
+func (srv *server) inviteUserHandler(c *gin.Context) {
+	var req InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Role == "" {
+		req.Role = roleAssistant
+	}
+
+	password, err := randomString()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := createUser(c.Request.Context(), srv.repo, CreateUserRequest{
+		Username:    req.Username,
+		Password:    password,
+		DisplayName: req.DisplayName,
+		Email:       req.Email,
+		Role:        req.Role,
+	})
+	if err != nil {
+		if errors.Is(err, errUsernameTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := srv.sendInvite(c, user); err != nil {
+		srv.log.Error("failed to send invite", "email", user.Email.String, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": "The account was created but the invitation email could not be sent; try resending it",
+			"user":  userResponse(user),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, userResponse(user))
+}
+
+// resendInviteHandler sends a new invite link, cancelling the previous one.
+func (srv *server) resendInviteHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := srv.repo.GetUserByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !user.Email.Valid || user.Email.String == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User has no email address"})
+		return
+	}
+
+	if err := srv.sendInvite(c, user); err != nil {
+		srv.log.Error("failed to send invite", "email", user.Email.String, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "The invitation email could not be sent"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation sent"})
+}
+
+type SetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func (srv *server) acceptInviteHandler(c *gin.Context) {
+	var req SetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := srv.redeemAccountToken(c.Request.Context(), req.Token, purposeInvite, req.Password)
+	if err != nil {
+		c.JSON(redeemErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password set", "username": user.Username})
+}
+
+type PasswordResetRequest struct {
+	// Identifier is a username or an email address.
+	Identifier string `json:"identifier" binding:"required"`
+}
+
+// requestPasswordResetHandler emails reset links. It answers the same way
+// whether or not an account matched, so it can't be used to find accounts.
+func (srv *server) requestPasswordResetHandler(c *gin.Context) {
+	var req PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	identifier := strings.TrimSpace(req.Identifier)
+	var users []db.User
+	if strings.Contains(identifier, "@") {
+		found, err := srv.repo.GetUsersByEmail(ctx, sql.NullString{String: identifier, Valid: true})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		users = found
+	} else if user, err := srv.repo.GetUserByUsername(ctx, identifier); err == nil {
+		users = append(users, user)
+	} else if err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The link is issued and mailed after the response has gone out, so how
+	// long the request takes doesn't reveal whether an account matched.
+	srv.background.Add(1)
+	go func() {
+		defer srv.background.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), smtpTimeout)
+		defer cancel()
+		for _, user := range users {
+			if !user.Active || !user.Email.Valid || user.Email.String == "" {
+				continue
+			}
+			srv.sendPasswordReset(ctx, user)
+		}
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"message": "If an account matches, a reset link has been emailed to it"})
+}
+
+func (srv *server) sendPasswordReset(ctx context.Context, user db.User) {
+	token, err := srv.issueAccountToken(ctx, user.ID, purposePasswordReset, passwordResetTTL, sql.NullInt32{})
+	if err != nil {
+		srv.log.Error("failed to issue password reset", "user", user.Username, "error", err)
+		return
+	}
+	body := fmt.Sprintf(`Hi %s,
+
+Someone asked to reset the password for your Jones County XC account (%s).
+Choose a new password here:
+%s
+
+This link works once and expires in 1 hour. If you didn't ask for this, you
+can ignore this email and your password will stay the same.
+`, displayName(user), user.Username, srv.appLink("/reset-password", token))
+
+	err = srv.mail.Send(ctx, mailMessage{
+		To:      user.Email.String,
+		Subject: "Reset your Jones County XC password",
+		Body:    body,
+	})
+	if err != nil {
+		srv.log.Error("failed to send password reset", "email", user.Email.String, "error", err)
+	}
+}
+
+func (srv *server) resetPasswordHandler(c *gin.Context) {
+	var req SetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := srv.redeemAccountToken(c.Request.Context(), req.Token, purposePasswordReset, req.Password)
+	if err != nil {
+		c.JSON(redeemErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated", "username": user.Username})
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =====================
+// LOGIN RATE LIMITING
+// =====================
+
+// loginAttempts is the failure history kept for one username or IP address.
+// Pending counts attempts that have been let through but not yet settled.
+type loginAttempts struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+	Pending     int
+}
+
+// attemptStore holds login failure history. The limiter serializes access,
+// so implementations need not be safe for concurrent use. ttl is how long the
+// entry matters; a store may forget it after that.
+type attemptStore interface {
+	Get(key string) (loginAttempts, bool)
+	Set(key string, attempts loginAttempts, ttl time.Duration)
+	Delete(key string)
+}
+
+// limitPolicy describes how failures are punished. The first freeAttempts
+// failures cost nothing; after that each attempt must wait baseDelay, doubling
+// per failure up to maxDelay. Reaching lockoutAfter failures locks the key for
+// lockoutFor. Failures are forgotten after resetAfter without another one.
+type limitPolicy struct {
+	freeAttempts int
+	lockoutAfter int
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+	lockoutFor   time.Duration
+	resetAfter   time.Duration
+}
+
+// Usernames are limited tightly. IP addresses get more room since a school
+// network may put many users behind one address.
+var (
+	usernameLimitPolicy = limitPolicy{
+		freeAttempts: 3,
+		lockoutAfter: 10,
+		baseDelay:    time.Second,
+		maxDelay:     time.Minute,
+		lockoutFor:   15 * time.Minute,
+		resetAfter:   time.Hour,
+	}
+	ipLimitPolicy = limitPolicy{
+		freeAttempts: 10,
+		lockoutAfter: 50,
+		baseDelay:    time.Second,
+		maxDelay:     time.Minute,
+		lockoutFor:   15 * time.Minute,
+		resetAfter:   time.Hour,
+	}
+)
+
+// current returns the attempts that still count at now.
+func (p limitPolicy) current(a loginAttempts, now time.Time) loginAttempts {
+	if now.Before(a.LockedUntil) || now.Sub(a.LastFailure) < p.resetAfter {
+		return a
+	}
+	return loginAttempts{Pending: a.Pending}
+}
+
+// wait is how long until another attempt is allowed. Pending attempts count
+// as failures made just now, so guesses sent side by side can't all slip in
+// before the first of them is marked failed.
+func (p limitPolicy) wait(a loginAttempts, now time.Time) time.Duration {
+	if now.Before(a.LockedUntil) {
+		return a.LockedUntil.Sub(now)
+	}
+	failures, last := a.Failures, a.LastFailure
+	if a.Pending > 0 {
+		failures += a.Pending
+		last = now
+	}
+	if failures <= p.freeAttempts {
+		return 0
+	}
+	delay := p.maxDelay
+	if shift := failures - p.freeAttempts - 1; shift < 30 {
+		delay = min(p.baseDelay<<shift, p.maxDelay)
+	}
+	if next := last.Add(delay); now.Before(next) {
+		return next.Sub(now)
+	}
+	return 0
+}
+
+type loginLimiter struct {
+	mu       sync.Mutex
+	store    attemptStore
+	now      func() time.Time
+	log      *slog.Logger
+	username limitPolicy
+	ip       limitPolicy
+}
+
+func newLoginLimiter(store attemptStore, now func() time.Time, logger *slog.Logger) *loginLimiter {
+	return &loginLimiter{
+		store:    store,
+		now:      now,
+		log:      logger,
+		username: usernameLimitPolicy,
+		ip:       ipLimitPolicy,
+	}
+}
+
+func usernameKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (l *loginLimiter) load(key string, p limitPolicy, now time.Time) loginAttempts {
+	a, _ := l.store.Get(key)
+	return p.current(a, now)
+}
+
+// retryAfter returns how long the caller must wait before trying to log in
+// as username from ip, or zero if an attempt is allowed now.
+func (l *loginLimiter) retryAfter(username, ip string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	return max(
+		l.username.wait(l.load(usernameKey(username), l.username, now), now),
+		l.ip.wait(l.load(ipKey(ip), l.ip, now), now),
+	)
+}
+
+// reserve lets an attempt to log in as username from ip go ahead if no
+// backoff applies, counting it as pending until it is settled. Checking and
+// reserving under one lock is what stops concurrent guesses from all seeing
+// the same failure count. It returns nil and how long to wait otherwise.
+func (l *loginLimiter) reserve(username, ip string) (*loginAttempt, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	userKey, addrKey := usernameKey(username), ipKey(ip)
+	user, addr := l.load(userKey, l.username, now), l.load(addrKey, l.ip, now)
+	if wait := max(l.username.wait(user, now), l.ip.wait(addr, now)); wait > 0 {
+		return nil, wait
+	}
+	user.Pending++
+	addr.Pending++
+	l.store.Set(userKey, user, max(l.username.resetAfter, l.username.lockoutFor))
+	l.store.Set(addrKey, addr, max(l.ip.resetAfter, l.ip.lockoutFor))
+	return &loginAttempt{limiter: l, username: username, ip: ip}, 0
+}
+
+// release gives back a pending attempt without counting it as a failure.
+func (l *loginLimiter) release(username, ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.releasePending(usernameKey(username), l.username, now)
+	l.releasePending(ipKey(ip), l.ip, now)
+}
+
+func (l *loginLimiter) releasePending(key string, p limitPolicy, now time.Time) {
+	a, ok := l.store.Get(key)
+	if !ok || a.Pending == 0 {
+		return
+	}
+	a = p.current(a, now)
+	a.Pending--
+	l.store.Set(key, a, max(p.resetAfter, p.lockoutFor))
+}
+
+// failure records a failed login against both the username and the IP,
+// settling the attempt's reservation if it had one.
+func (l *loginLimiter) failure(username, ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.log.Warn("failed login", "username", username, "ip", ip)
+	l.recordFailure(usernameKey(username), l.username, now)
+	l.recordFailure(ipKey(ip), l.ip, now)
+}
+
+func (l *loginLimiter) recordFailure(key string, p limitPolicy, now time.Time) {
+	a := l.load(key, p, now)
+	a.Failures++
+	a.LastFailure = now
+	if a.Pending > 0 {
+		a.Pending--
+	}
+	if p.lockoutAfter > 0 && a.Failures >= p.lockoutAfter {
+		l.log.Warn("locking out after failed logins", "key", key, "duration", p.lockoutFor, "failures", a.Failures)
+		a.LockedUntil = now.Add(p.lockoutFor)
+		a.Failures = 0
+	}
+	l.store.Set(key, a, max(p.resetAfter, p.lockoutFor))
+}
+
+// success clears the username's failures. The IP's are kept so that one
+// valid account can't be used to reset the limit for guessing others.
+func (l *loginLimiter) success(username string) {
+	l.unlock(username)
+}
+
+// loginAttempt is a reserved login attempt. Exactly one of failure or
+// success settles it; release, which is safe to defer, gives it back if
+// neither was called.
+type loginAttempt struct {
+	limiter      *loginLimiter
+	username, ip string
+	settled      bool
+}
+
+func (a *loginAttempt) failure() {
+	if a.settled {
+		return
+	}
+	a.settled = true
+	a.limiter.failure(a.username, a.ip)
+}
+
+func (a *loginAttempt) success() {
+	if a.settled {
+		return
+	}
+	a.settled = true
+	a.limiter.release(a.username, a.ip)
+	a.limiter.success(a.username)
+}
+
+func (a *loginAttempt) release() {
+	if a.settled {
+		return
+	}
+	a.settled = true
+	a.limiter.release(a.username, a.ip)
+}
+
+// unlock lifts any backoff or lockout on a username.
+func (l *loginLimiter) unlock(username string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.store.Delete(usernameKey(username))
+}
+
+// lockedUntil reports when a username's lockout ends, if it is locked out.
+func (l *loginLimiter) lockedUntil(username string) (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	a := l.load(usernameKey(username), l.username, now)
+	return a.LockedUntil, now.Before(a.LockedUntil)
+}
+
+// memoryAttemptStore is the default in-process store. It expires entries by
+// the same clock as the limiter.
+type memoryAttemptStore struct {
+	entries   map[string]memoryAttemptEntry
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type memoryAttemptEntry struct {
+	attempts loginAttempts
+	expires  time.Time
+}
+
+func newMemoryAttemptStore(now func() time.Time) *memoryAttemptStore {
+	return &memoryAttemptStore{entries: make(map[string]memoryAttemptEntry), now: now}
+}
+
+func (s *memoryAttemptStore) Get(key string) (loginAttempts, bool) {
+	entry, ok := s.entries[key]
+	if !ok || s.now().After(entry.expires) {
+		return loginAttempts{}, false
+	}
+	return entry.attempts, true
+}
+
+func (s *memoryAttemptStore) Set(key string, attempts loginAttempts, ttl time.Duration) {
+	now := s.now()
+	s.entries[key] = memoryAttemptEntry{attempts: attempts, expires: now.Add(ttl)}
+
+	// Drop expired entries now and then so guessing many usernames doesn't
+	// grow the map forever.
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, entry := range s.entries {
+			if now.After(entry.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+}
+
+func (s *memoryAttemptStore) Delete(key string) {
+	delete(s.entries, key)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testClock is a clock that only moves when told to.
+type testClock struct {
+	t time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{t: time.Date(2025, 9, 6, 8, 0, 0, 0, time.UTC)}
+}
+
+func (c *testClock) Now() time.Time          { return c.t }
+func (c *testClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func newTestLimiter(clock *testClock) *loginLimiter {
+	return newLoginLimiter(newMemoryAttemptStore(clock.Now), clock.Now, discardLogger())
+}
+
+func TestLoginLimiterBackoff(t *testing.T) {
+	clock := newTestClock()
+	l := newTestLimiter(clock)
+
+	for i := 0; i < usernameLimitPolicy.freeAttempts; i++ {
+		l.failure("coach", "10.0.0.1")
+		if wait := l.retryAfter("coach", "10.0.0.1"); wait != 0 {
+			t.Fatalf("after %d failures: wait %s, want 0", i+1, wait)
+		}
+	}
+
+	// Each failure past the free ones doubles the wait, up to maxDelay.
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		l.failure("coach", "10.0.0.1")
+		if wait := l.retryAfter("coach", "10.0.0.1"); wait != want {
+			t.Fatalf("wait %s, want %s", wait, want)
+		}
+		clock.Advance(want / 2)
+		if wait := l.retryAfter("coach", "10.0.0.1"); wait != want/2 {
+			t.Fatalf("halfway: wait %s, want %s", wait, want/2)
+		}
+		clock.Advance(want / 2)
+		if wait := l.retryAfter("coach", "10.0.0.1"); wait != 0 {
+			t.Fatalf("after waiting: wait %s, want 0", wait)
+		}
+	}
+
+	// Other usernames from another address are unaffected.
+	if wait := l.retryAfter("assistant", "10.0.0.2"); wait != 0 {
+		t.Errorf("other user: wait %s, want 0", wait)
+	}
+}
+
+func TestLoginLimiterBackoffCap(t *testing.T) {
+	clock := newTestClock()
+	l := newTestLimiter(clock)
+	l.username.lockoutAfter = 0 // backoff only
+
+	for i := 0; i < 20; i++ {
+		l.failure("coach", "10.0.0.1")
+	}
+	if wait := l.retryAfter("coach", "10.0.0.1"); wait != usernameLimitPolicy.maxDelay {
+		t.Errorf("wait %s, want %s", wait, usernameLimitPolicy.maxDelay)
+	}
+}
+
+func TestLoginLimiterLockout(t *testing.T) {
+	clock := newTestClock()
+	l := newTestLimiter(clock)
+
+	for i := 0; i < usernameLimitPolicy.lockoutAfter; i++ {
+		l.failure("coach", "10.0.0.1")
+		clock.Advance(time.Second)
+	}
+	until, locked := l.lockedUntil("Coach ")
+	if !locked {
+		t.Fatal("not locked out after lockoutAfter failures")
+	}
+	if want := clock.Now().Add(usernameLimitPolicy.lockoutFor - time.Second); !until.Equal(want) {
+		t.Errorf("locked until %s, want %s", until, want)
+	}
+	// A different address doesn't get around a username lockout.
+	if wait := l.retryAfter("coach", "10.0.0.9"); wait != usernameLimitPolicy.lockoutFor-time.Second {
+		t.Errorf("wait %s, want %s", wait, usernameLimitPolicy.lockoutFor-time.Second)
+	}
+
+	clock.Advance(usernameLimitPolicy.lockoutFor)
+	if _, locked := l.lockedUntil("coach"); locked {
+		t.Error("still locked out after lockoutFor")
+	}
+	// The lockout cleared the count, so the next failure is free again.
+	l.failure("coach", "10.0.0.9")
+	if wait := l.retryAfter("coach", "10.0.0.9"); wait != 0 {
+		t.Errorf("after lockout: wait %s, want 0", wait)
+	}
+}
+
+func TestLoginLimiterReset(t *testing.T) {
+	clock := newTestClock()
+	l := newTestLimiter(clock)
+
+	for i := 0; i < usernameLimitPolicy.freeAttempts+2; i++ {
+		l.failure("coach", "10.0.0.1")
+	}
+	if wait := l.retryAfter("coach", "10.0.0.1"); wait == 0 {
+		t.Fatal("expected a wait after repeated failures")
+	}
+
+	clock.Advance(usernameLimitPolicy.resetAfter)
+	l.failure("coach", "10.0.0.1")
+	if wait := l.retryAfter("coach", "10.0.0.1"); wait != 0 {
+		t.Errorf("failures were not forgotten after resetAfter: wait %s", wait)
+	}
+}
+
+func TestLoginLimiterUnlock(t *testing.T) {
+	clock := newTestClock()
+	l := newTestLimiter(clock)
+
+	for i := 0; i < usernameLimitPolicy.lockoutAfter; i++ {
+		l.failure("coach", "10.0.0.1")
+	}
+	if _, locked := l.lockedUntil("coach"); !locked {
+		t.Fatal("not locked out")
+	}
+
+	l.unlock("coach")
+	if _, locked := l.lockedUntil("coach"); locked {
+		t.Error("still locked out after unlock")
+	}
+	if wait := l.retryAfter("coach", "10.0.0.2"); wait != 0 {
+		t.Errorf("after unlock: wait %s, want 0", wait)
+	}
+}
+
+func TestLoginLimiterSuccessKeepsIPFailures(t *testing.T) {
+	clock := newTestClock()
+	l := newTestLimiter(clock)
+
+	// Spraying one password across many usernames from one address.
+	for i := 0; i <= ipLimitPolicy.freeAttempts; i++ {
+		l.failure("user"+string(rune('a'+i)), "10.0.0.1")
+	}
+	l.success("coach")
+	if wait := l.retryAfter("coach", "10.0.0.1"); wait != ipLimitPolicy.baseDelay {
+		t.Errorf("wait %s, want %s from the IP limit", wait, ipLimitPolicy.baseDelay)
+	}
+}
+
+func TestLoginLimiterReserve(t *testing.T) {
+	clock := newTestClock()
+	l := newTestLimiter(clock)
+
+	for i := 0; i < usernameLimitPolicy.freeAttempts; i++ {
+		l.failure("coach", "10.0.0.1")
+	}
+
+	// The last free attempt is let through; a second one alongside it has to
+	// wait as though the first had already failed.
+	first, wait := l.reserve("coach", "10.0.0.1")
+	if first == nil {
+		t.Fatalf("first attempt refused, wait %s", wait)
+	}
+	if second, wait := l.reserve("coach", "10.0.0.2"); second != nil || wait != usernameLimitPolicy.baseDelay {
+		t.Errorf("second attempt: got %v, wait %s, want refused for %s", second, wait, usernameLimitPolicy.baseDelay)
+	}
+
+	// Releasing the attempt gives the slot back without counting a failure.
+	first.release()
+	first.failure()
+	again, wait := l.reserve("coach", "10.0.0.2")
+	if again == nil {
+		t.Fatalf("after release: refused, wait %s", wait)
+	}
+	again.failure()
+	if wait := l.retryAfter("coach", "10.0.0.2"); wait != usernameLimitPolicy.baseDelay {
+		t.Errorf("after failure: wait %s, want %s", wait, usernameLimitPolicy.baseDelay)
+	}
+}
+
+func TestLoginLimiterReserveConcurrent(t *testing.T) {
+	clock := newTestClock()
+	l := newTestLimiter(clock)
+	for i := 0; i < usernameLimitPolicy.freeAttempts; i++ {
+		l.failure("coach", "10.0.0.1")
+	}
+
+	var wg sync.WaitGroup
+	var reserved atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if attempt, _ := l.reserve("coach", "10.0.0.1"); attempt != nil {
+				reserved.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := reserved.Load(); n != 1 {
+		t.Errorf("%d concurrent attempts let through, want 1", n)
+	}
+}
+
+func TestLoginLimiterSuccessReleasesIP(t *testing.T) {
+	clock := newTestClock()
+	l := newTestLimiter(clock)
+
+	for i := 0; i <= ipLimitPolicy.freeAttempts; i++ {
+		attempt, wait := l.reserve("coach", "10.0.0.1")
+		if attempt == nil {
+			t.Fatalf("login %d refused, wait %s", i+1, wait)
+		}
+		attempt.success()
+		attempt.release()
+	}
+	if wait := l.retryAfter("assistant", "10.0.0.1"); wait != 0 {
+		t.Errorf("after successful logins: wait %s, want 0", wait)
+	}
+}
+
+func TestMemoryAttemptStoreExpiry(t *testing.T) {
+	clock := newTestClock()
+	s := newMemoryAttemptStore(clock.Now)
+
+	s.Set("user:coach", loginAttempts{Failures: 2}, time.Minute)
+	if a, ok := s.Get("user:coach"); !ok || a.Failures != 2 {
+		t.Fatalf("Get = %+v, %v", a, ok)
+	}
+
+	clock.Advance(time.Minute + time.Second)
+	if _, ok := s.Get("user:coach"); ok {
+		t.Error("entry still present after its ttl")
+	}
+
+	// The next Set sweeps expired entries out of the map.
+	s.Set("user:other", loginAttempts{Failures: 1}, time.Minute)
+	if _, ok := s.entries["user:coach"]; ok {
+		t.Error("expired entry was not swept")
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =====================
+// MAIL
+// =====================
+
+type mailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// mailer delivers plain-text email. smtpMailer is used in production;
+// fileMailer and memoryMailer keep mail local for development and tests.
+type mailer interface {
+	Send(ctx context.Context, msg mailMessage) error
+}
+
+type mailConfig struct {
+	From         string `yaml:"from" toml:"from"`
+	Dir          string `yaml:"dir" toml:"dir"`
+	SMTPHost     string `yaml:"smtp_host" toml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port" toml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username" toml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password" toml:"smtp_password"`
+}
+
+// newMailer sends through SMTP when a host is configured, and otherwise
+// writes .eml files to the mail directory. now dates the messages.
+func newMailer(cfg mailConfig, now func() time.Time, logger *slog.Logger) mailer {
+	if cfg.SMTPHost != "" {
+		return &smtpMailer{
+			addr:     net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+			host:     cfg.SMTPHost,
+			username: cfg.SMTPUsername,
+			password: cfg.SMTPPassword,
+			from:     cfg.From,
+			now:      now,
+		}
+	}
+	logger.Info("no SMTP host is configured; email will be written to files", "dir", cfg.Dir)
+	return &fileMailer{dir: cfg.Dir, from: cfg.From, now: now, log: logger}
+}
+
+// formatMessage renders msg as an RFC 5322 message.
+func formatMessage(from string, msg mailMessage, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// smtpTimeout bounds a whole SMTP conversation, so a stalled mail server
+// can't hold a request or goroutine open indefinitely.
+const smtpTimeout = 30 * time.Second
+
+// smtpMailer sends through an SMTP server, upgrading to TLS when the server
+// offers STARTTLS.
+type smtpMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	now      func() time.Time
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg mailMessage) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	envelopeFrom := m.from
+	if start, end := strings.Index(m.from, "<"), strings.Index(m.from, ">"); start >= 0 && end > start {
+		envelopeFrom = m.from[start+1 : end]
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	// Cancelling ctx closes the connection, which unblocks any read or write.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(envelopeFrom); err != nil {
+		return err
+	}
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(formatMessage(m.from, msg, m.now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// fileMailer writes each message to its own .eml file, which most mail
+// clients can open.
+type fileMailer struct {
+	dir  string
+	from string
+	now  func() time.Time
+	log  *slog.Logger
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg mailMessage) error {
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return err
+	}
+	now := m.now()
+	name := fmt.Sprintf("%s-%d.eml", now.Format("20060102-150405"), now.UnixNano()%1e9)
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, formatMessage(m.from, msg, now), 0o600); err != nil {
+		return err
+	}
+	m.log.Info("wrote email", "subject", msg.Subject, "to", msg.To, "path", path)
+	return nil
+}
+
+// memoryMailer keeps sent messages in memory.
+type memoryMailer struct {
+	mu       sync.Mutex
+	messages []mailMessage
+}
+
+func (m *memoryMailer) Send(ctx context.Context, msg mailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Sent returns a copy of the messages sent so far.
+func (m *memoryMailer) Sent() []mailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mailMessage(nil), m.messages...)
+}
@@ -1,81 +1,117 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"log/slog"
+	"math"
 	"net/http"
+	"os"
+	"os/signal"
+	"sort"
 	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"jones-county-xc/backend/db"
 
 	"github.com/gin-gonic/gin"
-	_ "github.com/go-sql-driver/mysql"
 )
 
-var queries *db.Queries
-var dbConn *sql.DB
-
 func main() {
-	// Connect to MySQL
-	conn, err := sql.Open("mysql", "xcapp:xcapp123@tcp(127.0.0.1:3306)/jones_county_xc?parseTime=true")
+	cfg, args, err := loadConfig(os.Args[1:], os.Getenv)
+	if len(args) > 0 && args[0] == "config" {
+		if err := configCommand(cfg, err, args[1:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		log.Fatal(err)
 	}
-	defer conn.Close()
+	slog.SetLogLoggerLevel(logLevels[cfg.LogLevel])
+	logger := slog.Default()
 
-	// Verify connection
-	if err := conn.Ping(); err != nil {
-		log.Fatal("Failed to ping database:", err)
+	conn, err := openDatabase(cfg.Driver, cfg.DSN, logger)
+	if err != nil {
+		log.Fatal(err)
 	}
-	log.Println("Connected to MySQL database")
-
-	// Initialize queries
-	queries = db.New(conn)
-	dbConn = conn
-
-	// Setup router
-	r := gin.Default()
-
-	r.GET("/health", healthHandler)
-
-	// Event Types CRUD
-	r.GET("/api/event-types", getEventTypesHandler)
-	r.GET("/api/event-types/:id", getEventTypeByIDHandler)
-	r.POST("/api/event-types", createEventTypeHandler)
-	r.PUT("/api/event-types/:id", updateEventTypeHandler)
-	r.DELETE("/api/event-types/:id", deleteEventTypeHandler)
+	defer conn.Close()
 
-	// Athletes CRUD
-	r.GET("/api/athletes", getAthletesHandler)
-	r.GET("/api/athletes/:id", getAthleteByIDHandler)
-	r.GET("/api/athletes/:id/results", getAthleteResultsHandler)
-	r.POST("/api/athletes", createAthleteHandler)
-	r.PUT("/api/athletes/:id", updateAthleteHandler)
-	r.DELETE("/api/athletes/:id", deleteAthleteHandler)
+	// Subcommands such as create-admin run against the database and exit
+	// instead of starting the server.
+	if len(args) > 0 {
+		if err := runCommand(cfg, conn, args[0], args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	// Meets CRUD
-	r.GET("/api/meets", getMeetsHandler)
-	r.GET("/api/meets/:id", getMeetByIDHandler)
-	r.GET("/api/meets/:id/results", getResultsByMeetHandler)
-	r.POST("/api/meets", createMeetHandler)
-	r.PUT("/api/meets/:id", updateMeetHandler)
-	r.DELETE("/api/meets/:id", deleteMeetHandler)
+	migrations, err := loadMigrations(cfg.Driver)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// An in-memory database starts empty every time, so it is migrated here
+	// instead of with `migrate up`.
+	if cfg.Driver == driverSQLite && strings.HasPrefix(cfg.DSN, ":memory:") {
+		if err := migrateUp(context.Background(), conn, migrations, 0, log.Writer()); err != nil {
+			log.Fatal(err)
+		}
+	}
+	repo := newSQLRepository(conn, migrations)
+	if err := repo.CheckSchema(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 
-	// Results CRUD
-	r.GET("/api/results", getResultsHandler)
-	r.GET("/api/results/all", getAllResultsHandler)
-	r.GET("/api/results/top10", getTopTenFastestHandler)
-	r.POST("/api/results", createResultHandler)
-	r.PUT("/api/results/:id", updateResultHandler)
-	r.DELETE("/api/results/:id", deleteResultHandler)
+	srv := newServer(cfg, repo, logger)
+	go srv.pruneSessions(time.Hour)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Once shutdown starts, a second signal kills the process at once.
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if err := srv.serve(ctx, newHTTPServer(cfg, srv.newRouter())); err != nil {
+		log.Fatal(err)
+	}
+}
 
-	// Auth
-	r.POST("/api/login", loginHandler)
+// =====================
+// HEALTH PROBES
+// =====================
 
-	r.Run(":8080")
+// livezHandler answers as long as the process can serve requests at all. It
+// doesn't touch the database, so an outage there doesn't get the process
+// restarted.
+func (srv *server) livezHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
-func healthHandler(c *gin.Context) {
+// readyzHandler tells the load balancer whether to send traffic here: the
+// database must answer and have the schema this build expects, and the server
+// must not be shutting down.
+func (srv *server) readyzHandler(c *gin.Context) {
+	if srv.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": "shutting down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyzTimeout)
+	defer cancel()
+	if err := srv.repo.Ping(ctx); err != nil {
+		srv.log.Warn("readiness check failed", "check", "database", "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": "database unreachable"})
+		return
+	}
+	if err := srv.repo.CheckSchema(ctx); err != nil {
+		srv.log.Warn("readiness check failed", "check", "migrations", "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": "database schema mismatch"})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
@@ -83,8 +119,17 @@ func healthHandler(c *gin.Context) {
 // EVENT TYPES HANDLERS
 // =====================
 
-func getEventTypesHandler(c *gin.Context) {
-	eventTypes, err := queries.GetAllEventTypes(c.Request.Context())
+func eventTypeResponse(et db.EventType) gin.H {
+	return gin.H{
+		"id":          et.ID,
+		"name":        et.Name,
+		"distance":    et.Distance.String,
+		"description": et.Description.String,
+	}
+}
+
+func (srv *server) getEventTypesHandler(c *gin.Context) {
+	eventTypes, err := srv.repo.GetAllEventTypes(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -92,24 +137,19 @@ func getEventTypesHandler(c *gin.Context) {
 
 	result := make([]gin.H, len(eventTypes))
 	for i, et := range eventTypes {
-		result[i] = gin.H{
-			"id":          et.ID,
-			"name":        et.Name,
-			"distance":    et.Distance.String,
-			"description": et.Description.String,
-		}
+		result[i] = eventTypeResponse(et)
 	}
 	c.JSON(http.StatusOK, result)
 }
 
-func getEventTypeByIDHandler(c *gin.Context) {
+func (srv *server) getEventTypeByIDHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event type ID"})
 		return
 	}
 
-	et, err := queries.GetEventTypeByID(c.Request.Context(), int32(id))
+	et, err := srv.repo.GetEventTypeByID(c.Request.Context(), int32(id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Event type not found"})
@@ -119,12 +159,7 @@ func getEventTypeByIDHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"id":          et.ID,
-		"name":        et.Name,
-		"distance":    et.Distance.String,
-		"description": et.Description.String,
-	})
+	c.JSON(http.StatusOK, eventTypeResponse(et))
 }
 
 type EventTypeRequest struct {
@@ -133,33 +168,53 @@ type EventTypeRequest struct {
 	Description string `json:"description"`
 }
 
-func createEventTypeHandler(c *gin.Context) {
+func (srv *server) createEventTypeHandler(c *gin.Context) {
 	var req EventTypeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	result, err := queries.CreateEventType(c.Request.Context(), db.CreateEventTypeParams{
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.CreateEventType(c.Request.Context(), db.CreateEventTypeParams{
 		Name:        req.Name,
 		Distance:    sql.NullString{String: req.Distance, Valid: req.Distance != ""},
 		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
 	})
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "An event type with this name already exists"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	id, _ := result.LastInsertId()
-	c.JSON(http.StatusCreated, gin.H{
-		"id":          id,
-		"name":        req.Name,
-		"distance":    req.Distance,
-		"description": req.Description,
-	})
+	created, err := tx.GetEventTypeByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditEventType, created.ID, nil, eventTypeResponse(created)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, eventTypeResponse(created))
 }
 
-func updateEventTypeHandler(c *gin.Context) {
+func (srv *server) updateEventTypeHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event type ID"})
@@ -172,37 +227,102 @@ func updateEventTypeHandler(c *gin.Context) {
 		return
 	}
 
-	err = queries.UpdateEventType(c.Request.Context(), db.UpdateEventTypeParams{
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := tx.GetEventTypeByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event type not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = tx.UpdateEventType(c.Request.Context(), db.UpdateEventTypeParams{
 		ID:          int32(id),
 		Name:        req.Name,
 		Distance:    sql.NullString{String: req.Distance, Valid: req.Distance != ""},
 		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
 	})
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "An event type with this name already exists"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"id":          id,
-		"name":        req.Name,
-		"distance":    req.Distance,
-		"description": req.Description,
-	})
+	after, err := tx.GetEventTypeByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditEventType, after.ID, eventTypeResponse(before), eventTypeResponse(after)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, eventTypeResponse(after))
 }
 
-func deleteEventTypeHandler(c *gin.Context) {
+func (srv *server) deleteEventTypeHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event type ID"})
 		return
 	}
 
-	err = queries.DeleteEventType(c.Request.Context(), int32(id))
+	tx, err := srv.repo.Begin(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	defer tx.Rollback()
+
+	before, err := tx.GetEventTypeByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event type not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Results in this event stay, without an event type.
+	results, err := tx.GetResultsForEventType(c.Request.Context(), sql.NullInt32{Int32: before.ID, Valid: true})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordResultsDetached(c, tx, results); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.DeleteEventType(c.Request.Context(), int32(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditEventType, before.ID, eventTypeResponse(before), nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Event type deleted"})
 }
@@ -211,34 +331,49 @@ func deleteEventTypeHandler(c *gin.Context) {
 // ATHLETES HANDLERS
 // =====================
 
-func getAthletesHandler(c *gin.Context) {
-	athletes, err := queries.GetAllAthletes(c.Request.Context())
+func athleteResponse(a db.Athlete) gin.H {
+	return gin.H{
+		"id":              a.ID,
+		"name":            a.Name,
+		"grade":           a.Grade,
+		"division":        a.Division.String,
+		"personalRecord":  a.PersonalRecord.String,
+		"events":          a.Events.String,
+		"nameVisibility":  a.NameVisibility,
+		"parentalConsent": a.ParentalConsent,
+	}
+}
+
+func (srv *server) getAthletesHandler(c *gin.Context) {
+	athletes, err := srv.repo.GetAllAthletes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	names, err := srv.loadAthleteNames(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	result := make([]gin.H, len(athletes))
-	for i, a := range athletes {
-		result[i] = gin.H{
-			"id":             a.ID,
-			"name":           a.Name,
-			"grade":          a.Grade,
-			"personalRecord": a.PersonalRecord.String,
-			"events":         a.Events.String,
+	result := make([]gin.H, 0, len(athletes))
+	for _, a := range athletes {
+		if names.hidden(a.ID) {
+			continue
 		}
+		result = append(result, names.athlete(a))
 	}
 	c.JSON(http.StatusOK, result)
 }
 
-func getAthleteByIDHandler(c *gin.Context) {
+func (srv *server) getAthleteByIDHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid athlete ID"})
 		return
 	}
 
-	athlete, err := queries.GetAthleteByID(c.Request.Context(), int32(id))
+	athlete, err := srv.repo.GetAthleteByID(c.Request.Context(), int32(id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Athlete not found"})
@@ -247,24 +382,41 @@ func getAthleteByIDHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	names, err := srv.loadAthleteNames(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkAthleteVisible(c, names, athlete.ID) {
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"id":             athlete.ID,
-		"name":           athlete.Name,
-		"grade":          athlete.Grade,
-		"personalRecord": athlete.PersonalRecord.String,
-		"events":         athlete.Events.String,
-	})
+	c.JSON(http.StatusOK, names.athlete(athlete))
 }
 
-func getAthleteResultsHandler(c *gin.Context) {
+func (srv *server) getAthleteResultsHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid athlete ID"})
 		return
 	}
 
-	results, err := queries.GetAthleteResults(c.Request.Context(), int32(id))
+	names, err := srv.loadAthleteNames(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkAthleteVisible(c, names, int32(id)) {
+		return
+	}
+
+	results, err := srv.repo.GetAthleteResults(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	conditions, err := srv.loadConditions(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -273,15 +425,18 @@ func getAthleteResultsHandler(c *gin.Context) {
 	response := make([]gin.H, len(results))
 	for i, r := range results {
 		response[i] = gin.H{
-			"id":          r.ID,
-			"athleteId":   r.AthleteID,
-			"meetId":      r.MeetID,
-			"eventTypeId": r.EventTypeID.Int32,
-			"event":       r.EventName.String,
-			"time":        r.Time,
-			"place":       r.Place.Int32,
-			"meetName":    r.MeetName,
-			"meetDate":    r.MeetDate.Format("January 2, 2006"),
+			"id":           r.ID,
+			"athleteId":    r.AthleteID,
+			"meetId":       r.MeetID,
+			"eventTypeId":  r.EventTypeID.Int32,
+			"event":        r.EventName.String,
+			"time":         r.Time,
+			"adjustedTime": adjustedRaceTime(r.Time, r.CourseFactor),
+			"courseFactor": courseFactorValue(r.CourseFactor),
+			"place":        r.Place.Int32,
+			"meetName":     r.MeetName,
+			"meetDate":     r.MeetDate.Format("January 2, 2006"),
+			"conditions":   conditions.forResult(r.MeetID, r.EventTypeID),
 		}
 	}
 	c.JSON(http.StatusOK, response)
@@ -290,20 +445,29 @@ func getAthleteResultsHandler(c *gin.Context) {
 type AthleteRequest struct {
 	Name           string `json:"name" binding:"required"`
 	Grade          int32  `json:"grade" binding:"required"`
+	Division       string `json:"division" binding:"omitempty,oneof=boys girls"`
 	PersonalRecord string `json:"personalRecord"`
 	Events         string `json:"events"`
 }
 
-func createAthleteHandler(c *gin.Context) {
+func (srv *server) createAthleteHandler(c *gin.Context) {
 	var req AthleteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	result, err := queries.CreateAthlete(c.Request.Context(), db.CreateAthleteParams{
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.CreateAthlete(c.Request.Context(), db.CreateAthleteParams{
 		Name:           req.Name,
 		Grade:          req.Grade,
+		Division:       sql.NullString{String: req.Division, Valid: req.Division != ""},
 		PersonalRecord: sql.NullString{String: req.PersonalRecord, Valid: req.PersonalRecord != ""},
 		Events:         sql.NullString{String: req.Events, Valid: req.Events != ""},
 	})
@@ -313,16 +477,24 @@ func createAthleteHandler(c *gin.Context) {
 	}
 
 	id, _ := result.LastInsertId()
-	c.JSON(http.StatusCreated, gin.H{
-		"id":             id,
-		"name":           req.Name,
-		"grade":          req.Grade,
-		"personalRecord": req.PersonalRecord,
-		"events":         req.Events,
-	})
+	created, err := tx.GetAthleteByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditAthlete, created.ID, nil, athleteResponse(created)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, athleteResponse(created))
 }
 
-func updateAthleteHandler(c *gin.Context) {
+func (srv *server) updateAthleteHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid athlete ID"})
@@ -335,10 +507,28 @@ func updateAthleteHandler(c *gin.Context) {
 		return
 	}
 
-	err = queries.UpdateAthlete(c.Request.Context(), db.UpdateAthleteParams{
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := tx.GetAthleteByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Athlete not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = tx.UpdateAthlete(c.Request.Context(), db.UpdateAthleteParams{
 		ID:             int32(id),
 		Name:           req.Name,
 		Grade:          req.Grade,
+		Division:       sql.NullString{String: req.Division, Valid: req.Division != ""},
 		PersonalRecord: sql.NullString{String: req.PersonalRecord, Valid: req.PersonalRecord != ""},
 		Events:         sql.NullString{String: req.Events, Valid: req.Events != ""},
 	})
@@ -347,24 +537,67 @@ func updateAthleteHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"id":             id,
-		"name":           req.Name,
-		"grade":          req.Grade,
-		"personalRecord": req.PersonalRecord,
-		"events":         req.Events,
-	})
+	after, err := tx.GetAthleteByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditAthlete, after.ID, athleteResponse(before), athleteResponse(after)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, athleteResponse(after))
 }
 
-func deleteAthleteHandler(c *gin.Context) {
+func (srv *server) deleteAthleteHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid athlete ID"})
 		return
 	}
 
-	err = queries.DeleteAthlete(c.Request.Context(), int32(id))
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := tx.GetAthleteByID(c.Request.Context(), int32(id))
 	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Athlete not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The athlete's results go with them.
+	results, err := tx.GetResultsForAthlete(c.Request.Context(), before.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordResultDeletes(c, tx, results); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.DeleteAthlete(c.Request.Context(), before.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditAthlete, before.ID, athleteResponse(before), nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -376,35 +609,52 @@ func deleteAthleteHandler(c *gin.Context) {
 // MEETS HANDLERS
 // =====================
 
-func getMeetsHandler(c *gin.Context) {
-	meets, err := queries.GetAllMeets(c.Request.Context())
+func meetResponse(m db.Meet) gin.H {
+	return gin.H{
+		"id":           m.ID,
+		"name":         m.Name,
+		"date":         m.Date.Format("2006-01-02"),
+		"time":         m.Time.String,
+		"location":     m.Location.String,
+		"description":  m.Description.String,
+		"courseId":     m.CourseID.Int32,
+		"conditions":   meetConditions(m),
+		"status":       m.Status,
+		"postponed":    m.Status == meetPostponed,
+		"originalDate": formatNullDate(m.OriginalDate),
+		"official":     m.Official,
+		"championship": m.Championship,
+	}
+}
+
+// getMeetsHandler hides cancelled meets unless includeCancelled=true is
+// passed, as the admin page does.
+func (srv *server) getMeetsHandler(c *gin.Context) {
+	meets, err := srv.repo.GetAllMeets(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	result := make([]gin.H, len(meets))
-	for i, m := range meets {
-		result[i] = gin.H{
-			"id":          m.ID,
-			"name":        m.Name,
-			"date":        m.Date.Format("2006-01-02"),
-			"time":        m.Time.String,
-			"location":    m.Location.String,
-			"description": m.Description.String,
+	includeCancelled := c.Query("includeCancelled") == "true"
+	result := make([]gin.H, 0, len(meets))
+	for _, m := range meets {
+		if m.Status == meetCancelled && !includeCancelled {
+			continue
 		}
+		result = append(result, meetResponse(m))
 	}
 	c.JSON(http.StatusOK, result)
 }
 
-func getMeetByIDHandler(c *gin.Context) {
+func (srv *server) getMeetByIDHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
 		return
 	}
 
-	meet, err := queries.GetMeetByID(c.Request.Context(), int32(id))
+	meet, err := srv.repo.GetMeetByID(c.Request.Context(), int32(id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Meet not found"})
@@ -414,41 +664,51 @@ func getMeetByIDHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"id":          meet.ID,
-		"name":        meet.Name,
-		"date":        meet.Date.Format("2006-01-02"),
-		"time":        meet.Time.String,
-		"location":    meet.Location.String,
-		"description": meet.Description.String,
-	})
+	c.JSON(http.StatusOK, meetResponse(meet))
 }
 
-func getResultsByMeetHandler(c *gin.Context) {
+func (srv *server) getResultsByMeetHandler(c *gin.Context) {
 	meetID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
 		return
 	}
 
-	results, err := queries.GetMeetResults(c.Request.Context(), int32(meetID))
+	results, err := srv.repo.GetMeetResults(c.Request.Context(), int32(meetID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	response := make([]gin.H, len(results))
-	for i, r := range results {
-		response[i] = gin.H{
-			"id":          r.ID,
-			"athleteId":   r.AthleteID,
-			"athleteName": r.AthleteName,
-			"meetId":      r.MeetID,
-			"eventTypeId": r.EventTypeID.Int32,
-			"event":       r.EventName.String,
-			"time":        r.Time,
-			"place":       r.Place.Int32,
+	conditions, err := srv.loadMeetConditions(c.Request.Context(), int32(meetID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	names, err := srv.loadAthleteNames(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]gin.H, 0, len(results))
+	for _, r := range results {
+		if names.hidden(r.AthleteID) {
+			continue
 		}
+		response = append(response, gin.H{
+			"id":           r.ID,
+			"athleteId":    r.AthleteID,
+			"athleteName":  names.name(r.AthleteID, r.AthleteName),
+			"meetId":       r.MeetID,
+			"eventTypeId":  r.EventTypeID.Int32,
+			"event":        r.EventName.String,
+			"time":         r.Time,
+			"adjustedTime": adjustedRaceTime(r.Time, r.CourseFactor),
+			"courseFactor": courseFactorValue(r.CourseFactor),
+			"place":        r.Place.Int32,
+			"conditions":   conditions.forResult(r.MeetID, r.EventTypeID),
+		})
 	}
 	c.JSON(http.StatusOK, response)
 }
@@ -459,35 +719,75 @@ type MeetRequest struct {
 	Time        string `json:"time"`
 	Location    string `json:"location"`
 	Description string `json:"description"`
+	CourseID    int32  `json:"courseId"`
+
+	Championship *bool              `json:"championship"`
+	Conditions   *ConditionsRequest `json:"conditions"`
 }
 
-func createMeetHandler(c *gin.Context) {
+func (srv *server) createMeetHandler(c *gin.Context) {
 	var req MeetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	result, err := dbConn.ExecContext(c.Request.Context(),
-		"INSERT INTO meets (name, date, time, location, description) VALUES (?, ?, ?, ?, ?)",
-		req.Name, req.Date, req.Time, req.Location, req.Description)
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	conditions := req.Conditions
+	if conditions == nil {
+		conditions = &ConditionsRequest{}
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.CreateMeet(c.Request.Context(), db.CreateMeetParams{
+		Name:          req.Name,
+		Date:          date,
+		Time:          sql.NullString{String: req.Time, Valid: req.Time != ""},
+		Location:      sql.NullString{String: req.Location, Valid: req.Location != ""},
+		Description:   sql.NullString{String: req.Description, Valid: req.Description != ""},
+		CourseID:      sql.NullInt32{Int32: req.CourseID, Valid: req.CourseID > 0},
+		TemperatureF:  nullInt32(conditions.TemperatureF),
+		HumidityPct:   nullInt32(conditions.HumidityPct),
+		WindMph:       nullInt32(conditions.WindMph),
+		Precipitation: sql.NullString{String: conditions.Precipitation, Valid: conditions.Precipitation != ""},
+		Footing:       sql.NullString{String: conditions.Footing, Valid: conditions.Footing != ""},
+		Championship:  req.Championship != nil && *req.Championship,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	id, _ := result.LastInsertId()
-	c.JSON(http.StatusCreated, gin.H{
-		"id":          id,
-		"name":        req.Name,
-		"date":        req.Date,
-		"time":        req.Time,
-		"location":    req.Location,
-		"description": req.Description,
-	})
+	created, err := tx.GetMeetByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditMeet, created.ID, nil, meetResponse(created)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, meetResponse(created))
 }
 
-func updateMeetHandler(c *gin.Context) {
+func (srv *server) updateMeetHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
@@ -500,36 +800,146 @@ func updateMeetHandler(c *gin.Context) {
 		return
 	}
 
-	_, err = dbConn.ExecContext(c.Request.Context(),
-		"UPDATE meets SET name = ?, date = ?, time = ?, location = ?, description = ? WHERE id = ?",
-		req.Name, req.Date, req.Time, req.Location, req.Description, id)
+	newDate, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	defer tx.Rollback()
 
-	c.JSON(http.StatusOK, gin.H{
-		"id":          id,
-		"name":        req.Name,
-		"date":        req.Date,
-		"time":        req.Time,
-		"location":    req.Location,
-		"description": req.Description,
+	meet, err := tx.GetMeetByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Meet not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Moving a meet that has started or finished would misdate its results,
+	// so a date edit is held to the same rule as a reschedule.
+	dateChanged := !newDate.Equal(meet.Date)
+	if dateChanged && meet.Status != meetScheduled && meet.Status != meetPostponed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only scheduled or postponed meets can be rescheduled"})
+		return
+	}
+
+	if dateChanged {
+		if err := recordMeetDateChange(c.Request.Context(), tx, meet, newDate, meet.Status, "Date edited"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	err = tx.UpdateMeet(c.Request.Context(), db.UpdateMeetParams{
+		ID:          meet.ID,
+		Name:        req.Name,
+		Date:        newDate,
+		Time:        sql.NullString{String: req.Time, Valid: req.Time != ""},
+		Location:    sql.NullString{String: req.Location, Valid: req.Location != ""},
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		CourseID:    sql.NullInt32{Int32: req.CourseID, Valid: req.CourseID > 0},
 	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Conditions and the championship flag are only overwritten when the
+	// request includes them, so edits that don't know about them leave them
+	// intact.
+	if cond := req.Conditions; cond != nil {
+		err = tx.UpdateMeetConditions(c.Request.Context(), db.UpdateMeetConditionsParams{
+			ID:            meet.ID,
+			TemperatureF:  nullInt32(cond.TemperatureF),
+			HumidityPct:   nullInt32(cond.HumidityPct),
+			WindMph:       nullInt32(cond.WindMph),
+			Precipitation: sql.NullString{String: cond.Precipitation, Valid: cond.Precipitation != ""},
+			Footing:       sql.NullString{String: cond.Footing, Valid: cond.Footing != ""},
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Championship != nil {
+		err = tx.SetMeetChampionship(c.Request.Context(), db.SetMeetChampionshipParams{
+			ID:           meet.ID,
+			Championship: *req.Championship,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	after, err := tx.GetMeetByID(c.Request.Context(), meet.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditMeet, meet.ID, meetResponse(meet), meetResponse(after)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, meetResponse(after))
 }
 
-func deleteMeetHandler(c *gin.Context) {
+func (srv *server) deleteMeetHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
 		return
 	}
 
-	err = queries.DeleteMeet(c.Request.Context(), int32(id))
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := tx.GetMeetByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Meet not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The meet's results go with it.
+	results, err := tx.GetResultsForMeet(c.Request.Context(), before.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if err := recordResultDeletes(c, tx, results); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.DeleteMeet(c.Request.Context(), before.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditMeet, before.ID, meetResponse(before), nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Meet deleted"})
 }
@@ -538,9 +948,14 @@ func deleteMeetHandler(c *gin.Context) {
 // RESULTS HANDLERS
 // =====================
 
-func getResultsHandler(c *gin.Context) {
+func (srv *server) getResultsHandler(c *gin.Context) {
 	// Get all meets
-	meets, err := queries.GetAllMeets(c.Request.Context())
+	meets, err := srv.repo.GetAllMeets(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	names, err := srv.loadAthleteNames(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -550,7 +965,7 @@ func getResultsHandler(c *gin.Context) {
 	response := make([]gin.H, 0)
 	for _, m := range meets {
 		// Get results for this meet
-		results, err := queries.GetMeetResults(c.Request.Context(), m.ID)
+		results, err := srv.repo.GetMeetResults(c.Request.Context(), m.ID)
 		if err != nil {
 			continue // Skip meets with no results
 		}
@@ -559,14 +974,17 @@ func getResultsHandler(c *gin.Context) {
 		}
 
 		// Build athletes array
-		athletes := make([]gin.H, len(results))
-		for i, r := range results {
-			athletes[i] = gin.H{
+		athletes := make([]gin.H, 0, len(results))
+		for _, r := range results {
+			if names.hidden(r.AthleteID) {
+				continue
+			}
+			athletes = append(athletes, gin.H{
 				"id":    r.AthleteID,
-				"name":  r.AthleteName,
+				"name":  names.name(r.AthleteID, r.AthleteName),
 				"time":  r.Time,
 				"event": r.EventName.String,
-			}
+			})
 		}
 
 		// Determine team placement (simplified - just use best individual place)
@@ -586,56 +1004,110 @@ func getResultsHandler(c *gin.Context) {
 		}
 
 		response = append(response, gin.H{
-			"id":        m.ID,
-			"meetName":  m.Name,
-			"date":      m.Date.Format("January 2, 2006"),
-			"placement": placement,
-			"athletes":  athletes,
+			"id":         m.ID,
+			"meetName":   m.Name,
+			"date":       m.Date.Format("January 2, 2006"),
+			"placement":  placement,
+			"athletes":   athletes,
+			"conditions": meetConditions(m),
 		})
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-func getAllResultsHandler(c *gin.Context) {
-	results, err := queries.GetAllResults(c.Request.Context())
+func (srv *server) getAllResultsHandler(c *gin.Context) {
+	results, err := srv.repo.GetAllResults(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	names, err := srv.loadAthleteNames(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	conditions, err := srv.loadConditions(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	response := make([]gin.H, len(results))
-	for i, r := range results {
-		response[i] = gin.H{
-			"id":          r.ID,
-			"athleteId":   r.AthleteID,
-			"athleteName": r.AthleteName,
-			"meetId":      r.MeetID,
-			"meetName":    r.MeetName,
-			"eventTypeId": r.EventTypeID.Int32,
-			"eventName":   r.EventName.String,
-			"time":        r.Time,
-			"place":       r.Place.Int32,
+	response := make([]gin.H, 0, len(results))
+	for _, r := range results {
+		if names.hidden(r.AthleteID) {
+			continue
 		}
+		response = append(response, gin.H{
+			"id":           r.ID,
+			"athleteId":    r.AthleteID,
+			"athleteName":  names.name(r.AthleteID, r.AthleteName),
+			"meetId":       r.MeetID,
+			"meetName":     r.MeetName,
+			"eventTypeId":  r.EventTypeID.Int32,
+			"eventName":    r.EventName.String,
+			"time":         r.Time,
+			"adjustedTime": adjustedRaceTime(r.Time, r.CourseFactor),
+			"courseFactor": courseFactorValue(r.CourseFactor),
+			"place":        r.Place.Int32,
+			"conditions":   conditions.forResult(r.MeetID, r.EventTypeID),
+		})
 	}
 	c.JSON(http.StatusOK, response)
 }
 
-func getTopTenFastestHandler(c *gin.Context) {
-	results, err := queries.GetTopTenFastestTimes(c.Request.Context())
+// getTopTenFastestHandler ranks results by time, or by course-neutral time
+// with ?adjusted=true. Athletes the viewer may not see are left out rather
+// than taking up places.
+func (srv *server) getTopTenFastestHandler(c *gin.Context) {
+	adjusted := c.Query("adjusted") == "true"
+
+	results, err := srv.repo.GetLeaderboardTimes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	names, err := srv.loadAthleteNames(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	response := make([]gin.H, len(results))
-	for i, r := range results {
+	type ranked struct {
+		row     db.GetLeaderboardTimesRow
+		seconds float64
+	}
+	rankedResults := make([]ranked, 0, len(results))
+	for _, r := range results {
+		if names.hidden(r.AthleteID) {
+			continue
+		}
+		seconds, err := parseRaceTime(r.Time)
+		if err != nil {
+			continue
+		}
+		if adjusted {
+			seconds /= courseFactorValue(r.CourseFactor)
+		}
+		rankedResults = append(rankedResults, ranked{row: r, seconds: seconds})
+	}
+	sort.SliceStable(rankedResults, func(i, j int) bool { return rankedResults[i].seconds < rankedResults[j].seconds })
+	if len(rankedResults) > 10 {
+		rankedResults = rankedResults[:10]
+	}
+
+	response := make([]gin.H, len(rankedResults))
+	for i, rr := range rankedResults {
+		r := rr.row
 		response[i] = gin.H{
 			"id":           r.ID,
 			"time":         r.Time,
+			"adjustedTime": adjustedRaceTime(r.Time, r.CourseFactor),
+			"courseFactor": courseFactorValue(r.CourseFactor),
 			"place":        r.Place.Int32,
 			"athleteId":    r.AthleteID,
-			"athleteName":  r.AthleteName,
+			"athleteName":  names.name(r.AthleteID, r.AthleteName),
 			"athleteGrade": r.AthleteGrade,
 			"meetId":       r.MeetID,
 			"meetName":     r.MeetName,
@@ -647,33 +1119,72 @@ func getTopTenFastestHandler(c *gin.Context) {
 }
 
 type ResultRequest struct {
-	AthleteID   int32 `json:"athleteId" binding:"required"`
-	MeetID      int32 `json:"meetId" binding:"required"`
-	EventTypeID int32 `json:"eventTypeId" binding:"required"`
+	AthleteID   int32  `json:"athleteId" binding:"required"`
+	MeetID      int32  `json:"meetId" binding:"required"`
+	EventTypeID int32  `json:"eventTypeId" binding:"required"`
 	Time        string `json:"time" binding:"required"`
 	Place       int32  `json:"place"`
 }
 
-func createResultHandler(c *gin.Context) {
+func (srv *server) createResultHandler(c *gin.Context) {
 	var req ResultRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	result, err := queries.CreateResult(c.Request.Context(), db.CreateResultParams{
+	if !srv.checkResultsOpen(c, req.MeetID) {
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.CreateResult(c.Request.Context(), db.CreateResultParams{
 		AthleteID:   req.AthleteID,
 		MeetID:      req.MeetID,
 		EventTypeID: sql.NullInt32{Int32: req.EventTypeID, Valid: req.EventTypeID > 0},
 		Time:        req.Time,
 		Place:       sql.NullInt32{Int32: req.Place, Valid: req.Place > 0},
 	})
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This athlete already has a result in this meet and event"})
+		return
+	}
+	if isForeignKeyViolation(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Athlete or event type not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	id, _ := result.LastInsertId()
+	created, err := tx.GetResultByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditResult, created.ID, nil, resultSnapshot(resultFromRow(created))); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	warnings, err := srv.qualificationWarnings(c.Request.Context(), req.MeetID, req.AthleteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"id":          id,
 		"athleteId":   req.AthleteID,
@@ -681,10 +1192,11 @@ func createResultHandler(c *gin.Context) {
 		"eventTypeId": req.EventTypeID,
 		"time":        req.Time,
 		"place":       req.Place,
+		"warnings":    warnings,
 	})
 }
 
-func updateResultHandler(c *gin.Context) {
+func (srv *server) updateResultHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid result ID"})
@@ -697,7 +1209,30 @@ func updateResultHandler(c *gin.Context) {
 		return
 	}
 
-	err = queries.UpdateResult(c.Request.Context(), db.UpdateResultParams{
+	existing, err := srv.repo.GetResultByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Result not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !srv.checkResultsOpen(c, existing.MeetID) {
+		return
+	}
+	if req.MeetID != existing.MeetID && !srv.checkResultsOpen(c, req.MeetID) {
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	err = tx.UpdateResult(c.Request.Context(), db.UpdateResultParams{
 		ID:          int32(id),
 		AthleteID:   req.AthleteID,
 		MeetID:      req.MeetID,
@@ -705,6 +1240,36 @@ func updateResultHandler(c *gin.Context) {
 		Time:        req.Time,
 		Place:       sql.NullInt32{Int32: req.Place, Valid: req.Place > 0},
 	})
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This athlete already has a result in this meet and event"})
+		return
+	}
+	if isForeignKeyViolation(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Athlete or event type not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	after, err := tx.GetResultByID(c.Request.Context(), existing.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	err = recordAudit(c, tx, auditResult, existing.ID,
+		resultSnapshot(resultFromRow(existing)), resultSnapshot(resultFromRow(after)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	warnings, err := srv.qualificationWarnings(c.Request.Context(), req.MeetID, req.AthleteID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -717,21 +1282,49 @@ func updateResultHandler(c *gin.Context) {
 		"eventTypeId": req.EventTypeID,
 		"time":        req.Time,
 		"place":       req.Place,
+		"warnings":    warnings,
 	})
 }
 
-func deleteResultHandler(c *gin.Context) {
+func (srv *server) deleteResultHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid result ID"})
 		return
 	}
 
-	err = queries.DeleteResult(c.Request.Context(), int32(id))
+	existing, err := srv.repo.GetResultByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Result not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !srv.checkResultsOpen(c, existing.MeetID) {
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	defer tx.Rollback()
+
+	if err := tx.DeleteResult(c.Request.Context(), existing.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditResult, existing.ID, resultSnapshot(resultFromRow(existing)), nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Result deleted"})
 }
@@ -745,21 +1338,26 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-func loginHandler(c *gin.Context) {
+// loginHandler checks a username and password. Users with two-factor
+// authentication get a challenge to complete at /api/login/2fa instead of a
+// session.
+func (srv *server) loginHandler(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var id int
-	var username string
-	err := dbConn.QueryRowContext(c.Request.Context(),
-		"SELECT id, username FROM users WHERE username = ? AND password = ?",
-		req.Username, req.Password).Scan(&id, &username)
+	attempt, ok := srv.reserveLogin(c, req.Username)
+	if !ok {
+		return
+	}
+	defer attempt.release()
 
+	user, err := srv.authenticate(c.Request.Context(), req.Username, req.Password)
 	if err != nil {
 		if err == sql.ErrNoRows {
+			attempt.failure()
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
 			return
 		}
@@ -767,9 +1365,61 @@ func loginHandler(c *gin.Context) {
 		return
 	}
 
+	enabled, err := srv.twoFactorEnabled(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if enabled {
+		// Failures are only cleared once the second step succeeds, so a known
+		// password can't be used to keep guessing codes.
+		challenge, expires := srv.newLoginChallenge(user.ID, srv.now())
+		c.JSON(http.StatusOK, gin.H{
+			"twoFactorRequired": true,
+			"challenge":         challenge,
+			"expiresAt":         expires,
+		})
+		return
+	}
+
+	attempt.success()
+	srv.completeLogin(c, user, twoFactorRequired(user.Role))
+}
+
+// reserveLogin reserves a login attempt for username from the client's IP.
+// It answers 429 and returns false while either is backing off after failed
+// logins. The caller must settle the attempt, deferring its release.
+func (srv *server) reserveLogin(c *gin.Context, username string) (*loginAttempt, bool) {
+	attempt, wait := srv.loginLimits.reserve(username, c.ClientIP())
+	if attempt != nil {
+		return attempt, true
+	}
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":      "Too many failed login attempts, try again later",
+		"retryAfter": seconds,
+	})
+	return nil, false
+}
+
+// completeLogin starts a session for a fully authenticated user. setupRequired
+// tells the client the user must enroll in two-factor authentication before
+// they can do anything else.
+func (srv *server) completeLogin(c *gin.Context, user db.User, setupRequired bool) {
+	token, expires, err := srv.startSession(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"success":  true,
-		"id":       id,
-		"username": username,
+		"success":                true,
+		"id":                     user.ID,
+		"username":               user.Username,
+		"role":                   user.Role,
+		"token":                  token,
+		"expiresAt":              expires,
+		"twoFactorSetupRequired": setupRequired,
 	})
 }
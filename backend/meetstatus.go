@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// MEET STATUS LIFECYCLE
+// =====================
+
+const (
+	meetScheduled  = "scheduled"
+	meetInProgress = "in_progress"
+	meetFinal      = "final"
+	meetPostponed  = "postponed"
+	meetCancelled  = "cancelled"
+)
+
+// meetTransitions lists the statuses a meet may move to from each status.
+// Postponed meets return to scheduled through a reschedule, and a final meet
+// can be reopened for corrections until its results are made official.
+var meetTransitions = map[string][]string{
+	meetScheduled:  {meetInProgress, meetPostponed, meetCancelled},
+	meetInProgress: {meetFinal, meetPostponed, meetCancelled},
+	meetFinal:      {meetInProgress},
+	meetPostponed:  {meetScheduled, meetCancelled},
+	meetCancelled:  {},
+}
+
+func canTransition(from, to string) bool {
+	return slices.Contains(meetTransitions[from], to)
+}
+
+// resultsOpen reports whether results may be entered or changed for a meet.
+func resultsOpen(m db.Meet) bool {
+	return (m.Status == meetInProgress || m.Status == meetFinal) && !m.Official
+}
+
+// checkResultsOpen aborts the request and returns false when results for the
+// meet cannot currently be entered, including by an API token limited to
+// another meet.
+func (srv *server) checkResultsOpen(c *gin.Context, meetID int32) bool {
+	if !checkTokenMeet(c, meetID) {
+		return false
+	}
+	meet, err := srv.repo.GetMeetByID(c.Request.Context(), meetID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Meet not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if meet.Official {
+		c.JSON(http.StatusConflict, gin.H{"error": "Meet results are official and can no longer be changed"})
+		return false
+	}
+	if !resultsOpen(meet) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Results can only be entered while a meet is in progress or final"})
+		return false
+	}
+	return true
+}
+
+func formatNullDate(t sql.NullTime) any {
+	if !t.Valid {
+		return nil
+	}
+	return t.Time.Format("2006-01-02")
+}
+
+type MeetStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=scheduled in_progress final postponed cancelled"`
+}
+
+func (srv *server) updateMeetStatusHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+
+	var req MeetStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	meet, err := srv.repo.GetMeetByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Meet not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !canTransition(meet.Status, req.Status) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Cannot change meet status from " + meet.Status + " to " + req.Status,
+			"allowed": meetTransitions[meet.Status],
+		})
+		return
+	}
+	if meet.Official {
+		c.JSON(http.StatusConflict, gin.H{"error": "Meet results are official and its status can no longer change"})
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	err = tx.UpdateMeetStatus(c.Request.Context(), db.UpdateMeetStatusParams{
+		ID:     meet.ID,
+		Status: req.Status,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	after, err := tx.GetMeetByID(c.Request.Context(), meet.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditMeet, meet.ID, meetResponse(meet), meetResponse(after)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, meetResponse(after))
+}
+
+type RescheduleRequest struct {
+	Date   string `json:"date" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+func (srv *server) rescheduleMeetHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+
+	var req RescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	newDate, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	meet, err := srv.repo.GetMeetByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Meet not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if meet.Status != meetScheduled && meet.Status != meetPostponed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only scheduled or postponed meets can be rescheduled"})
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := recordMeetDateChange(c.Request.Context(), tx, meet, newDate, meetScheduled, req.Reason); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	after, err := tx.GetMeetByID(c.Request.Context(), meet.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditMeet, meet.ID, meetResponse(meet), meetResponse(after)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, meetResponse(after))
+}
+
+// recordMeetDateChange moves a meet to a new date, remembering the date it was
+// originally scheduled for and appending to its date history.
+func recordMeetDateChange(ctx context.Context, q db.Querier, meet db.Meet, newDate time.Time, status, reason string) error {
+	err := q.RescheduleMeet(ctx, db.RescheduleMeetParams{
+		ID:           meet.ID,
+		NewDate:      newDate,
+		PreviousDate: sql.NullTime{Time: meet.Date, Valid: true},
+		Status:       status,
+	})
+	if err != nil {
+		return err
+	}
+	return q.CreateMeetDateChange(ctx, db.CreateMeetDateChangeParams{
+		MeetID:       meet.ID,
+		PreviousDate: meet.Date,
+		NewDate:      newDate,
+		Reason:       sql.NullString{String: reason, Valid: reason != ""},
+	})
+}
+
+func (srv *server) markMeetOfficialHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+
+	meet, err := srv.repo.GetMeetByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Meet not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if meet.Status != meetFinal {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only final meets can be made official"})
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := tx.MarkMeetOfficial(c.Request.Context(), meet.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	after, err := tx.GetMeetByID(c.Request.Context(), meet.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditMeet, meet.ID, meetResponse(meet), meetResponse(after)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, meetResponse(after))
+}
+
+func (srv *server) getMeetHistoryHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+
+	changes, err := srv.repo.GetMeetDateChanges(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]gin.H, len(changes))
+	for i, ch := range changes {
+		response[i] = gin.H{
+			"id":           ch.ID,
+			"meetId":       ch.MeetID,
+			"previousDate": ch.PreviousDate.Format("2006-01-02"),
+			"newDate":      ch.NewDate.Format("2006-01-02"),
+			"reason":       ch.Reason.String,
+			"changedAt":    ch.ChangedAt.Time,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =====================
+// SCHEMA MIGRATIONS
+// =====================
+
+// Migrations are numbered pairs of files, NNNN_name.up.sql and
+// NNNN_name.down.sql, applied in order. Each engine has its own directory,
+// migrations/<driver>, with the same versions. Applied versions are recorded
+// in schema_migrations. sqlc reads the same directories and skips the down
+// files.
+//
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+type migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// loadMigrations reads and pairs the driver's migration files, sorted by
+// version.
+func loadMigrations(driver string) ([]migration, error) {
+	dir := "migrations/" + driver
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*migration)
+	for _, e := range entries {
+		m := migrationFileName.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration %s is not named NNNN_name.up.sql or NNNN_name.down.sql", e.Name())
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		data, err := fs.ReadFile(migrationFiles, dir+"/"+e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(data)
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitStatements breaks a migration into statements on semicolons outside
+// of quotes and comments, since the driver runs one statement per Exec.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote rune
+	lineComment := false
+
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			statements = append(statements, s)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case lineComment:
+			if r == '\n' {
+				lineComment = false
+				current.WriteRune(r)
+			}
+			continue
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			lineComment = true
+			continue
+		case r == ';':
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return statements
+}
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+type appliedMigration struct {
+	Name      string
+	AppliedAt time.Time
+}
+
+func appliedMigrations(ctx context.Context, conn *sql.DB) (map[int64]appliedMigration, error) {
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return nil, err
+	}
+	return readAppliedMigrations(ctx, conn)
+}
+
+// readAppliedMigrations is appliedMigrations without creating the table, for
+// checks that must not write, such as the readiness probe.
+func readAppliedMigrations(ctx context.Context, conn *sql.DB) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var version int64
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.Name, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+// runMigration applies one direction of a migration and records it. MySQL
+// commits DDL as it goes, so a migration that fails halfway must be fixed by
+// hand; keep each one small.
+func runMigration(ctx context.Context, conn *sql.DB, mig migration, up bool) error {
+	script, verb := mig.Up, "applying"
+	if !up {
+		script, verb = mig.Down, "reverting"
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%s migration %d_%s: %w", verb, mig.Version, mig.Name, err)
+		}
+	}
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", mig.Version, mig.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mig.Version)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// migrateUp applies every pending migration up to and including target, or
+// all of them when target is 0.
+func migrateUp(ctx context.Context, conn *sql.DB, migrations []migration, target int64, out io.Writer) error {
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, mig := range migrations {
+		if _, ok := applied[mig.Version]; ok || (target > 0 && mig.Version > target) {
+			continue
+		}
+		if err := runMigration(ctx, conn, mig, true); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Applied %d_%s\n", mig.Version, mig.Name)
+		count++
+	}
+	if count == 0 {
+		fmt.Fprintln(out, "Schema is up to date")
+	}
+	return nil
+}
+
+// migrateDown reverts the newest steps applied migrations.
+func migrateDown(ctx context.Context, conn *sql.DB, migrations []migration, steps int, out io.Writer) error {
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		mig := migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if err := runMigration(ctx, conn, mig, false); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Reverted %d_%s\n", mig.Version, mig.Name)
+		steps--
+	}
+	return nil
+}
+
+// checkSchema refuses to serve a database that is missing migrations, or
+// that was migrated by a newer build than this one.
+func checkSchema(ctx context.Context, conn *sql.DB, migrations []migration) error {
+	applied, err := readAppliedMigrations(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("reading schema_migrations: %w; run `migrate up` on a new database", err)
+	}
+	var pending []string
+	for _, mig := range migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%d_%s", mig.Version, mig.Name))
+		}
+		delete(applied, mig.Version)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is out of date (pending: %s); run `migrate up`", strings.Join(pending, ", "))
+	}
+	for version, a := range applied {
+		return fmt.Errorf("database has migration %d_%s, which this build doesn't know; deploy a newer build", version, a.Name)
+	}
+	return nil
+}
+
+// migrateCommand implements `migrate up|down|status|force`.
+func migrateCommand(cfg config, conn *sql.DB, args []string) error {
+	const usage = "usage: migrate up [-to VERSION] | down [-steps N] | status | force VERSION"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	migrations, err := loadMigrations(cfg.Driver)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	out := os.Stdout
+
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	switch args[0] {
+	case "up":
+		to := flags.Int64("to", 0, "stop after this version")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		return migrateUp(ctx, conn, migrations, *to, out)
+	case "down":
+		steps := flags.Int("steps", 1, "number of migrations to revert")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		return migrateDown(ctx, conn, migrations, *steps, out)
+	case "status":
+		return migrationStatus(ctx, conn, migrations, out)
+	case "force":
+		if len(args) != 2 {
+			return errors.New(usage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return forceMigrations(ctx, conn, migrations, version, out)
+	default:
+		return errors.New(usage)
+	}
+}
+
+func migrationStatus(ctx context.Context, conn *sql.DB, migrations []migration, out io.Writer) error {
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+	for _, mig := range migrations {
+		status := "pending"
+		if a, ok := applied[mig.Version]; ok {
+			status = "applied " + a.AppliedAt.Format(time.DateTime)
+		}
+		fmt.Fprintf(out, "%04d_%-30s %s\n", mig.Version, mig.Name, status)
+	}
+	return nil
+}
+
+// forceMigrations records every migration up to version as applied without
+// running it, and forgets later ones. `force 1` adopts a database that was
+// created from the old schema.sql, which 0001_baseline reproduces; `migrate
+// up` then brings it current. It also recovers from a migration fixed by
+// hand.
+func forceMigrations(ctx context.Context, conn *sql.DB, migrations []migration, version int64, out io.Writer) error {
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, mig := range migrations {
+		_, isApplied := applied[mig.Version]
+		switch {
+		case mig.Version <= version && !isApplied:
+			_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", mig.Version, mig.Name)
+		case mig.Version > version && isApplied:
+			_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mig.Version)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Schema version set to %d\n", version)
+	return nil
+}
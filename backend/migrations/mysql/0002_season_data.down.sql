@@ -0,0 +1,24 @@
+-- Reverts 0002_season_data.up.sql.
+
+DROP TABLE IF EXISTS race_conditions;
+DROP TABLE IF EXISTS award_rules;
+DROP TABLE IF EXISTS time_standards;
+DROP TABLE IF EXISTS team_finishes;
+DROP TABLE IF EXISTS qualification_rules;
+DROP TABLE IF EXISTS meet_date_changes;
+ALTER TABLE meets DROP FOREIGN KEY fk_meets_course;
+DROP INDEX idx_meets_course ON meets;
+ALTER TABLE meets
+    DROP COLUMN course_id,
+    DROP COLUMN temperature_f,
+    DROP COLUMN humidity_pct,
+    DROP COLUMN wind_mph,
+    DROP COLUMN precipitation,
+    DROP COLUMN footing,
+    DROP COLUMN status,
+    DROP COLUMN original_date,
+    DROP COLUMN official,
+    DROP COLUMN finalized_at,
+    DROP COLUMN championship;
+DROP TABLE IF EXISTS courses;
+ALTER TABLE athletes DROP COLUMN division;
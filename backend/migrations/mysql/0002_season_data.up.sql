@@ -0,0 +1,123 @@
+-- Season data added after the original schema: divisions, courses, meet
+-- conditions and status, qualification, time standards and awards.
+
+-- Athletes run in the boys or girls division.
+ALTER TABLE athletes ADD COLUMN division VARCHAR(10) CHECK (division IN ('boys', 'girls')) AFTER grade;
+
+-- Courses table (correction_factor > 1 means the course runs slow)
+CREATE TABLE courses (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    location VARCHAR(100),
+    description VARCHAR(255),
+    correction_factor DOUBLE NOT NULL DEFAULT 1,
+    sample_size INT NOT NULL DEFAULT 0,
+    factor_updated_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- Meets gain a course, weather and footing, and a status lifecycle.
+ALTER TABLE meets
+    ADD COLUMN course_id INT AFTER description,
+    ADD COLUMN temperature_f INT AFTER course_id,
+    ADD COLUMN humidity_pct INT CHECK (humidity_pct BETWEEN 0 AND 100) AFTER temperature_f,
+    ADD COLUMN wind_mph INT AFTER humidity_pct,
+    ADD COLUMN precipitation VARCHAR(20) AFTER wind_mph,
+    ADD COLUMN footing VARCHAR(20) AFTER precipitation,
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'in_progress', 'final', 'postponed', 'cancelled')) AFTER footing,
+    ADD COLUMN original_date DATE AFTER status,
+    ADD COLUMN official BOOLEAN NOT NULL DEFAULT FALSE AFTER original_date,
+    ADD COLUMN finalized_at TIMESTAMP NULL AFTER official,
+    ADD COLUMN championship BOOLEAN NOT NULL DEFAULT FALSE AFTER finalized_at;
+CREATE INDEX idx_meets_course ON meets(course_id);
+ALTER TABLE meets ADD CONSTRAINT fk_meets_course
+    FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE SET NULL;
+
+-- Meet date changes (history kept when a meet is rescheduled)
+CREATE TABLE meet_date_changes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    meet_id INT NOT NULL,
+    previous_date DATE NOT NULL,
+    new_date DATE NOT NULL,
+    reason VARCHAR(255),
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE
+);
+
+-- Qualification rules (results at meet_id decide who advances to target_meet_id)
+CREATE TABLE qualification_rules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    meet_id INT NOT NULL UNIQUE,
+    target_meet_id INT NOT NULL,
+    top_teams INT NOT NULL DEFAULT 0,
+    top_individuals INT NOT NULL DEFAULT 0,
+    top_individuals_not_on_teams INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE,
+    FOREIGN KEY (target_meet_id) REFERENCES meets(id) ON DELETE CASCADE,
+    CHECK (meet_id <> target_meet_id)
+);
+
+-- Team finishes (our team's place in the team standings of a meet)
+CREATE TABLE team_finishes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    meet_id INT NOT NULL,
+    division VARCHAR(10) NOT NULL CHECK (division IN ('boys', 'girls')),
+    place INT NOT NULL,
+    score INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_team_finish (meet_id, division)
+);
+
+-- Time standards (a result at or under the time achieves the standard)
+CREATE TABLE time_standards (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    event_type_id INT NOT NULL,
+    division VARCHAR(10) CHECK (division IN ('boys', 'girls')),
+    time VARCHAR(10) NOT NULL,
+    category VARCHAR(20) NOT NULL DEFAULT 'milestone',
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (event_type_id) REFERENCES event_types(id) ON DELETE CASCADE
+);
+
+-- Award rules (what it takes to earn each season award)
+CREATE TABLE award_rules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    award_type VARCHAR(20) NOT NULL
+        CHECK (award_type IN ('letter', 'most_improved', 'top_newcomer')),
+    criterion VARCHAR(30)
+        CHECK (criterion IN ('varsity_races', 'championship_score')),
+    threshold INT NOT NULL DEFAULT 1,
+    winners INT NOT NULL DEFAULT 1,
+    division VARCHAR(10) CHECK (division IN ('boys', 'girls')),
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- Race conditions table (per-race overrides of the meet conditions)
+CREATE TABLE race_conditions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    meet_id INT NOT NULL,
+    event_type_id INT NOT NULL,
+    temperature_f INT,
+    humidity_pct INT CHECK (humidity_pct BETWEEN 0 AND 100),
+    wind_mph INT,
+    precipitation VARCHAR(20),
+    footing VARCHAR(20),
+    notes VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE,
+    FOREIGN KEY (event_type_id) REFERENCES event_types(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_race_conditions (meet_id, event_type_id)
+);
+
+CREATE INDEX idx_meet_date_changes_meet ON meet_date_changes(meet_id);
@@ -0,0 +1,125 @@
+-- Accounts and everything that hangs off them: users, sessions, two-factor
+-- authentication, API tokens, invitations, the audit log and athlete name
+-- privacy.
+
+-- Users table (accounts for the admin site; passwords are bcrypt hashes)
+CREATE TABLE users (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    username VARCHAR(50) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    display_name VARCHAR(100),
+    email VARCHAR(255),
+    role VARCHAR(20) NOT NULL DEFAULT 'assistant'
+        CHECK (role IN ('head_coach', 'assistant', 'athlete', 'parent')),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    password_changed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- Links athlete and parent accounts to the athletes whose private data they
+-- may see
+CREATE TABLE user_athletes (
+    user_id INT NOT NULL,
+    athlete_id INT NOT NULL,
+    PRIMARY KEY (user_id, athlete_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE
+);
+
+-- Single-use links emailed for invitations and password resets
+CREATE TABLE account_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    purpose VARCHAR(20) NOT NULL CHECK (purpose IN ('invite', 'password_reset')),
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_by INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- API tokens for scripts and timing software (scopes is a space-separated
+-- list; meet_id limits results:write to one meet)
+CREATE TABLE api_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    token_prefix VARCHAR(16) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    meet_id INT,
+    expires_at TIMESTAMP NULL,
+    last_used_at TIMESTAMP NULL,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP NULL,
+    created_by INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Two-factor authentication (TOTP secret; confirmed_at is set once the user
+-- proves their authenticator works)
+CREATE TABLE user_totp (
+    user_id INT PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- One-time recovery codes for when the authenticator is lost
+CREATE TABLE recovery_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Login sessions. Only a hash of each token is stored.
+CREATE TABLE sessions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    user_id INT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NULL,
+    user_agent VARCHAR(255),
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Audit log (append-only record of every change to athletes, meets, results
+-- and event types; before/after hold JSON snapshots of the row)
+CREATE TABLE audit_log (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT,
+    username VARCHAR(50),
+    action VARCHAR(10) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id INT NOT NULL,
+    before_data TEXT,
+    after_data TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- How an athlete's name appears to the public. 'full' also needs parental
+-- consent; without it the name falls back to first name and last initial.
+ALTER TABLE athletes
+    ADD COLUMN name_visibility VARCHAR(10) NOT NULL DEFAULT 'initial' CHECK (name_visibility IN ('full', 'initial', 'hidden')) AFTER events,
+    ADD COLUMN parental_consent BOOLEAN NOT NULL DEFAULT FALSE AFTER name_visibility;
+
+CREATE INDEX idx_sessions_user ON sessions(user_id);
+CREATE INDEX idx_recovery_codes_user ON recovery_codes(user_id);
+CREATE INDEX idx_api_tokens_user ON api_tokens(user_id);
+CREATE INDEX idx_account_tokens_user ON account_tokens(user_id);
+CREATE INDEX idx_audit_log_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX idx_audit_log_created ON audit_log(created_at);
@@ -0,0 +1,63 @@
+-- The tables of the original schema.sql, for PostgreSQL. Kept in step with
+-- ../mysql.
+
+-- Athletes table
+CREATE TABLE athletes (
+    id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    grade INT NOT NULL CHECK (grade >= 9 AND grade <= 12),
+    personal_record VARCHAR(10),
+    events VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Event Types table
+CREATE TABLE event_types (
+    id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    distance VARCHAR(20),
+    description VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Meets table
+CREATE TABLE meets (
+    id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    date DATE NOT NULL,
+    time VARCHAR(10),
+    location VARCHAR(100),
+    description TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Results table (links athletes to meets)
+CREATE TABLE results (
+    id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    athlete_id INT NOT NULL,
+    meet_id INT NOT NULL,
+    event_type_id INT,
+    time VARCHAR(10) NOT NULL,
+    place INT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE,
+    FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE,
+    FOREIGN KEY (event_type_id) REFERENCES event_types(id) ON DELETE SET NULL,
+    CONSTRAINT unique_athlete_meet_event UNIQUE (athlete_id, meet_id, event_type_id)
+);
+
+-- Indexes for faster queries
+CREATE INDEX idx_results_athlete ON results(athlete_id);
+CREATE INDEX idx_results_meet ON results(meet_id);
+CREATE INDEX idx_meets_date ON meets(date);
+
+-- Event types are reference data that results and standards point at.
+INSERT INTO event_types (name, distance, description) VALUES
+    ('5K', '5000m', 'Standard cross country distance'),
+    ('3200m', '3200m', 'Two mile race'),
+    ('1600m', '1600m', 'One mile race'),
+    ('800m', '800m', 'Half mile race'),
+    ('400m', '400m', 'Quarter mile sprint');
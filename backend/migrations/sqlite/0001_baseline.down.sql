@@ -0,0 +1,6 @@
+-- Drops everything created by 0001_baseline.up.sql, children first.
+
+DROP TABLE IF EXISTS results;
+DROP TABLE IF EXISTS meets;
+DROP TABLE IF EXISTS event_types;
+DROP TABLE IF EXISTS athletes;
@@ -0,0 +1,22 @@
+-- Reverts 0002_season_data.up.sql.
+
+DROP TABLE IF EXISTS race_conditions;
+DROP TABLE IF EXISTS award_rules;
+DROP TABLE IF EXISTS time_standards;
+DROP TABLE IF EXISTS team_finishes;
+DROP TABLE IF EXISTS qualification_rules;
+DROP TABLE IF EXISTS meet_date_changes;
+DROP INDEX IF EXISTS idx_meets_course;
+ALTER TABLE meets DROP COLUMN championship;
+ALTER TABLE meets DROP COLUMN finalized_at;
+ALTER TABLE meets DROP COLUMN official;
+ALTER TABLE meets DROP COLUMN original_date;
+ALTER TABLE meets DROP COLUMN status;
+ALTER TABLE meets DROP COLUMN footing;
+ALTER TABLE meets DROP COLUMN precipitation;
+ALTER TABLE meets DROP COLUMN wind_mph;
+ALTER TABLE meets DROP COLUMN humidity_pct;
+ALTER TABLE meets DROP COLUMN temperature_f;
+ALTER TABLE meets DROP COLUMN course_id;
+DROP TABLE IF EXISTS courses;
+ALTER TABLE athletes DROP COLUMN division;
@@ -0,0 +1,12 @@
+-- Reverts 0003_accounts.up.sql.
+
+ALTER TABLE athletes DROP COLUMN parental_consent;
+ALTER TABLE athletes DROP COLUMN name_visibility;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS user_totp;
+DROP TABLE IF EXISTS api_tokens;
+DROP TABLE IF EXISTS account_tokens;
+DROP TABLE IF EXISTS user_athletes;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+// =====================
+// SINGLE SIGN-ON (OIDC)
+// =====================
+
+const (
+	oidcStateCookie     = "xc_oidc"
+	oidcStateTTL        = 10 * time.Minute
+	oidcChallengeCookie = "xc_login_challenge"
+)
+
+// oidcConfig describes the identity provider. SSO is off when Issuer is
+// empty.
+type oidcConfig struct {
+	Issuer       string `yaml:"issuer" toml:"issuer"`
+	ClientID     string `yaml:"client_id" toml:"client_id"`
+	ClientSecret string `yaml:"client_secret" toml:"client_secret"`
+	RedirectURL  string `yaml:"redirect_url" toml:"redirect_url"`
+	// Name is shown on the login button, e.g. "Google".
+	Name string `yaml:"name" toml:"name"`
+	// DomainRoles maps email domains to the role given to people from that
+	// domain who sign in without an account yet. Other unknown emails are
+	// turned away.
+	DomainRoles map[string]string `yaml:"domain_roles" toml:"domain_roles"`
+}
+
+// oidcClient is set up on first use, so the server starts even while the
+// identity provider is unreachable.
+type oidcClient struct {
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+func (srv *server) getOIDCClient(ctx context.Context) (*oidcClient, error) {
+	srv.oidcMu.Lock()
+	defer srv.oidcMu.Unlock()
+	if srv.oidcCached != nil {
+		return srv.oidcCached, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, srv.cfg.OIDC.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discovering %s: %w", srv.cfg.OIDC.Issuer, err)
+	}
+	srv.oidcCached = &oidcClient{
+		oauth: oauth2.Config{
+			ClientID:     srv.cfg.OIDC.ClientID,
+			ClientSecret: srv.cfg.OIDC.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  srv.cfg.OIDC.RedirectURL,
+			Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: srv.cfg.OIDC.ClientID}),
+	}
+	return srv.oidcCached, nil
+}
+
+// oidcState is what the login step remembers for the callback. It travels in
+// a signed cookie, so no server-side storage is needed.
+type oidcState struct {
+	State    string `json:"s"`
+	Nonce    string `json:"n"`
+	Verifier string `json:"v"`
+	Redirect string `json:"r"`
+	Expires  int64  `json:"e"`
+}
+
+func (srv *server) signOIDCState(payload string) string {
+	mac := hmac.New(sha256.New, srv.sessionSecret)
+	mac.Write([]byte("oidc-state:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (srv *server) encodeOIDCState(s oidcState) (string, error) {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	return payload + "." + srv.signOIDCState(payload), nil
+}
+
+func (srv *server) decodeOIDCState(value string, now time.Time) (oidcState, bool) {
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(srv.signOIDCState(payload))) {
+		return oidcState{}, false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return oidcState{}, false
+	}
+	var s oidcState
+	if err := json.Unmarshal(raw, &s); err != nil || now.Unix() > s.Expires {
+		return oidcState{}, false
+	}
+	return s, true
+}
+
+func (srv *server) setOIDCStateCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     "/api/auth/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   srv.secureCookies(c),
+		// Lax lets the cookie come back on the provider's redirect.
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// setChallengeCookie hands a single sign-on login challenge to
+// POST /api/login/2fa. Only that endpoint receives it.
+func (srv *server) setChallengeCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcChallengeCookie,
+		Value:    value,
+		Path:     "/api/login/2fa",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   srv.secureCookies(c),
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func randomString() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// safeRedirect only allows paths on this site, so the login flow can't be
+// used to send people elsewhere.
+func safeRedirect(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.Contains(path, `\`) {
+		return "/admin"
+	}
+	return path
+}
+
+// redirectToLogin sends the browser back to the login page, which picks up
+// the outcome from the query string.
+func (srv *server) redirectToLogin(c *gin.Context, params url.Values) {
+	c.Redirect(http.StatusFound, srv.cfg.AppURL+"/login?"+params.Encode())
+}
+
+func (srv *server) oidcFailure(c *gin.Context, message string) {
+	srv.redirectToLogin(c, url.Values{"error": {message}})
+}
+
+// getOIDCInfoHandler tells the login page whether to offer single sign-on.
+func (srv *server) getOIDCInfoHandler(c *gin.Context) {
+	if srv.cfg.OIDC.Issuer == "" {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":  true,
+		"name":     srv.cfg.OIDC.Name,
+		"loginUrl": "/api/auth/oidc/login",
+	})
+}
+
+// oidcLoginHandler starts an authorization code flow with PKCE and sends the
+// browser to the identity provider.
+func (srv *server) oidcLoginHandler(c *gin.Context) {
+	if srv.cfg.OIDC.Issuer == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	client, err := srv.getOIDCClient(c.Request.Context())
+	if err != nil {
+		srv.log.Error("single sign-on unavailable", "error", err)
+		srv.oidcFailure(c, "Single sign-on is unavailable right now")
+		return
+	}
+
+	state, err := randomString()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	nonce, err := randomString()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	cookie, err := srv.encodeOIDCState(oidcState{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		Redirect: safeRedirect(c.DefaultQuery("redirect", "/admin")),
+		Expires:  srv.now().Add(oidcStateTTL).Unix(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	srv.setOIDCStateCookie(c, cookie, int(oidcStateTTL.Seconds()))
+
+	c.Redirect(http.StatusFound, client.oauth.AuthCodeURL(state,
+		oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)))
+}
+
+type oidcClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// oidcCallbackHandler finishes the flow: it trades the code for tokens,
+// verifies the ID token and signs in the local user with the same email.
+func (srv *server) oidcCallbackHandler(c *gin.Context) {
+	if srv.cfg.OIDC.Issuer == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	value, _ := c.Cookie(oidcStateCookie)
+	srv.setOIDCStateCookie(c, "", -1)
+	state, ok := srv.decodeOIDCState(value, srv.now())
+	if !ok || c.Query("state") == "" || !hmac.Equal([]byte(c.Query("state")), []byte(state.State)) {
+		srv.oidcFailure(c, "Sign-in expired, please try again")
+		return
+	}
+	if e := c.Query("error"); e != "" {
+		srv.log.Warn("identity provider returned an error", "error", e, "description", c.Query("error_description"))
+		srv.oidcFailure(c, "Sign-in was cancelled or refused")
+		return
+	}
+
+	ctx := c.Request.Context()
+	client, err := srv.getOIDCClient(ctx)
+	if err != nil {
+		srv.log.Error("single sign-on unavailable", "error", err)
+		srv.oidcFailure(c, "Single sign-on is unavailable right now")
+		return
+	}
+
+	token, err := client.oauth.Exchange(ctx, c.Query("code"), oauth2.VerifierOption(state.Verifier))
+	if err != nil {
+		srv.log.Warn("OIDC code exchange failed", "error", err)
+		srv.oidcFailure(c, "Sign-in failed, please try again")
+		return
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		srv.oidcFailure(c, "Sign-in failed, please try again")
+		return
+	}
+	idToken, err := client.verifier.Verify(ctx, rawIDToken)
+	if err != nil || !hmac.Equal([]byte(idToken.Nonce), []byte(state.Nonce)) {
+		srv.log.Warn("OIDC ID token rejected", "error", err)
+		srv.oidcFailure(c, "Sign-in failed, please try again")
+		return
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		srv.oidcFailure(c, "Sign-in failed, please try again")
+		return
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		srv.oidcFailure(c, "Your account's email address is not verified")
+		return
+	}
+
+	user, err := srv.oidcUser(ctx, claims)
+	if errors.Is(err, errNoSSOAccount) || errors.Is(err, errAmbiguousSSOAccount) {
+		srv.oidcFailure(c, err.Error())
+		return
+	}
+	if err != nil {
+		srv.log.Warn("OIDC sign-in failed", "email", claims.Email, "error", err)
+		srv.oidcFailure(c, "Sign-in failed, please try again")
+		return
+	}
+
+	// Accounts with two-factor authentication still need their code. The
+	// challenge travels in a cookie rather than the URL, where it would end
+	// up in browser history and proxy logs, and the login page asks for the
+	// code.
+	enabled, err := srv.twoFactorEnabled(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if enabled {
+		challenge, _ := srv.newLoginChallenge(user.ID, srv.now())
+		srv.setChallengeCookie(c, challenge, int(challengeTTL.Seconds()))
+		srv.redirectToLogin(c, url.Values{"twoFactor": {"1"}, "redirect": {state.Redirect}})
+		return
+	}
+
+	if _, _, err := srv.startSession(c, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	srv.log.Info("signed in with single sign-on", "user", user.Username, "email", claims.Email)
+	srv.redirectToLogin(c, url.Values{"sso": {"1"}, "redirect": {state.Redirect}})
+}
+
+var (
+	errNoSSOAccount        = errors.New("there is no account for this email address; ask a head coach for an invitation")
+	errAmbiguousSSOAccount = errors.New("more than one account uses this email address; ask a head coach to fix it")
+)
+
+// oidcUser finds the active account with the verified email, creating one
+// when the email's domain is set up for it.
+func (srv *server) oidcUser(ctx context.Context, claims oidcClaims) (db.User, error) {
+	users, err := srv.repo.GetUsersByEmail(ctx, sql.NullString{String: claims.Email, Valid: true})
+	if err != nil {
+		return db.User{}, err
+	}
+	var active []db.User
+	for _, u := range users {
+		if u.Active {
+			active = append(active, u)
+		}
+	}
+	switch {
+	case len(active) == 1:
+		return active[0], nil
+	case len(active) > 1:
+		return db.User{}, errAmbiguousSSOAccount
+	case len(users) > 0:
+		// A deactivated account must not be recreated by signing in.
+		return db.User{}, errNoSSOAccount
+	}
+
+	_, domain, _ := strings.Cut(strings.ToLower(claims.Email), "@")
+	role, ok := srv.cfg.OIDC.DomainRoles[domain]
+	if !ok {
+		return db.User{}, errNoSSOAccount
+	}
+
+	username, err := srv.ssoUsername(ctx, claims.Email)
+	if err != nil {
+		return db.User{}, err
+	}
+	password, err := randomString()
+	if err != nil {
+		return db.User{}, err
+	}
+	user, err := createUser(ctx, srv.repo, CreateUserRequest{
+		Username:    username,
+		Password:    password,
+		DisplayName: claims.Name,
+		Email:       claims.Email,
+		Role:        role,
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+	srv.log.Info("created account on first sign-in", "role", role, "user", user.Username, "email", claims.Email)
+	return user, nil
+}
+
+var usernameUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// ssoUsername derives a free username from the email's local part.
+func (srv *server) ssoUsername(ctx context.Context, email string) (string, error) {
+	local, _, _ := strings.Cut(strings.ToLower(email), "@")
+	base := usernameUnsafe.ReplaceAllString(local, "")
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	for i := 1; i < 100; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s%d", base, i)
+		}
+		_, err := srv.repo.GetUserByUsername(ctx, candidate)
+		if err == sql.ErrNoRows {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", errors.New("no free username for " + email)
+}
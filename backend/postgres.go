@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// =====================
+// POSTGRESQL
+// =====================
+
+// The queries are written for MySQL, with ? placeholders, and create
+// handlers read the new row's ID from LastInsertId. Rather than keep a
+// second copy of every query, PostgreSQL connections are wrapped to rewrite
+// placeholders to $1, $2, ... and to answer LastInsertId from INSERT ...
+// RETURNING. Because the rewriting happens in the driver, it covers
+// transactions and the migration runner too.
+
+func openPostgres(dsn string) (*sql.DB, error) {
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(postgresConnector{stdlib.GetConnector(*config)}), nil
+}
+
+type postgresConnector struct {
+	driver.Connector
+}
+
+func (c postgresConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresConn{conn.(*stdlib.Conn)}, nil
+}
+
+// postgresConn embeds the pgx connection, so database/sql still finds its
+// transaction, ping and session reset methods.
+type postgresConn struct {
+	*stdlib.Conn
+}
+
+func (c *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(rebind(query))
+}
+
+func (c *postgresConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Conn.PrepareContext(ctx, rebind(query))
+}
+
+func (c *postgresConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.Conn.QueryContext(ctx, rebind(query), args)
+}
+
+func (c *postgresConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query = rebind(query)
+	if !needsReturning(query) {
+		return c.Conn.ExecContext(ctx, query, args)
+	}
+
+	rows, err := c.Conn.QueryContext(ctx, returningAll(query), args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	idColumn := -1
+	for i, name := range rows.Columns() {
+		if name == "id" {
+			idColumn = i
+		}
+	}
+	result := insertResult{}
+	values := make([]driver.Value, len(rows.Columns()))
+	for {
+		err := rows.Next(values)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Like MySQL, report the first row's ID for multi-row inserts.
+		if result.rows == 0 && idColumn >= 0 {
+			result.id, result.hasID = toInt64(values[idColumn])
+		}
+		result.rows++
+	}
+	return result, nil
+}
+
+type insertResult struct {
+	id    int64
+	hasID bool
+	rows  int64
+}
+
+func (r insertResult) LastInsertId() (int64, error) {
+	if !r.hasID {
+		return 0, errors.New("table has no id column")
+	}
+	return r.id, nil
+}
+
+func (r insertResult) RowsAffected() (int64, error) {
+	return r.rows, nil
+}
+
+func toInt64(v driver.Value) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int32:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+// rebind rewrites ? placeholders to $1, $2, ..., leaving quoted strings,
+// identifiers and comments alone.
+func rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	var quote byte
+	lineComment := false
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case lineComment:
+			lineComment = ch != '\n'
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '-' && i+1 < len(query) && query[i+1] == '-':
+			lineComment = true
+		case ch == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// returningAll makes an INSERT hand back the rows it added. It is RETURNING *
+// rather than RETURNING id, since not every table has one.
+func returningAll(query string) string {
+	return strings.TrimRight(strings.TrimSpace(query), ";") + " RETURNING *"
+}
+
+// needsReturning reports whether ExecContext adds RETURNING * to a query.
+func needsReturning(query string) bool {
+	return isInsert(query) && !containsWord(query, "RETURNING")
+}
+
+// isInsert reports whether the first keyword after any comments is INSERT.
+func isInsert(query string) bool {
+	for _, line := range strings.Split(query, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		return len(line) >= 6 && strings.EqualFold(line[:6], "INSERT")
+	}
+	return false
+}
+
+func containsWord(query, word string) bool {
+	for _, field := range strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '_'
+	}) {
+		if strings.EqualFold(field, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite db/postgres/queries.sql")
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query, want string
+	}{
+		{"SELECT * FROM athletes", "SELECT * FROM athletes"},
+		{"SELECT * FROM athletes WHERE id = ?", "SELECT * FROM athletes WHERE id = $1"},
+		{"INSERT INTO results (athlete_id, meet_id, time) VALUES (?, ?, ?)",
+			"INSERT INTO results (athlete_id, meet_id, time) VALUES ($1, $2, $3)"},
+		// Placeholders past nine keep counting.
+		{"VALUES (?,?,?,?,?,?,?,?,?,?,?)", "VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)"},
+		// Question marks in strings, quoted identifiers and comments are
+		// not placeholders.
+		{"SELECT '?' AS q, name FROM athletes WHERE id = ?", "SELECT '?' AS q, name FROM athletes WHERE id = $1"},
+		{`SELECT "what?" FROM t WHERE a = ? AND b = 'it''s ?' AND c = ?`,
+			`SELECT "what?" FROM t WHERE a = $1 AND b = 'it''s ?' AND c = $2`},
+		{"-- why?\nSELECT * FROM t WHERE id = ? -- really?\nAND x = ?",
+			"-- why?\nSELECT * FROM t WHERE id = $1 -- really?\nAND x = $2"},
+		// A single dash is subtraction, not a comment.
+		{"UPDATE t SET n = n - ? WHERE id = ?", "UPDATE t SET n = n - $1 WHERE id = $2"},
+	}
+	for _, tt := range tests {
+		if got := rebind(tt.query); got != tt.want {
+			t.Errorf("rebind(%q)\n got %q\nwant %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestIsInsert(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"INSERT INTO athletes (name) VALUES ($1)", true},
+		{"insert into athletes (name) values ($1)", true},
+		{"\n\t  INSERT INTO athletes (name) VALUES ($1)", true},
+		{"-- Add the athlete.\nINSERT INTO athletes (name) VALUES ($1)", true},
+		{"SELECT * FROM athletes", false},
+		{"UPDATE athletes SET name = $1", false},
+		{"-- INSERT INTO athletes\nDELETE FROM athletes", false},
+		{"WITH a AS (SELECT 1) INSERT INTO t SELECT * FROM a", false},
+		{"INS", false},
+		{"", false},
+		{"-- only a comment", false},
+	}
+	for _, tt := range tests {
+		if got := isInsert(tt.query); got != tt.want {
+			t.Errorf("isInsert(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestContainsWord(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"INSERT INTO t (a) VALUES ($1) RETURNING id", true},
+		{"insert into t (a) values ($1) returning *", true},
+		{"INSERT INTO t (returning_customer) VALUES ($1)", false},
+		{"INSERT INTO t (a) VALUES ($1)", false},
+	}
+	for _, tt := range tests {
+		if got := containsWord(tt.query, "RETURNING"); got != tt.want {
+			t.Errorf("containsWord(%q, RETURNING) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+// TestPostgresQueries checks that db/postgres/queries.sql holds every query
+// in db/queries.sql.go as a PostgreSQL connection runs it, so sqlc can check
+// them against the PostgreSQL schema. Run with -update after changing a
+// query.
+func TestPostgresQueries(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "db/queries.sql.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	b.WriteString("-- Code generated by go test -run TestPostgresQueries -update. DO NOT EDIT.\n")
+	b.WriteString("-- The queries in db/queries.sql.go, rewritten as postgres.go runs them.\n")
+	ast.Inspect(file, func(n ast.Node) bool {
+		lit, ok := n.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING || !strings.HasPrefix(lit.Value, "`-- name: ") {
+			return true
+		}
+		query, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		query = rebind(strings.TrimSpace(query))
+		// Only ExecContext adds RETURNING; sqlc runs :exec queries that way.
+		if header, _, _ := strings.Cut(query, "\n"); strings.Contains(header, " :exec") && needsReturning(query) {
+			query = returningAll(query)
+		}
+		b.WriteString("\n" + query + ";\n")
+		return true
+	})
+
+	const path = "db/postgres/queries.sql"
+	if *update {
+		if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != b.String() {
+		t.Errorf("%s is out of date; run go test -run TestPostgresQueries -update", path)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// ATHLETE PRIVACY
+// =====================
+
+// Athletes are minors, so the public only sees their full name when a parent
+// has consented. Everyone else gets first name and last initial unless the
+// athlete is hidden altogether.
+const (
+	nameFull    = "full"
+	nameInitial = "initial"
+	nameHidden  = "hidden"
+
+	hiddenAthleteName = "Private athlete"
+)
+
+// athleteNames decides how athletes appear in one response. Coaches see
+// everyone, athlete and parent accounts see their own athletes, and anyone
+// else sees each athlete's public name.
+type athleteNames struct {
+	all      bool
+	own      map[int32]bool
+	settings map[int32]db.GetAthletePrivacyRow
+}
+
+func (srv *server) loadAthleteNames(c *gin.Context) (athleteNames, error) {
+	if srv.can(c, permViewAllAthletes) {
+		return athleteNames{all: true}, nil
+	}
+
+	ctx := c.Request.Context()
+	names := athleteNames{
+		own:      make(map[int32]bool),
+		settings: make(map[int32]db.GetAthletePrivacyRow),
+	}
+	if srv.can(c, permViewOwnAthletes) {
+		user, _ := currentUser(c)
+		ids, err := srv.repo.GetUserAthleteIDs(ctx, user.ID)
+		if err != nil {
+			return athleteNames{}, err
+		}
+		for _, id := range ids {
+			names.own[id] = true
+		}
+	}
+
+	rows, err := srv.repo.GetAthletePrivacy(ctx)
+	if err != nil {
+		return athleteNames{}, err
+	}
+	for _, r := range rows {
+		names.settings[r.ID] = r
+	}
+	return names, nil
+}
+
+// visibility returns how much of an athlete's name this viewer may see.
+func (n athleteNames) visibility(id int32) string {
+	if n.all || n.own[id] {
+		return nameFull
+	}
+	s, ok := n.settings[id]
+	if !ok {
+		return nameHidden
+	}
+	if s.NameVisibility == nameFull && !s.ParentalConsent {
+		return nameInitial
+	}
+	return s.NameVisibility
+}
+
+func (n athleteNames) hidden(id int32) bool {
+	return n.visibility(id) == nameHidden
+}
+
+func (n athleteNames) name(id int32, fullName string) string {
+	switch n.visibility(id) {
+	case nameFull:
+		return fullName
+	case nameInitial:
+		return firstNameLastInitial(fullName)
+	default:
+		return hiddenAthleteName
+	}
+}
+
+// athlete is athleteResponse as this viewer may see it. Only coaches see the
+// privacy settings themselves.
+func (n athleteNames) athlete(a db.Athlete) gin.H {
+	response := athleteResponse(a)
+	response["name"] = n.name(a.ID, a.Name)
+	if !n.all {
+		delete(response, "nameVisibility")
+		delete(response, "parentalConsent")
+	}
+	return response
+}
+
+// firstNameLastInitial turns "Marcus Johnson" into "Marcus J.".
+func firstNameLastInitial(name string) string {
+	parts := strings.Fields(name)
+	if len(parts) < 2 {
+		return name
+	}
+	last := []rune(parts[len(parts)-1])
+	return parts[0] + " " + string(unicode.ToUpper(last[0])) + "."
+}
+
+// checkAthleteVisible answers 404 and returns false when the viewer may not
+// see the athlete at all, so hidden athletes' pages look like missing ones.
+func checkAthleteVisible(c *gin.Context, names athleteNames, id int32) bool {
+	if names.hidden(id) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Athlete not found"})
+		return false
+	}
+	return true
+}
+
+type AthletePrivacyRequest struct {
+	NameVisibility  string `json:"nameVisibility" binding:"required,oneof=full initial hidden"`
+	ParentalConsent *bool  `json:"parentalConsent" binding:"required"`
+}
+
+// updateAthletePrivacyHandler records a parent's consent and the athlete's
+// chosen name visibility. A full name stays hidden from the public until
+// consent is recorded.
+func (srv *server) updateAthletePrivacyHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid athlete ID"})
+		return
+	}
+
+	var req AthletePrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := tx.GetAthleteByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Athlete not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = tx.UpdateAthletePrivacy(c.Request.Context(), db.UpdateAthletePrivacyParams{
+		NameVisibility:  req.NameVisibility,
+		ParentalConsent: *req.ParentalConsent,
+		ID:              int32(id),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	after, err := tx.GetAthleteByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recordAudit(c, tx, auditAthlete, after.ID, athleteResponse(before), athleteResponse(after)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, athleteResponse(after))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestHiddenAthletesLeftOut checks that lists the public can read leave
+// hidden athletes out altogether, rather than showing their results under a
+// placeholder name, while coaches still see them.
+func TestHiddenAthletesLeftOut(t *testing.T) {
+	ts := newTestServer(t)
+	// Jaylen Carter (athlete 1) advances from the region meet and has the
+	// fastest times.
+	addResult(ts)
+	rec := ts.do(http.MethodPut, "/api/athletes/1/privacy", roleHeadCoach, obj{"nameVisibility": nameHidden, "parentalConsent": false})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("hiding athlete: %d %s", rec.Code, rec.Body)
+	}
+
+	for _, path := range []string{
+		"/api/meets/1/results",
+		"/api/results",
+		"/api/results/all",
+		"/api/results/top10",
+		"/api/results/top10?adjusted=true",
+		"/api/meets/5/advancers",
+		"/api/meets/6/qualified",
+		"/api/standards/report",
+	} {
+		t.Run(path, func(t *testing.T) {
+			rec := ts.do(http.MethodGet, path, roleHeadCoach, nil)
+			if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Jaylen Carter") {
+				t.Fatalf("coach: got %d %s, want the athlete listed", rec.Code, rec.Body)
+			}
+			rec = ts.do(http.MethodGet, path, "", nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("anonymous: got %d %s", rec.Code, rec.Body)
+			}
+			if strings.Contains(rec.Body.String(), hiddenAthleteName) {
+				t.Errorf("anonymous: hidden athlete listed: %s", rec.Body)
+			}
+		})
+	}
+
+	// The top ten still has ten places once the hidden athlete is left out.
+	var top []struct{ AthleteID int32 }
+	decode(t, ts.do(http.MethodGet, "/api/results/top10", "", nil), &top)
+	if len(top) != 10 {
+		t.Errorf("top ten has %d results, want 10", len(top))
+	}
+}
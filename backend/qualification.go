@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// POSTSEASON QUALIFICATION
+// =====================
+
+const (
+	qualifiedByTeam             = "team"
+	qualifiedByIndividual       = "individual"
+	qualifiedByIndividualNoTeam = "individual_not_on_team"
+)
+
+type qualifier struct {
+	AthleteID   int32  `json:"athleteId"`
+	AthleteName string `json:"athleteName"`
+	Division    string `json:"division"`
+	MeetID      int32  `json:"meetId"`
+	Time        string `json:"time"`
+	Place       int32  `json:"place"`
+	Reason      string `json:"reason"`
+}
+
+// computeQualifiers applies a qualification rule to our results at the
+// qualifying meet. A team that finishes within the top N teams takes every
+// runner from that division. Otherwise runners advance individually, either
+// from the overall top N or from the top N individuals not on a qualifying
+// team. That second list leaves out runners whose team qualified, so it is
+// ranked separately: our remaining runners in each division are ordered by
+// place and the first N advance. We only hold our own results, so runners
+// from other schools who are not on qualifying teams can't be counted.
+func computeQualifiers(rule db.QualificationRule, results []db.GetMeetResultsRow, teamPlaces map[string]int32) []qualifier {
+	seen := make(map[int32]bool)
+	qualifiers := make([]qualifier, 0)
+	add := func(r db.GetMeetResultsRow, division, reason string) {
+		seen[r.AthleteID] = true
+		qualifiers = append(qualifiers, qualifier{
+			AthleteID:   r.AthleteID,
+			AthleteName: r.AthleteName,
+			Division:    division,
+			MeetID:      r.MeetID,
+			Time:        r.Time,
+			Place:       r.Place.Int32,
+			Reason:      reason,
+		})
+	}
+
+	// Runners not taken by team or overall place, by division.
+	remaining := make(map[string][]db.GetMeetResultsRow)
+	for _, r := range results {
+		if seen[r.AthleteID] {
+			continue
+		}
+		division := athleteDivision(r.AthleteDivision.String)
+		teamPlace, hasTeamPlace := teamPlaces[division]
+
+		switch {
+		case hasTeamPlace && rule.TopTeams > 0 && teamPlace <= rule.TopTeams:
+			add(r, division, qualifiedByTeam)
+		case r.Place.Valid && rule.TopIndividuals > 0 && r.Place.Int32 <= rule.TopIndividuals:
+			add(r, division, qualifiedByIndividual)
+		case r.Place.Valid:
+			remaining[division] = append(remaining[division], r)
+		}
+	}
+
+	if rule.TopIndividualsNotOnTeams > 0 {
+		for division, runners := range remaining {
+			sort.SliceStable(runners, func(i, j int) bool { return runners[i].Place.Int32 < runners[j].Place.Int32 })
+			rank := int32(0)
+			for _, r := range runners {
+				if seen[r.AthleteID] {
+					continue
+				}
+				if rank++; rank > rule.TopIndividualsNotOnTeams {
+					break
+				}
+				add(r, division, qualifiedByIndividualNoTeam)
+			}
+		}
+	}
+
+	sort.SliceStable(qualifiers, func(i, j int) bool {
+		if qualifiers[i].Division != qualifiers[j].Division {
+			return qualifiers[i].Division < qualifiers[j].Division
+		}
+		return qualifiers[i].Place < qualifiers[j].Place
+	})
+	return qualifiers
+}
+
+// qualifiersFromMeet evaluates the qualification rule attached to a meet.
+func (srv *server) qualifiersFromMeet(ctx context.Context, rule db.QualificationRule) ([]qualifier, error) {
+	results, err := srv.repo.GetMeetResults(ctx, rule.MeetID)
+	if err != nil {
+		return nil, err
+	}
+	finishes, err := srv.repo.GetTeamFinishesByMeet(ctx, rule.MeetID)
+	if err != nil {
+		return nil, err
+	}
+
+	teamPlaces := make(map[string]int32, len(finishes))
+	for _, f := range finishes {
+		teamPlaces[f.Division] = f.Place
+	}
+	return computeQualifiers(rule, results, teamPlaces), nil
+}
+
+// qualifiedForMeet returns everyone who advanced into a meet through any of
+// its qualifying meets. The boolean is false when the meet has no qualifiers
+// and is therefore open to anyone.
+func (srv *server) qualifiedForMeet(ctx context.Context, meetID int32) (map[int32]qualifier, bool, error) {
+	rules, err := srv.repo.GetQualificationRulesForTarget(ctx, meetID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(rules) == 0 {
+		return nil, false, nil
+	}
+
+	qualified := make(map[int32]qualifier)
+	for _, rule := range rules {
+		qs, err := srv.qualifiersFromMeet(ctx, rule)
+		if err != nil {
+			return nil, true, err
+		}
+		for _, q := range qs {
+			if _, ok := qualified[q.AthleteID]; !ok {
+				qualified[q.AthleteID] = q
+			}
+		}
+	}
+	return qualified, true, nil
+}
+
+// qualificationWarnings returns a warning when an athlete is entered in a
+// championship meet they did not qualify for.
+func (srv *server) qualificationWarnings(ctx context.Context, meetID, athleteID int32) ([]string, error) {
+	qualified, restricted, err := srv.qualifiedForMeet(ctx, meetID)
+	if err != nil || !restricted {
+		return []string{}, err
+	}
+	if _, ok := qualified[athleteID]; ok {
+		return []string{}, nil
+	}
+	return []string{fmt.Sprintf("Athlete %d has not qualified for this meet", athleteID)}, nil
+}
+
+func (srv *server) getQualificationRuleHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+
+	rule, err := srv.repo.GetQualificationRule(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Meet is not a qualifier"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	finishes, err := srv.repo.GetTeamFinishesByMeet(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	teamFinishes := make([]gin.H, len(finishes))
+	for i, f := range finishes {
+		teamFinishes[i] = gin.H{
+			"division": f.Division,
+			"place":    f.Place,
+			"score":    f.Score.Int32,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meetId":                   rule.MeetID,
+		"targetMeetId":             rule.TargetMeetID,
+		"topTeams":                 rule.TopTeams,
+		"topIndividuals":           rule.TopIndividuals,
+		"topIndividualsNotOnTeams": rule.TopIndividualsNotOnTeams,
+		"teamFinishes":             teamFinishes,
+	})
+}
+
+type QualificationRuleRequest struct {
+	TargetMeetID             int32 `json:"targetMeetId" binding:"required"`
+	TopTeams                 int32 `json:"topTeams" binding:"min=0"`
+	TopIndividuals           int32 `json:"topIndividuals" binding:"min=0"`
+	TopIndividualsNotOnTeams int32 `json:"topIndividualsNotOnTeams" binding:"min=0"`
+}
+
+func (srv *server) putQualificationRuleHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+
+	var req QualificationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.TargetMeetID == int32(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A meet cannot qualify athletes for itself"})
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+	if err := tx.DeleteQualificationRule(c.Request.Context(), int32(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	err = tx.CreateQualificationRule(c.Request.Context(), db.CreateQualificationRuleParams{
+		MeetID:                   int32(id),
+		TargetMeetID:             req.TargetMeetID,
+		TopTeams:                 req.TopTeams,
+		TopIndividuals:           req.TopIndividuals,
+		TopIndividualsNotOnTeams: req.TopIndividualsNotOnTeams,
+	})
+	if isForeignKeyViolation(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Meet not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meetId":                   id,
+		"targetMeetId":             req.TargetMeetID,
+		"topTeams":                 req.TopTeams,
+		"topIndividuals":           req.TopIndividuals,
+		"topIndividualsNotOnTeams": req.TopIndividualsNotOnTeams,
+	})
+}
+
+func (srv *server) deleteQualificationRuleHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+
+	if err := srv.repo.DeleteQualificationRule(c.Request.Context(), int32(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Qualification rule deleted"})
+}
+
+type TeamFinishRequest struct {
+	Place int32 `json:"place" binding:"required,min=1"`
+	Score int32 `json:"score"`
+}
+
+func (srv *server) putTeamFinishHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+	division := c.Param("division")
+	if !validDivision(division) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid division"})
+		return
+	}
+
+	var req TeamFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+	err = tx.DeleteTeamFinish(c.Request.Context(), db.DeleteTeamFinishParams{MeetID: int32(id), Division: division})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	err = tx.CreateTeamFinish(c.Request.Context(), db.CreateTeamFinishParams{
+		MeetID:   int32(id),
+		Division: division,
+		Place:    req.Place,
+		Score:    sql.NullInt32{Int32: req.Score, Valid: req.Score > 0},
+	})
+	if isForeignKeyViolation(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Meet not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meetId":   id,
+		"division": division,
+		"place":    req.Place,
+		"score":    req.Score,
+	})
+}
+
+// getAdvancersHandler lists who advanced from a qualifying meet.
+func (srv *server) getAdvancersHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+
+	rule, err := srv.repo.GetQualificationRule(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Meet is not a qualifier"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	qualifiers, err := srv.qualifiersFromMeet(c.Request.Context(), rule)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	names, err := srv.loadAthleteNames(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	advanced := make([]qualifier, 0, len(qualifiers))
+	for _, q := range qualifiers {
+		if names.hidden(q.AthleteID) {
+			continue
+		}
+		q.AthleteName = names.name(q.AthleteID, q.AthleteName)
+		advanced = append(advanced, q)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meetId":       rule.MeetID,
+		"targetMeetId": rule.TargetMeetID,
+		"advanced":     advanced,
+	})
+}
+
+// getQualifiedHandler lists who qualified into a championship meet from all
+// of the meets that feed it.
+func (srv *server) getQualifiedHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meet ID"})
+		return
+	}
+
+	qualified, restricted, err := srv.qualifiedForMeet(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	names, err := srv.loadAthleteNames(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	athletes := make([]qualifier, 0, len(qualified))
+	for _, q := range qualified {
+		if names.hidden(q.AthleteID) {
+			continue
+		}
+		q.AthleteName = names.name(q.AthleteID, q.AthleteName)
+		athletes = append(athletes, q)
+	}
+	sort.Slice(athletes, func(i, j int) bool {
+		if athletes[i].Division != athletes[j].Division {
+			return athletes[i].Division < athletes[j].Division
+		}
+		return athletes[i].Place < athletes[j].Place
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"meetId":       id,
+		"championship": restricted,
+		"qualified":    athletes,
+	})
+}
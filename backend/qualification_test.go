@@ -0,0 +1,70 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+
+	"jones-county-xc/backend/db"
+)
+
+func meetResult(athleteID int32, division string, place int32) db.GetMeetResultsRow {
+	return db.GetMeetResultsRow{
+		AthleteID:       athleteID,
+		MeetID:          1,
+		Time:            "18:00",
+		Place:           sql.NullInt32{Int32: place, Valid: place > 0},
+		AthleteDivision: sql.NullString{String: division, Valid: true},
+	}
+}
+
+func TestComputeQualifiers(t *testing.T) {
+	rule := db.QualificationRule{MeetID: 1, TopTeams: 2, TopIndividuals: 5, TopIndividualsNotOnTeams: 3}
+	results := []db.GetMeetResultsRow{
+		// Girls finished second as a team, so every girl advances.
+		meetResult(1, "girls", 40),
+		meetResult(2, "girls", 0),
+		// The boys team missed, so boys advance individually.
+		meetResult(10, "boys", 2),
+		meetResult(11, "boys", 9),
+		meetResult(12, "boys", 15),
+		meetResult(13, "boys", 20),
+		meetResult(14, "boys", 30),
+		meetResult(15, "boys", 0),
+		// A second result for a runner who already qualified.
+		meetResult(10, "boys", 4),
+	}
+	teamPlaces := map[string]int32{"girls": 2, "boys": 6}
+
+	got := computeQualifiers(rule, results, teamPlaces)
+	want := map[int32]string{
+		1:  qualifiedByTeam,
+		2:  qualifiedByTeam,
+		10: qualifiedByIndividual,
+		// 9th, 15th and 20th overall, but the first three of the runners
+		// left once the team and top-5 qualifiers are taken out.
+		11: qualifiedByIndividualNoTeam,
+		12: qualifiedByIndividualNoTeam,
+		13: qualifiedByIndividualNoTeam,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d qualifiers %+v, want %d", len(got), got, len(want))
+	}
+	for _, q := range got {
+		if reason, ok := want[q.AthleteID]; !ok || q.Reason != reason {
+			t.Errorf("athlete %d qualified by %q, want %q", q.AthleteID, q.Reason, reason)
+		}
+	}
+	if got[0].Division != "boys" || got[0].AthleteID != 10 {
+		t.Errorf("qualifiers not sorted by division and place: %+v", got)
+	}
+}
+
+func TestComputeQualifiersNoIndividualRules(t *testing.T) {
+	rule := db.QualificationRule{MeetID: 1, TopTeams: 1}
+	results := []db.GetMeetResultsRow{meetResult(1, "boys", 1), meetResult(2, "girls", 1)}
+
+	got := computeQualifiers(rule, results, map[string]int32{"boys": 1, "girls": 3})
+	if len(got) != 1 || got[0].AthleteID != 1 || got[0].Reason != qualifiedByTeam {
+		t.Errorf("got %+v, want only athlete 1 by team", got)
+	}
+}
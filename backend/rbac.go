@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// ROLES AND PERMISSIONS
+// =====================
+
+type permission string
+
+const (
+	// permPublic routes need no session at all.
+	permPublic permission = "public"
+	// permAccount covers a signed-in user's own session and password.
+	permAccount permission = "account"
+
+	permEnterResults   permission = "results:write"
+	permManageRoster   permission = "athletes:write"
+	permManageMeets    permission = "meets:write"
+	permManageSettings permission = "settings:write"
+	permManageUsers    permission = "users:write"
+	permViewAudit      permission = "audit:read"
+
+	// permViewAllAthletes allows reading private data for any athlete;
+	// permViewOwnAthletes only for the athletes linked to the account.
+	permViewAllAthletes permission = "athletes:read_private"
+	permViewOwnAthletes permission = "athletes:read_own"
+)
+
+// rolePermissions lists what each role may do. Anything not listed here is
+// denied.
+var rolePermissions = map[string][]permission{
+	roleHeadCoach: {
+		permAccount, permEnterResults, permManageRoster, permManageMeets,
+		permManageSettings, permManageUsers, permViewAudit, permViewAllAthletes, permViewOwnAthletes,
+	},
+	roleAssistant: {permAccount, permEnterResults, permViewAllAthletes},
+	roleAthlete:   {permAccount, permViewOwnAthletes},
+	roleParent:    {permAccount, permViewOwnAthletes},
+}
+
+func hasPermission(role string, perm permission) bool {
+	if perm == permPublic {
+		return true
+	}
+	return slices.Contains(rolePermissions[role], perm)
+}
+
+// permissionDenied checks the signed-in user against perm and returns the
+// status and body to reject the request with, or a zero status if allowed.
+// Users whose role requires two-factor authentication are limited to their
+// own account routes until they enroll.
+func (srv *server) permissionDenied(c *gin.Context, perm permission) (int, gin.H) {
+	user, ok := currentUser(c)
+	if !ok || !hasPermission(user.Role, perm) {
+		return http.StatusForbidden, gin.H{"error": "You do not have permission to do that"}
+	}
+	// API tokens are further limited to their scopes. A token doesn't stand
+	// in for two-factor enrollment, so its owner is checked like a session.
+	if token, ok := currentAPIToken(c); ok && !tokenAllows(token, perm) {
+		return http.StatusForbidden, gin.H{"error": "This API token does not have the " + string(perm) + " scope"}
+	}
+	if perm != permAccount && twoFactorRequired(user.Role) {
+		enabled, err := srv.twoFactorEnabled(c.Request.Context(), user.ID)
+		if err != nil {
+			return http.StatusInternalServerError, gin.H{"error": err.Error()}
+		}
+		if !enabled {
+			return http.StatusForbidden, gin.H{
+				"error":                  "Set up two-factor authentication to continue",
+				"twoFactorSetupRequired": true,
+			}
+		}
+	}
+	return 0, nil
+}
+
+// can reports whether the request's user, if any, holds perm. Public
+// handlers use it to decide how much to show.
+func (srv *server) can(c *gin.Context, perm permission) bool {
+	status, _ := srv.permissionDenied(c, perm)
+	return status == 0
+}
+
+// requirePermission must run after requireAuth.
+func (srv *server) requirePermission(perm permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if status, body := srv.permissionDenied(c, perm); status != 0 {
+			c.AbortWithStatusJSON(status, body)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// principal is someone calling the API in TestRouteAccess.
+type principal struct {
+	name string
+	// role is the session to send, or empty for an API token or nobody.
+	role string
+	// scopes, when set, make the request with a head coach API token that
+	// has them instead of a session.
+	scopes []string
+	// allowed lists the permissions the principal should get past. It is
+	// spelled out rather than read from rolePermissions, so a change there
+	// has to be made here too.
+	allowed []permission
+}
+
+var principals = []principal{
+	{name: "anonymous"},
+	{name: roleHeadCoach, role: roleHeadCoach, allowed: []permission{
+		permAccount, permEnterResults, permManageRoster, permManageMeets,
+		permManageSettings, permManageUsers, permViewAudit, permViewOwnAthletes,
+	}},
+	{name: roleAssistant, role: roleAssistant, allowed: []permission{permAccount, permEnterResults}},
+	{name: roleAthlete, role: roleAthlete, allowed: []permission{permAccount, permViewOwnAthletes}},
+	{name: roleParent, role: roleParent, allowed: []permission{permAccount, permViewOwnAthletes}},
+	// Scopes never grant account or user management, even to a head coach.
+	{name: "scoped token", scopes: []string{scopeReadAll, string(permManageMeets)}, allowed: []permission{
+		permManageMeets, permViewAudit, permViewOwnAthletes,
+	}},
+}
+
+// routeParams fills in a route's path parameters.
+var routeParams = strings.NewReplacer(
+	":id", "1",
+	":eventTypeId", "1",
+	":tokenId", "1",
+	":entity", auditAthlete,
+	":division", "boys",
+)
+
+// TestRouteAccess calls every route as every principal and checks it is
+// turned away with 401 or 403, or let through to the handler, as its
+// permission says. Requests carry no body, so most handlers that are reached
+// answer 400.
+func TestRouteAccess(t *testing.T) {
+	routes := newServer(defaultConfig(), nil, discardLogger()).routes()
+	for _, rt := range routes {
+		path := routeParams.Replace(rt.Path)
+		for _, p := range principals {
+			t.Run(rt.Method+" "+rt.Path+" as "+p.name, func(t *testing.T) {
+				ts := newTestServer(t)
+				var token string
+				switch {
+				case p.role != "":
+					token = ts.sessions[p.role]
+				case p.scopes != nil:
+					token, _ = ts.issueToken(roleHeadCoach, 0, p.scopes...)
+				}
+				rec := ts.doToken(rt.Method, path, token, nil)
+
+				switch {
+				case rt.Permission == permPublic || slices.Contains(p.allowed, rt.Permission):
+					if rec.Code == http.StatusUnauthorized || rec.Code == http.StatusForbidden {
+						t.Errorf("got %d %s, want the handler to be reached", rec.Code, rec.Body)
+					}
+				case token == "":
+					if rec.Code != http.StatusUnauthorized {
+						t.Errorf("got %d, want 401", rec.Code)
+					}
+				default:
+					if rec.Code != http.StatusForbidden {
+						t.Errorf("got %d, want 403", rec.Code)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestTwoFactorEnrollmentRequired checks that a head coach who hasn't set up
+// two-factor authentication can only reach their own account routes.
+func TestTwoFactorEnrollmentRequired(t *testing.T) {
+	ts := newTestServer(t)
+	if rec := ts.do(http.MethodDelete, "/api/users/1/2fa", roleHeadCoach, nil); rec.Code != http.StatusOK {
+		t.Fatalf("resetting two-factor: %d %s", rec.Code, rec.Body)
+	}
+	coach := ts.signIn(ts.users[roleHeadCoach])
+
+	if rec := ts.doToken(http.MethodGet, "/api/users", coach, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("GET /api/users: got %d, want 403", rec.Code)
+	}
+	if rec := ts.doToken(http.MethodPost, "/api/account/2fa/setup", coach, nil); rec.Code != http.StatusOK {
+		t.Errorf("POST /api/account/2fa/setup: got %d %s, want 200", rec.Code, rec.Body)
+	}
+}
+
+// TestMeetLimitedToken checks that a token limited to one meet can't enter
+// results for another.
+func TestMeetLimitedToken(t *testing.T) {
+	ts := newTestServer(t)
+	startMeet(ts)
+	token, _ := ts.issueToken(roleAssistant, 6, string(permEnterResults))
+
+	rec := ts.doToken(http.MethodPost, "/api/results", token, newResult)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got %d %s, want 403", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"jones-county-xc/backend/db"
+)
+
+// =====================
+// REPOSITORY
+// =====================
+
+// repository is the storage the handlers use: every generated query plus
+// transactions and the checks behind /readyz. sqlRepository implements it
+// over database/sql; tests can substitute a fake.
+type repository interface {
+	db.Querier
+	Begin(ctx context.Context) (repositoryTx, error)
+	Ping(ctx context.Context) error
+	// CheckSchema reports whether the database has exactly the migrations
+	// this build expects.
+	CheckSchema(ctx context.Context) error
+}
+
+// repositoryTx runs queries inside a transaction.
+type repositoryTx interface {
+	db.Querier
+	Commit() error
+	Rollback() error
+}
+
+type sqlRepository struct {
+	*db.Queries
+	conn       *sql.DB
+	migrations []migration
+}
+
+// newSQLRepository wraps conn. migrations are the ones CheckSchema expects;
+// commands that never serve requests may pass nil.
+func newSQLRepository(conn *sql.DB, migrations []migration) *sqlRepository {
+	return &sqlRepository{Queries: db.New(conn), conn: conn, migrations: migrations}
+}
+
+func (r *sqlRepository) Begin(ctx context.Context) (repositoryTx, error) {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{Queries: r.Queries.WithTx(tx), Tx: tx}, nil
+}
+
+func (r *sqlRepository) Ping(ctx context.Context) error {
+	return r.conn.PingContext(ctx)
+}
+
+func (r *sqlRepository) CheckSchema(ctx context.Context) error {
+	return checkSchema(ctx, r.conn, r.migrations)
+}
+
+// sqlTx gets its queries from *db.Queries and Commit and Rollback from
+// *sql.Tx.
+type sqlTx struct {
+	*db.Queries
+	*sql.Tx
+}
+
+var (
+	_ repository   = (*sqlRepository)(nil)
+	_ repositoryTx = (*sqlTx)(nil)
+)
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// ROUTES
+// =====================
+
+// route pairs an endpoint with the permission needed to call it. Every
+// endpoint is declared here so access rules can be reviewed in one place.
+type route struct {
+	Method     string
+	Path       string
+	Permission permission
+	Handler    gin.HandlerFunc
+}
+
+// routes binds every endpoint to this server's handlers.
+func (srv *server) routes() []route {
+	return []route{
+		// Probes for the load balancer. /health predates /livez.
+		{http.MethodGet, "/health", permPublic, srv.livezHandler},
+		{http.MethodGet, "/livez", permPublic, srv.livezHandler},
+		{http.MethodGet, "/readyz", permPublic, srv.readyzHandler},
+
+		// Event Types CRUD
+		{http.MethodGet, "/api/event-types", permPublic, srv.getEventTypesHandler},
+		{http.MethodGet, "/api/event-types/:id", permPublic, srv.getEventTypeByIDHandler},
+		{http.MethodPost, "/api/event-types", permManageSettings, srv.createEventTypeHandler},
+		{http.MethodPut, "/api/event-types/:id", permManageSettings, srv.updateEventTypeHandler},
+		{http.MethodDelete, "/api/event-types/:id", permManageSettings, srv.deleteEventTypeHandler},
+
+		// Athletes CRUD
+		{http.MethodGet, "/api/athletes", permPublic, srv.getAthletesHandler},
+		{http.MethodGet, "/api/athletes/:id", permPublic, srv.getAthleteByIDHandler},
+		{http.MethodGet, "/api/athletes/:id/results", permPublic, srv.getAthleteResultsHandler},
+		{http.MethodGet, "/api/athletes/:id/progression", permPublic, srv.getAthleteProgressionHandler},
+		{http.MethodGet, "/api/athletes/:id/standards", permPublic, srv.getAthleteStandardsHandler},
+		{http.MethodPost, "/api/athletes", permManageRoster, srv.createAthleteHandler},
+		{http.MethodPut, "/api/athletes/:id", permManageRoster, srv.updateAthleteHandler},
+		{http.MethodPut, "/api/athletes/:id/privacy", permManageRoster, srv.updateAthletePrivacyHandler},
+		{http.MethodDelete, "/api/athletes/:id", permManageRoster, srv.deleteAthleteHandler},
+
+		// Courses CRUD
+		{http.MethodGet, "/api/courses", permPublic, srv.getCoursesHandler},
+		{http.MethodGet, "/api/courses/:id", permPublic, srv.getCourseByIDHandler},
+		{http.MethodPost, "/api/courses", permManageSettings, srv.createCourseHandler},
+		{http.MethodPost, "/api/courses/recompute", permManageSettings, srv.recomputeCourseFactorsHandler},
+		{http.MethodPut, "/api/courses/:id", permManageSettings, srv.updateCourseHandler},
+		{http.MethodDelete, "/api/courses/:id", permManageSettings, srv.deleteCourseHandler},
+
+		// Meets CRUD
+		{http.MethodGet, "/api/meets", permPublic, srv.getMeetsHandler},
+		{http.MethodGet, "/api/meets/:id", permPublic, srv.getMeetByIDHandler},
+		{http.MethodGet, "/api/meets/:id/results", permPublic, srv.getResultsByMeetHandler},
+		{http.MethodGet, "/api/meets/:id/conditions", permPublic, srv.getMeetConditionsHandler},
+		{http.MethodPut, "/api/meets/:id/races/:eventTypeId/conditions", permManageMeets, srv.putRaceConditionsHandler},
+		{http.MethodDelete, "/api/meets/:id/races/:eventTypeId/conditions", permManageMeets, srv.deleteRaceConditionsHandler},
+		{http.MethodPost, "/api/meets", permManageMeets, srv.createMeetHandler},
+		{http.MethodPut, "/api/meets/:id", permManageMeets, srv.updateMeetHandler},
+		{http.MethodDelete, "/api/meets/:id", permManageMeets, srv.deleteMeetHandler},
+		{http.MethodPut, "/api/meets/:id/status", permManageMeets, srv.updateMeetStatusHandler},
+		{http.MethodPost, "/api/meets/:id/reschedule", permManageMeets, srv.rescheduleMeetHandler},
+		{http.MethodPost, "/api/meets/:id/official", permManageMeets, srv.markMeetOfficialHandler},
+		{http.MethodGet, "/api/meets/:id/history", permPublic, srv.getMeetHistoryHandler},
+
+		// Time standards
+		{http.MethodGet, "/api/standards", permPublic, srv.getStandardsHandler},
+		{http.MethodGet, "/api/standards/report", permPublic, srv.getStandardsReportHandler},
+		{http.MethodGet, "/api/standards/:id", permPublic, srv.getStandardByIDHandler},
+		{http.MethodPost, "/api/standards", permManageSettings, srv.createStandardHandler},
+		{http.MethodPut, "/api/standards/:id", permManageSettings, srv.updateStandardHandler},
+		{http.MethodDelete, "/api/standards/:id", permManageSettings, srv.deleteStandardHandler},
+
+		// Awards
+		{http.MethodGet, "/api/awards/rules", permPublic, srv.getAwardRulesHandler},
+		{http.MethodGet, "/api/awards/rules/:id", permPublic, srv.getAwardRuleByIDHandler},
+		{http.MethodPost, "/api/awards/rules", permManageSettings, srv.createAwardRuleHandler},
+		{http.MethodPut, "/api/awards/rules/:id", permManageSettings, srv.updateAwardRuleHandler},
+		{http.MethodDelete, "/api/awards/rules/:id", permManageSettings, srv.deleteAwardRuleHandler},
+		{http.MethodGet, "/api/awards/report", permPublic, srv.getAwardsReportHandler},
+
+		// Postseason qualification
+		{http.MethodGet, "/api/meets/:id/qualification", permPublic, srv.getQualificationRuleHandler},
+		{http.MethodPut, "/api/meets/:id/qualification", permManageMeets, srv.putQualificationRuleHandler},
+		{http.MethodDelete, "/api/meets/:id/qualification", permManageMeets, srv.deleteQualificationRuleHandler},
+		{http.MethodPut, "/api/meets/:id/team-finishes/:division", permManageMeets, srv.putTeamFinishHandler},
+		{http.MethodGet, "/api/meets/:id/advancers", permPublic, srv.getAdvancersHandler},
+		{http.MethodGet, "/api/meets/:id/qualified", permPublic, srv.getQualifiedHandler},
+
+		// Results CRUD
+		{http.MethodGet, "/api/results", permPublic, srv.getResultsHandler},
+		{http.MethodGet, "/api/results/all", permPublic, srv.getAllResultsHandler},
+		{http.MethodGet, "/api/results/top10", permPublic, srv.getTopTenFastestHandler},
+		{http.MethodPost, "/api/results", permEnterResults, srv.createResultHandler},
+		{http.MethodPut, "/api/results/:id", permEnterResults, srv.updateResultHandler},
+		{http.MethodDelete, "/api/results/:id", permEnterResults, srv.deleteResultHandler},
+
+		// Statistics
+		{http.MethodGet, "/api/stats/team", permPublic, srv.getTeamStatsHandler},
+
+		// Audit log
+		{http.MethodGet, "/api/audit", permViewAudit, srv.getAuditLogHandler},
+		{http.MethodGet, "/api/audit/:entity/:id", permViewAudit, srv.getEntityHistoryHandler},
+
+		// Auth
+		{http.MethodPost, "/api/login", permPublic, srv.loginHandler},
+		{http.MethodPost, "/api/login/2fa", permPublic, srv.loginTwoFactorHandler},
+		{http.MethodGet, "/api/auth/oidc", permPublic, srv.getOIDCInfoHandler},
+		{http.MethodGet, "/api/auth/oidc/login", permPublic, srv.oidcLoginHandler},
+		{http.MethodGet, "/api/auth/oidc/callback", permPublic, srv.oidcCallbackHandler},
+		{http.MethodPost, "/api/invites/accept", permPublic, srv.acceptInviteHandler},
+		{http.MethodPost, "/api/password-reset", permPublic, srv.requestPasswordResetHandler},
+		{http.MethodPost, "/api/password-reset/confirm", permPublic, srv.resetPasswordHandler},
+		{http.MethodGet, "/api/session", permAccount, srv.getSessionHandler},
+		{http.MethodPost, "/api/session/refresh", permAccount, srv.refreshSessionHandler},
+		{http.MethodPost, "/api/logout", permAccount, srv.logoutHandler},
+		{http.MethodPost, "/api/account/password", permAccount, srv.changePasswordHandler},
+		{http.MethodGet, "/api/account/2fa", permAccount, srv.getTwoFactorHandler},
+		{http.MethodPost, "/api/account/2fa/setup", permAccount, srv.setupTwoFactorHandler},
+		{http.MethodPost, "/api/account/2fa/confirm", permAccount, srv.confirmTwoFactorHandler},
+		{http.MethodPost, "/api/account/2fa/recovery-codes", permAccount, srv.regenerateRecoveryCodesHandler},
+		{http.MethodDelete, "/api/account/2fa", permAccount, srv.disableTwoFactorHandler},
+		{http.MethodGet, "/api/account/tokens", permAccount, srv.getMyAPITokensHandler},
+		{http.MethodPost, "/api/account/tokens", permAccount, srv.createMyAPITokenHandler},
+		{http.MethodDelete, "/api/account/tokens/:tokenId", permAccount, srv.revokeMyAPITokenHandler},
+		{http.MethodGet, "/api/me/athletes", permViewOwnAthletes, srv.getMyAthletesHandler},
+
+		// Users
+		{http.MethodGet, "/api/users", permManageUsers, srv.getUsersHandler},
+		{http.MethodGet, "/api/users/:id", permManageUsers, srv.getUserByIDHandler},
+		{http.MethodPost, "/api/users", permManageUsers, srv.createUserHandler},
+		{http.MethodPost, "/api/users/invite", permManageUsers, srv.inviteUserHandler},
+		{http.MethodPost, "/api/users/:id/invite", permManageUsers, srv.resendInviteHandler},
+		{http.MethodPut, "/api/users/:id", permManageUsers, srv.updateUserHandler},
+		{http.MethodPut, "/api/users/:id/password", permManageUsers, srv.resetUserPasswordHandler},
+		{http.MethodPost, "/api/users/:id/unlock", permManageUsers, srv.unlockUserHandler},
+		{http.MethodDelete, "/api/users/:id/2fa", permManageUsers, srv.resetUserTwoFactorHandler},
+		{http.MethodGet, "/api/users/:id/tokens", permManageUsers, srv.getUserAPITokensHandler},
+		{http.MethodPost, "/api/users/:id/tokens", permManageUsers, srv.createUserAPITokenHandler},
+		{http.MethodDelete, "/api/users/:id/tokens/:tokenId", permManageUsers, srv.revokeUserAPITokenHandler},
+		{http.MethodPut, "/api/users/:id/athletes", permManageUsers, srv.putUserAthletesHandler},
+		{http.MethodDelete, "/api/users/:id", permManageUsers, srv.deleteUserHandler},
+	}
+}
+
+// registerRoutes adds every route to the router. Public routes are open to
+// anyone but still see who is signed in, since that decides how much athlete
+// data they show; all others require a session whose role grants the route's
+// permission.
+func (srv *server) registerRoutes(r gin.IRoutes) {
+	for _, rt := range srv.routes() {
+		if rt.Permission == permPublic {
+			r.Handle(rt.Method, rt.Path, srv.identifyUser(), rt.Handler)
+			continue
+		}
+		r.Handle(rt.Method, rt.Path, srv.requireAuth(), srv.requirePermission(rt.Permission), rt.Handler)
+	}
+}
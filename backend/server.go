@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// HTTP SERVER
+// =====================
+
+// server holds everything the handlers need, so a test can build one around
+// a fake repository, a fixed clock or a memoryMailer.
+type server struct {
+	cfg           config
+	repo          repository
+	log           *slog.Logger
+	now           func() time.Time
+	mail          mailer
+	sessionSecret []byte
+	loginLimits   *loginLimiter
+
+	// oidcCached is set up on first use; see getOIDCClient.
+	oidcMu     sync.Mutex
+	oidcCached *oidcClient
+
+	// draining is set once shutdown starts, so /readyz turns traffic away.
+	draining atomic.Bool
+
+	// background tracks work started by handlers that outlives the request,
+	// such as sending password reset mail. serve waits for it on shutdown.
+	background sync.WaitGroup
+}
+
+// readyzTimeout bounds the database checks behind /readyz, so a hung
+// database fails the probe instead of stalling it.
+const readyzTimeout = 2 * time.Second
+
+func newServer(cfg config, repo repository, logger *slog.Logger) *server {
+	srv := &server{
+		cfg:           cfg,
+		repo:          repo,
+		log:           logger,
+		now:           time.Now,
+		sessionSecret: loadSessionSecret(cfg.SessionSecret, logger),
+	}
+	// Read srv.now on every call, so a clock swapped in after construction
+	// reaches the mailer and the limiter too.
+	clock := func() time.Time { return srv.now() }
+	srv.mail = newMailer(cfg.Mail, clock, logger)
+	// Login limits live in memory, so they reset when the server restarts.
+	srv.loginLimits = newLoginLimiter(newMemoryAttemptStore(clock), clock, logger)
+	return srv
+}
+
+// newRouter sets up gin with the configured logging and CORS policy and
+// registers every API route.
+func (srv *server) newRouter() *gin.Engine {
+	cfg := srv.cfg
+	if cfg.LogLevel != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	r := gin.New()
+	// Client IPs feed the login limits, so X-Forwarded-For is only believed
+	// from the configured proxies. With none, the peer address is used.
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic(err) // checked by config.normalize
+	}
+	// Request logs are info level, so they go quiet at warn and above.
+	if cfg.LogLevel == "debug" || cfg.LogLevel == "info" {
+		r.Use(gin.Logger())
+	}
+	r.Use(gin.Recovery())
+	if len(cfg.CORSOrigins) > 0 {
+		r.Use(corsMiddleware(cfg.CORSOrigins))
+	}
+
+	srv.registerRoutes(r)
+	return r
+}
+
+func newHTTPServer(cfg config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           handler,
+		ReadTimeout:       time.Duration(cfg.ReadTimeout),
+		ReadHeaderTimeout: time.Duration(cfg.ReadTimeout),
+		WriteTimeout:      time.Duration(cfg.WriteTimeout),
+		IdleTimeout:       time.Duration(cfg.IdleTimeout),
+	}
+}
+
+// serve runs httpServer until ctx is cancelled. It then fails /readyz for
+// cfg.ShutdownDelay while still serving, so the load balancer notices and
+// stops sending traffic, before it stops accepting connections and waits up
+// to cfg.ShutdownTimeout for in-flight requests to finish.
+func (srv *server) serve(ctx context.Context, httpServer *http.Server) error {
+	errs := make(chan error, 1)
+	go func() {
+		srv.log.Info("listening", "addr", httpServer.Addr)
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	srv.draining.Store(true)
+	if delay := time.Duration(srv.cfg.ShutdownDelay); delay > 0 {
+		srv.log.Info("draining before shutdown", "delay", delay)
+		select {
+		case err := <-errs:
+			return err
+		case <-time.After(delay):
+		}
+	}
+	srv.log.Info("shutting down", "timeout", time.Duration(srv.cfg.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(srv.cfg.ShutdownTimeout))
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutting down: %w", err)
+	}
+	srv.background.Wait()
+	srv.log.Info("stopped")
+	return nil
+}
+
+// corsMiddleware lets browsers on the allowed origins call the API. Only
+// named origins get credentials; "*" allows anyone to read without cookies.
+func corsMiddleware(origins []string) gin.HandlerFunc {
+	wildcard := slices.Contains(origins, "*")
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+		switch {
+		case slices.Contains(origins, origin):
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Credentials", "true")
+		case wildcard:
+			h.Set("Access-Control-Allow-Origin", "*")
+		default:
+			c.Next()
+			return
+		}
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			h.Set("Access-Control-Allow-Methods", strings.Join([]string{
+				http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+			}, ", "))
+			h.Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			h.Set("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"jones-county-xc/backend/db"
+)
+
+// testPassword is every fixture account's password.
+const testPassword = "password123"
+
+// testTOTPSecret is the head coach's authenticator secret.
+const testTOTPSecret = "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+
+// testFixtures is sample_data.sql without the MySQL-only statements, so it
+// loads on every engine.
+var testFixtures = []string{
+	`INSERT INTO athletes (name, grade, division, personal_record, events) VALUES
+		('Jaylen Carter', 12, 'boys', '16:24', '5K, 3200m'),
+		('Miguel Rodriguez', 12, 'boys', '16:51', '5K, 1600m'),
+		('Ethan Brooks', 11, 'boys', '17:08', '5K, 3200m'),
+		('Tyler Washington', 11, 'boys', '17:32', '5K'),
+		('Noah Patterson', 10, 'boys', '17:45', '5K, 1600m'),
+		('Caleb Morris', 10, 'boys', '18:12', '5K'),
+		('Isaiah Green', 9, 'boys', '18:45', '5K'),
+		('Brandon Lee', 9, 'boys', '19:22', '5K'),
+		('Emma Sullivan', 12, 'girls', '19:45', '5K, 3200m'),
+		('Olivia Chen', 11, 'girls', '20:18', '5K, 1600m'),
+		('Sophia Williams', 11, 'girls', '20:42', '5K'),
+		('Ava Martinez', 10, 'girls', '21:15', '5K'),
+		('Madison Taylor', 10, 'girls', '21:38', '5K'),
+		('Chloe Anderson', 9, 'girls', '22:05', '5K')`,
+	`INSERT INTO courses (name, location, description) VALUES
+		('Jones County High School', 'Gray, GA', 'Flat home course used for time trials'),
+		('Central City Park', 'Macon, GA', 'Fast, mostly flat park loop'),
+		('Panther Creek', 'Stockbridge, GA', 'Rolling course with a creek crossing'),
+		('Carrollton Elementary', 'Carrollton, GA', 'Hilly state championship course'),
+		('Rigby Field', 'Warner Robins, GA', 'Grass fields with a long finishing straight')`,
+	`INSERT INTO meets (name, date, location, description, course_id, status, official, championship) VALUES
+		('Jones County Time Trial', '2026-08-15', 'Gray, GA', 'Pre-season time trial at Jones County High School', 1, 'final', TRUE, FALSE),
+		('Peach State Invitational', '2026-09-05', 'Macon, GA', 'Season opener at Central City Park', 2, 'final', TRUE, FALSE),
+		('Panther Creek Invitational', '2026-09-12', 'Stockbridge, GA', 'Hosted by Stockbridge High School', 3, 'final', TRUE, FALSE),
+		('Carrollton Orthopedic Invitational', '2026-09-19', 'Carrollton, GA', 'One of Georgia largest XC meets', 4, 'final', TRUE, FALSE),
+		('Region 4-AAAAA Championship', '2026-10-22', 'Warner Robins, GA', 'Regional championship at Rigby Field', 5, 'scheduled', FALSE, TRUE),
+		('GHSA 5A State Championship', '2026-11-07', 'Carrollton, GA', 'State finals at Carrollton Elementary', 4, 'scheduled', FALSE, TRUE)`,
+	`INSERT INTO results (athlete_id, meet_id, event_type_id, time, place) VALUES
+		(1, 1, 1, '16:42', 1), (2, 1, 1, '17:05', 2), (3, 1, 1, '17:28', 3),
+		(4, 1, 1, '17:51', 4), (5, 1, 1, '18:02', 5), (6, 1, 1, '18:33', 6),
+		(7, 1, 1, '19:12', 7), (8, 1, 1, '19:45', 8), (9, 1, 1, '20:15', 1),
+		(10, 1, 1, '20:48', 2), (11, 1, 1, '21:05', 3), (12, 1, 1, '21:42', 4),
+		(13, 1, 1, '22:01', 5), (14, 1, 1, '22:38', 6)`,
+	`INSERT INTO results (athlete_id, meet_id, event_type_id, time, place) VALUES
+		(1, 2, 1, '16:31', 3), (2, 2, 1, '16:58', 8), (3, 2, 1, '17:15', 12),
+		(4, 2, 1, '17:42', 18), (5, 2, 1, '17:55', 22), (9, 2, 1, '20:02', 5),
+		(10, 2, 1, '20:35', 9), (11, 2, 1, '20:58', 14)`,
+	`INSERT INTO results (athlete_id, meet_id, event_type_id, time, place) VALUES
+		(1, 3, 1, '16:24', 2), (2, 3, 1, '16:51', 6), (3, 3, 1, '17:08', 11),
+		(4, 3, 1, '17:35', 19), (5, 3, 1, '17:48', 24), (6, 3, 1, '18:15', 31),
+		(9, 3, 1, '19:52', 4), (10, 3, 1, '20:22', 8), (11, 3, 1, '20:45', 12),
+		(12, 3, 1, '21:18', 18)`,
+	`INSERT INTO qualification_rules (meet_id, target_meet_id, top_teams, top_individuals, top_individuals_not_on_teams) VALUES
+		(5, 6, 4, 0, 10)`,
+	`INSERT INTO time_standards (name, event_type_id, division, time, category) VALUES
+		('Sub-17 Club', 1, 'boys', '16:59', 'milestone'),
+		('Sub-20 Club', 1, 'girls', '19:59', 'milestone'),
+		('State Meet Standard', 1, 'boys', '17:30', 'state'),
+		('State Meet Standard', 1, 'girls', '20:30', 'state'),
+		('Varsity Letter Standard', 1, 'boys', '18:30', 'letter'),
+		('Varsity Letter Standard', 1, 'girls', '21:30', 'letter')`,
+	`INSERT INTO award_rules (name, award_type, criterion, threshold, winners, division) VALUES
+		('Varsity Letter', 'letter', 'varsity_races', 3, 1, NULL),
+		('Varsity Letter (Championship)', 'letter', 'championship_score', 1, 1, NULL),
+		('Most Improved', 'most_improved', NULL, 2, 1, 'boys'),
+		('Most Improved', 'most_improved', NULL, 2, 1, 'girls'),
+		('Top Newcomer', 'top_newcomer', NULL, 1, 1, 'boys'),
+		('Top Newcomer', 'top_newcomer', NULL, 1, 1, 'girls')`,
+}
+
+// testUsers are the fixture accounts, one per role. The athlete account is
+// linked to Jaylen Carter (athlete 1) and the parent to Emma Sullivan
+// (athlete 9). The head coach has two-factor authentication set up with
+// testTOTPSecret.
+var testUsers = []struct {
+	username, role string
+	athleteID      int32
+}{
+	{"coach", roleHeadCoach, 0},
+	{"assistant", roleAssistant, 0},
+	{"runner", roleAthlete, 1},
+	{"parent", roleParent, 9},
+}
+
+// testPasswordHash is computed once, since bcrypt is slow by design.
+var testPasswordHash = sync.OnceValue(func() string {
+	hash, err := hashPassword(testPassword)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// testServer is a server over a freshly migrated database holding the
+// fixtures, with a fixed clock and a memoryMailer.
+type testServer struct {
+	*server
+	t       *testing.T
+	handler http.Handler
+	conn    *sql.DB
+	clock   *testClock
+	mailbox *memoryMailer
+	// users and sessions are keyed by role.
+	users    map[string]db.User
+	sessions map[string]string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	ctx := context.Background()
+	conn, driver := openTestDatabase(t)
+
+	migrations, err := loadMigrations(driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateUp(ctx, conn, migrations, 0, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range testFixtures {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("loading fixtures: %v", err)
+		}
+	}
+
+	cfg := defaultConfig()
+	cfg.Driver = driver
+	cfg.SessionSecret = "test-session-secret"
+	cfg.LogLevel = "warn"
+	srv := newServer(cfg, newSQLRepository(conn, migrations), discardLogger())
+	clock := &testClock{t: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	srv.now = clock.Now
+	mailbox := &memoryMailer{}
+	srv.mail = mailbox
+
+	ts := &testServer{
+		server:   srv,
+		t:        t,
+		handler:  srv.newRouter(),
+		conn:     conn,
+		clock:    clock,
+		mailbox:  mailbox,
+		users:    make(map[string]db.User),
+		sessions: make(map[string]string),
+	}
+	for _, u := range testUsers {
+		ts.users[u.role] = ts.createUser(u.username, u.role, u.athleteID)
+		ts.sessions[u.role] = ts.signIn(ts.users[u.role])
+	}
+	coach := ts.users[roleHeadCoach].ID
+	if err := srv.repo.CreateUserTOTP(ctx, db.CreateUserTOTPParams{UserID: coach, Secret: testTOTPSecret}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := srv.repo.ConfirmUserTOTP(ctx, db.ConfirmUserTOTPParams{UserID: coach, LastUsedStep: 1}); err != nil || n != 1 {
+		t.Fatalf("confirming two-factor: %d rows, %v", n, err)
+	}
+	return ts
+}
+
+// openTestDatabase opens an empty in-memory SQLite database, a fresh
+// PostgreSQL schema when XC_TEST_POSTGRES_DSN is set, or a fresh MySQL
+// database when XC_TEST_MYSQL_DSN is set. The schema or database is dropped
+// when the test ends.
+func openTestDatabase(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	if dsn := os.Getenv("XC_TEST_POSTGRES_DSN"); dsn != "" {
+		return openTestPostgres(t, dsn), driverPostgres
+	}
+	if dsn := os.Getenv("XC_TEST_MYSQL_DSN"); dsn != "" {
+		return openTestMySQL(t, dsn), driverMySQL
+	}
+	conn, err := openDatabase(driverSQLite, ":memory:", discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, driverSQLite
+}
+
+func openTestPostgres(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
+	admin, err := openDatabase(driverPostgres, dsn, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	schema := fmt.Sprintf("xc_test_%d", rand.Uint64())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("dropping schema %s: %v", schema, err)
+		}
+	})
+
+	conn, err := openDatabase(driverPostgres, withSearchPath(dsn, schema), discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func openTestMySQL(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// loadConfig sets this for the server; the tests skip loadConfig.
+	cfg.ParseTime = true
+	admin, err := openDatabase(driverMySQL, cfg.FormatDSN(), discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	name := fmt.Sprintf("xc_test_%d", rand.Uint64())
+	if _, err := admin.Exec("CREATE DATABASE " + name); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP DATABASE " + name); err != nil {
+			t.Errorf("dropping database %s: %v", name, err)
+		}
+	})
+
+	cfg.DBName = name
+	conn, err := openDatabase(driverMySQL, cfg.FormatDSN(), discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// withSearchPath adds a search_path run-time parameter to a PostgreSQL URL
+// or keyword/value DSN.
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return dsn + " search_path=" + schema
+}
+
+func (ts *testServer) createUser(username, role string, athleteID int32) db.User {
+	ts.t.Helper()
+	ctx := context.Background()
+	result, err := ts.repo.CreateUser(ctx, db.CreateUserParams{
+		Username:     username,
+		PasswordHash: testPasswordHash(),
+		Email:        sql.NullString{String: username + "@example.org", Valid: true},
+		Role:         role,
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	id, _ := result.LastInsertId()
+	if athleteID > 0 {
+		if err := ts.repo.AddUserAthlete(ctx, db.AddUserAthleteParams{UserID: int32(id), AthleteID: athleteID}); err != nil {
+			ts.t.Fatal(err)
+		}
+	}
+	user, err := ts.repo.GetUserByID(ctx, int32(id))
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return user
+}
+
+// signIn stores a session for user the way login does and returns its token.
+func (ts *testServer) signIn(user db.User) string {
+	ts.t.Helper()
+	token, tokenHash, err := ts.newSessionToken()
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	_, err = ts.repo.CreateSession(context.Background(), db.CreateSessionParams{
+		TokenHash: tokenHash,
+		UserID:    user.ID,
+		ExpiresAt: ts.now().Add(sessionTTL).UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return token
+}
+
+// do sends a request as role, or anonymously when role is empty. body is
+// sent as JSON unless it is a string, which is sent as is.
+func (ts *testServer) do(method, path, role string, body any) *httptest.ResponseRecorder {
+	ts.t.Helper()
+	var token string
+	if role != "" {
+		var ok bool
+		if token, ok = ts.sessions[role]; !ok {
+			ts.t.Fatalf("no session for role %q", role)
+		}
+	}
+	return ts.doToken(method, path, token, body)
+}
+
+func (ts *testServer) doToken(method, path, token string, body any) *httptest.ResponseRecorder {
+	ts.t.Helper()
+	var reader *strings.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = strings.NewReader("")
+	case string:
+		reader = strings.NewReader(b)
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			ts.t.Fatal(err)
+		}
+		reader = strings.NewReader(string(raw))
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	ts.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode unmarshals a response body into v.
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+}
+
+// count returns the number of rows in table matching where.
+func (ts *testServer) count(table, where string, args ...any) int {
+	ts.t.Helper()
+	var n int
+	query := "SELECT COUNT(*) FROM " + table
+	if where != "" {
+		query += " WHERE " + where
+	}
+	if err := ts.conn.QueryRow(query, args...).Scan(&n); err != nil {
+		ts.t.Fatal(err)
+	}
+	return n
+}
+
+// totp returns the authenticator code for secret at the test clock's time.
+func (ts *testServer) totp(secret string) string {
+	ts.t.Helper()
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return totpCode(key, totpStep(ts.now()))
+}
+
+// issueToken stores an API token for the role's user and returns it with
+// its ID.
+func (ts *testServer) issueToken(role string, meetID int32, scopes ...string) (string, int32) {
+	ts.t.Helper()
+	token, tokenHash, err := newAPIToken()
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	result, err := ts.repo.CreateAPIToken(context.Background(), db.CreateAPITokenParams{
+		UserID:      ts.users[role].ID,
+		Name:        "test",
+		TokenHash:   tokenHash,
+		TokenPrefix: token[:len(apiTokenPrefix)+6],
+		Scopes:      strings.Join(scopes, " "),
+		MeetID:      sql.NullInt32{Int32: meetID, Valid: meetID > 0},
+		ExpiresAt:   sql.NullTime{Time: ts.now().Add(24 * time.Hour).UTC().Truncate(time.Second), Valid: true},
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	id, _ := result.LastInsertId()
+	return token, int32(id)
+}
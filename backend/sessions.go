@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// SESSIONS
+// =====================
+
+const (
+	sessionCookieName = "xc_session"
+	sessionTTL        = 12 * time.Hour
+
+	// sessionTouchInterval limits how often a session's last-seen time is
+	// written back, so every authenticated request doesn't cost an UPDATE.
+	sessionTouchInterval = 5 * time.Minute
+
+	contextUserKey    = "user"
+	contextSessionKey = "session"
+)
+
+// loadSessionSecret uses the configured secret. Without one a random secret is
+// generated, which logs everyone out whenever the server restarts.
+func loadSessionSecret(secret string, logger *slog.Logger) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+	logger.Warn("no session secret is configured; sessions will not survive a restart")
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		log.Fatal("Failed to generate session secret:", err)
+	}
+	return random
+}
+
+// Tokens have the form <id>.<signature>, where id is 32 random bytes and the
+// signature is an HMAC of the id. The database stores a SHA-256 of the id.
+func (srv *server) newSessionToken() (token, tokenHash string, err error) {
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(id)
+	return encoded + "." + srv.signSessionID(encoded), hashSessionID(encoded), nil
+}
+
+func (srv *server) signSessionID(id string) string {
+	mac := hmac.New(sha256.New, srv.sessionSecret)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func hashSessionID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// verifySessionToken checks a token's signature and returns the hash to look
+// it up by.
+func (srv *server) verifySessionToken(token string) (string, bool) {
+	id, sig, ok := strings.Cut(token, ".")
+	if !ok || id == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(sig), []byte(srv.signSessionID(id))) {
+		return "", false
+	}
+	return hashSessionID(id), true
+}
+
+// requestToken returns the session token from an Authorization: Bearer header
+// or, failing that, the session cookie.
+func requestToken(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	token, _ := c.Cookie(sessionCookieName)
+	return token
+}
+
+// secureCookies reports whether cookies should be marked Secure. TLS usually
+// ends at the load balancer, so an https app_url decides rather than the
+// connection the request arrived on.
+func (srv *server) secureCookies(c *gin.Context) bool {
+	return strings.HasPrefix(srv.cfg.AppURL, "https://") || c.Request.TLS != nil
+}
+
+func (srv *server) setSessionCookie(c *gin.Context, token string, expires time.Time) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		MaxAge:   int(expires.Sub(srv.now()).Seconds()),
+		HttpOnly: true,
+		Secure:   srv.secureCookies(c),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (srv *server) clearSessionCookie(c *gin.Context) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   srv.secureCookies(c),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// startSession stores a new session for the user and sets the cookie. The
+// token is also returned so API clients can send it as a bearer token.
+func (srv *server) startSession(c *gin.Context, user db.User) (string, time.Time, error) {
+	token, tokenHash, err := srv.newSessionToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expires := srv.now().Add(sessionTTL).UTC().Truncate(time.Second)
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	_, err = srv.repo.CreateSession(c.Request.Context(), db.CreateSessionParams{
+		TokenHash: tokenHash,
+		UserID:    user.ID,
+		ExpiresAt: expires,
+		UserAgent: sql.NullString{String: userAgent, Valid: userAgent != ""},
+		IpAddress: sql.NullString{String: c.ClientIP(), Valid: c.ClientIP() != ""},
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	srv.setSessionCookie(c, token, expires)
+	return token, expires, nil
+}
+
+// loadSession resolves the request's token to a live session and its user.
+// It returns sql.ErrNoRows for any missing, invalid or expired session.
+func (srv *server) loadSession(c *gin.Context) (db.Session, db.User, error) {
+	tokenHash, ok := srv.verifySessionToken(requestToken(c))
+	if !ok {
+		return db.Session{}, db.User{}, sql.ErrNoRows
+	}
+
+	ctx := c.Request.Context()
+	session, err := srv.repo.GetSessionByTokenHash(ctx, tokenHash)
+	if err != nil {
+		return db.Session{}, db.User{}, err
+	}
+	if srv.now().After(session.ExpiresAt) {
+		srv.repo.DeleteSession(ctx, session.ID)
+		return db.Session{}, db.User{}, sql.ErrNoRows
+	}
+
+	user, err := srv.repo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return db.Session{}, db.User{}, err
+	}
+	if !user.Active {
+		return db.Session{}, db.User{}, sql.ErrNoRows
+	}
+
+	if !session.LastSeenAt.Valid || srv.now().Sub(session.LastSeenAt.Time) > sessionTouchInterval {
+		now := sql.NullTime{Time: srv.now().UTC().Truncate(time.Second), Valid: true}
+		if err := srv.repo.TouchSession(ctx, db.TouchSessionParams{ID: session.ID, LastSeenAt: now}); err == nil {
+			session.LastSeenAt = now
+		}
+	}
+	return session, user, nil
+}
+
+// authenticateRequest resolves the request's API token or session and makes its
+// user available to handlers through currentUser. It returns sql.ErrNoRows
+// when the request carries no valid credentials.
+func (srv *server) authenticateRequest(c *gin.Context) error {
+	if token := requestToken(c); strings.HasPrefix(token, apiTokenPrefix) {
+		apiToken, user, err := srv.loadAPIToken(c, token)
+		if err != nil {
+			return err
+		}
+		c.Set(contextUserKey, user)
+		c.Set(contextAPITokenKey, apiToken)
+		return nil
+	}
+
+	session, user, err := srv.loadSession(c)
+	if err != nil {
+		return err
+	}
+	c.Set(contextUserKey, user)
+	c.Set(contextSessionKey, session)
+	return nil
+}
+
+// requireAuth rejects requests without a valid session or API token.
+func (srv *server) requireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := srv.authenticateRequest(c); err != nil {
+			if err != sql.ErrNoRows {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			message := "Authentication required"
+			if strings.HasPrefix(requestToken(c), apiTokenPrefix) {
+				message = "Invalid or expired API token"
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+			return
+		}
+		c.Next()
+	}
+}
+
+// identifyUser is the public routes' counterpart to requireAuth: it makes
+// the signed-in user available when there is one, and otherwise serves the
+// request anonymously.
+func (srv *server) identifyUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if requestToken(c) != "" {
+			if err := srv.authenticateRequest(c); err != nil && err != sql.ErrNoRows {
+				srv.log.Warn("serving request anonymously, could not load session", "error", err)
+			}
+		}
+		c.Next()
+	}
+}
+
+func currentUser(c *gin.Context) (db.User, bool) {
+	v, ok := c.Get(contextUserKey)
+	if !ok {
+		return db.User{}, false
+	}
+	user, ok := v.(db.User)
+	return user, ok
+}
+
+func currentSession(c *gin.Context) (db.Session, bool) {
+	v, ok := c.Get(contextSessionKey)
+	if !ok {
+		return db.Session{}, false
+	}
+	session, ok := v.(db.Session)
+	return session, ok
+}
+
+func sessionResponse(user db.User, expires time.Time) gin.H {
+	response := userResponse(user)
+	response["expiresAt"] = expires
+	return response
+}
+
+func (srv *server) getSessionHandler(c *gin.Context) {
+	user, _ := currentUser(c)
+	session, _ := currentSession(c)
+	c.JSON(http.StatusOK, sessionResponse(user, session.ExpiresAt))
+}
+
+func (srv *server) logoutHandler(c *gin.Context) {
+	session, _ := currentSession(c)
+	if err := srv.repo.DeleteSession(c.Request.Context(), session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	srv.clearSessionCookie(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// refreshSessionHandler swaps the current session for a new one with a fresh
+// expiry. The old token stops working immediately.
+func (srv *server) refreshSessionHandler(c *gin.Context) {
+	user, _ := currentUser(c)
+	session, _ := currentSession(c)
+
+	token, expires, err := srv.startSession(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := srv.repo.DeleteSession(c.Request.Context(), session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := sessionResponse(user, expires)
+	response["token"] = token
+	c.JSON(http.StatusOK, response)
+}
+
+// pruneSessions periodically removes expired sessions until the process
+// exits.
+func (srv *server) pruneSessions(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := srv.repo.DeleteExpiredSessions(context.Background(), srv.now().UTC()); err != nil {
+			srv.log.Error("failed to prune expired sessions", "error", err)
+		}
+	}
+}
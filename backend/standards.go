@@ -0,0 +1,438 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// TIME STANDARDS
+// =====================
+
+type StandardRequest struct {
+	Name        string `json:"name" binding:"required"`
+	EventTypeID int32  `json:"eventTypeId" binding:"required"`
+	Division    string `json:"division" binding:"omitempty,oneof=boys girls"`
+	Time        string `json:"time" binding:"required"`
+	Category    string `json:"category" binding:"omitempty,oneof=milestone state letter"`
+	Description string `json:"description"`
+}
+
+func standardResponse(s db.TimeStandard) gin.H {
+	return gin.H{
+		"id":          s.ID,
+		"name":        s.Name,
+		"eventTypeId": s.EventTypeID,
+		"division":    nullableString(s.Division),
+		"time":        s.Time,
+		"category":    s.Category,
+		"description": s.Description.String,
+	}
+}
+
+func (srv *server) getStandardsHandler(c *gin.Context) {
+	standards, err := srv.repo.GetAllTimeStandards(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]gin.H, len(standards))
+	for i, s := range standards {
+		response[i] = standardResponse(s)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (srv *server) getStandardByIDHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid standard ID"})
+		return
+	}
+
+	standard, err := srv.repo.GetTimeStandardByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Standard not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, standardResponse(standard))
+}
+
+// bindStandard binds and validates a standard request, normalizing its time so
+// standards compare consistently against results.
+func bindStandard(c *gin.Context) (StandardRequest, bool) {
+	var req StandardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	seconds, err := parseRaceTime(req.Time)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time"})
+		return req, false
+	}
+	req.Time = formatRaceTime(seconds)
+	if req.Category == "" {
+		req.Category = "milestone"
+	}
+	return req, true
+}
+
+func (srv *server) createStandardHandler(c *gin.Context) {
+	req, ok := bindStandard(c)
+	if !ok {
+		return
+	}
+
+	result, err := srv.repo.CreateTimeStandard(c.Request.Context(), db.CreateTimeStandardParams{
+		Name:        req.Name,
+		EventTypeID: req.EventTypeID,
+		Division:    sql.NullString{String: req.Division, Valid: req.Division != ""},
+		Time:        req.Time,
+		Category:    req.Category,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+	})
+	if isForeignKeyViolation(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event type not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	c.JSON(http.StatusCreated, gin.H{
+		"id":          id,
+		"name":        req.Name,
+		"eventTypeId": req.EventTypeID,
+		"division":    req.Division,
+		"time":        req.Time,
+		"category":    req.Category,
+		"description": req.Description,
+	})
+}
+
+func (srv *server) updateStandardHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid standard ID"})
+		return
+	}
+
+	req, ok := bindStandard(c)
+	if !ok {
+		return
+	}
+
+	if _, err := srv.repo.GetTimeStandardByID(c.Request.Context(), int32(id)); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Standard not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = srv.repo.UpdateTimeStandard(c.Request.Context(), db.UpdateTimeStandardParams{
+		ID:          int32(id),
+		Name:        req.Name,
+		EventTypeID: req.EventTypeID,
+		Division:    sql.NullString{String: req.Division, Valid: req.Division != ""},
+		Time:        req.Time,
+		Category:    req.Category,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+	})
+	if isForeignKeyViolation(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event type not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          id,
+		"name":        req.Name,
+		"eventTypeId": req.EventTypeID,
+		"division":    req.Division,
+		"time":        req.Time,
+		"category":    req.Category,
+		"description": req.Description,
+	})
+}
+
+func (srv *server) deleteStandardHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid standard ID"})
+		return
+	}
+
+	if _, err := srv.repo.GetTimeStandardByID(c.Request.Context(), int32(id)); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Standard not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = srv.repo.DeleteTimeStandard(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Standard deleted"})
+}
+
+// =====================
+// STANDARD ACHIEVEMENTS
+// =====================
+
+// achievement records the first race in which an athlete met a standard.
+type achievement struct {
+	StandardID int32  `json:"standardId"`
+	Name       string `json:"name"`
+	Category   string `json:"category"`
+	Standard   string `json:"standard"`
+	AthleteID  int32  `json:"athleteId"`
+	Athlete    string `json:"athlete"`
+	ResultID   int32  `json:"resultId"`
+	MeetID     int32  `json:"meetId"`
+	MeetName   string `json:"meetName"`
+	Date       string `json:"date"`
+	Time       string `json:"time"`
+}
+
+// standardGap describes a standard an athlete has not yet met and how far
+// their best time in the event is from it.
+type standardGap struct {
+	StandardID int32   `json:"standardId"`
+	Name       string  `json:"name"`
+	Category   string  `json:"category"`
+	Standard   string  `json:"standard"`
+	BestTime   string  `json:"bestTime"`
+	GapSeconds float64 `json:"gapSeconds"`
+	HasRaced   bool    `json:"hasRaced"`
+}
+
+// standardAppliesTo reports whether a standard covers an athlete's results.
+// Standards without a division apply to everyone.
+func standardAppliesTo(s db.TimeStandard, division string) bool {
+	return !s.Division.Valid || s.Division.String == division
+}
+
+// firstAchievements finds, for each applicable athlete and standard, the
+// earliest result at or under the standard time. Results must be ordered by
+// meet date.
+func firstAchievements(standards []db.TimeStandard, results []historyResult) []achievement {
+	achievements := make([]achievement, 0)
+	for _, s := range standards {
+		target, err := parseRaceTime(s.Time)
+		if err != nil {
+			continue
+		}
+		seen := make(map[int32]bool)
+		for _, r := range results {
+			if seen[r.AthleteID] || r.EventTypeID != s.EventTypeID || !standardAppliesTo(s, r.Division) {
+				continue
+			}
+			if r.Seconds > target {
+				continue
+			}
+			seen[r.AthleteID] = true
+			achievements = append(achievements, achievement{
+				StandardID: s.ID,
+				Name:       s.Name,
+				Category:   s.Category,
+				Standard:   s.Time,
+				AthleteID:  r.AthleteID,
+				Athlete:    r.AthleteName,
+				ResultID:   r.ResultID,
+				MeetID:     r.MeetID,
+				MeetName:   r.MeetName,
+				Date:       r.Date.Format("2006-01-02"),
+				Time:       r.Time,
+			})
+		}
+	}
+	return achievements
+}
+
+func (srv *server) getAthleteStandardsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid athlete ID"})
+		return
+	}
+
+	athlete, err := srv.repo.GetAthleteByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Athlete not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	names, err := srv.loadAthleteNames(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkAthleteVisible(c, names, athlete.ID) {
+		return
+	}
+
+	standards, err := srv.repo.GetAllTimeStandards(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	all, err := srv.loadResultHistory(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var results []historyResult
+	best := make(map[int32]float64)
+	for _, r := range all {
+		if r.AthleteID != athlete.ID {
+			continue
+		}
+		results = append(results, r)
+		if b, ok := best[r.EventTypeID]; !ok || r.Seconds < b {
+			best[r.EventTypeID] = r.Seconds
+		}
+	}
+
+	division := athleteDivision(athlete.Division.String)
+	achieved := firstAchievements(standards, results)
+	met := make(map[int32]bool, len(achieved))
+	for _, a := range achieved {
+		met[a.StandardID] = true
+	}
+
+	remaining := make([]standardGap, 0)
+	for _, s := range standards {
+		if met[s.ID] || !standardAppliesTo(s, division) {
+			continue
+		}
+		target, err := parseRaceTime(s.Time)
+		if err != nil {
+			continue
+		}
+		gap := standardGap{
+			StandardID: s.ID,
+			Name:       s.Name,
+			Category:   s.Category,
+			Standard:   s.Time,
+		}
+		if b, ok := best[s.EventTypeID]; ok {
+			gap.BestTime = formatRaceTime(b)
+			gap.GapSeconds = round1(b - target)
+			gap.HasRaced = true
+		}
+		remaining = append(remaining, gap)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"athleteId":   athlete.ID,
+		"athleteName": names.name(athlete.ID, athlete.Name),
+		"achieved":    achieved,
+		"remaining":   remaining,
+	})
+}
+
+// getStandardsReportHandler lists every standard with the athletes who have
+// met it, ordered by when they first did. An optional season limits the
+// report to standards first achieved during that season.
+func (srv *server) getStandardsReportHandler(c *gin.Context) {
+	season := 0
+	if s := c.Query("season"); s != "" {
+		var err error
+		season, err = strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season"})
+			return
+		}
+	}
+
+	division := c.Query("division")
+	if division != "" && !validDivision(division) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid division"})
+		return
+	}
+
+	standards, err := srv.repo.GetAllTimeStandards(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	results, err := srv.loadResultHistory(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	names, err := srv.loadAthleteNames(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if division != "" {
+		filtered := results[:0]
+		for _, r := range results {
+			if r.Division == division {
+				filtered = append(filtered, r)
+			}
+		}
+		results = filtered
+	}
+
+	byStandard := make(map[int32][]achievement)
+	for _, a := range firstAchievements(standards, results) {
+		if (season != 0 && a.Date[:4] != strconv.Itoa(season)) || names.hidden(a.AthleteID) {
+			continue
+		}
+		a.Athlete = names.name(a.AthleteID, a.Athlete)
+		byStandard[a.StandardID] = append(byStandard[a.StandardID], a)
+	}
+
+	report := make([]gin.H, 0, len(standards))
+	for _, s := range standards {
+		if division != "" && s.Division.Valid && s.Division.String != division {
+			continue
+		}
+		achievers := byStandard[s.ID]
+		if achievers == nil {
+			achievers = make([]achievement, 0)
+		}
+		sort.SliceStable(achievers, func(i, j int) bool { return achievers[i].Date < achievers[j].Date })
+		entry := standardResponse(s)
+		entry["achievers"] = achievers
+		entry["count"] = len(achievers)
+		report = append(report, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":    season,
+		"standards": report,
+	})
+}
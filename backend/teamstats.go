@@ -0,0 +1,413 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// TEAM STATISTICS
+// =====================
+
+const (
+	divisionBoys       = "boys"
+	divisionGirls      = "girls"
+	divisionUnassigned = "unassigned"
+
+	// teamScorers is the number of runners that score for a cross country team.
+	teamScorers = 5
+)
+
+// defaultDepthTargets are the 5K times used to measure team depth when the
+// request names no target for the event. Other events have no default.
+var defaultDepthTargets = map[string]map[string]string{
+	"5K": {
+		divisionBoys:  "18:00",
+		divisionGirls: "21:00",
+	},
+}
+
+func validDivision(division string) bool {
+	return division == divisionBoys || division == divisionGirls
+}
+
+// teamResult is a parsed result row with the athlete context needed for
+// team-level statistics.
+type teamResult struct {
+	raceTime
+	AthleteID int32
+	Division  string
+}
+
+type meetTeamStats struct {
+	MeetID                int32   `json:"meetId"`
+	MeetName              string  `json:"meetName"`
+	Date                  string  `json:"date"`
+	EventTypeID           int32   `json:"eventTypeId"`
+	Event                 string  `json:"event"`
+	Finishers             int     `json:"finishers"`
+	AverageTopFive        string  `json:"averageTopFive"`
+	AverageTopFiveSeconds float64 `json:"averageTopFiveSeconds"`
+	Spread                string  `json:"spread"`
+	SpreadSeconds         float64 `json:"spreadSeconds"`
+	PRs                   int     `json:"prs"`
+	Attendance            int     `json:"attendance"`
+	AttendancePercent     float64 `json:"attendancePercent"`
+}
+
+type eventDepth struct {
+	EventTypeID         int32  `json:"eventTypeId"`
+	Event               string `json:"event"`
+	TargetTime          string `json:"targetTime"`
+	AthletesRaced       int    `json:"athletesRaced"`
+	AthletesUnderTarget int    `json:"athletesUnderTarget"`
+}
+
+type divisionStats struct {
+	Division          string          `json:"division"`
+	RosterSize        int             `json:"rosterSize"`
+	AverageAttendance float64         `json:"averageAttendancePercent"`
+	SpreadChange      float64         `json:"spreadChangeSeconds"`
+	Depth             []eventDepth    `json:"depth"`
+	Meets             []meetTeamStats `json:"meets"`
+}
+
+func (srv *server) getTeamStatsHandler(c *gin.Context) {
+	season := srv.now().Year()
+	if s := c.Query("season"); s != "" {
+		var err error
+		season, err = strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season"})
+			return
+		}
+	}
+
+	division := c.Query("division")
+	if division != "" && !validDivision(division) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid division"})
+		return
+	}
+
+	targets, ok := srv.depthTargets(c)
+	if !ok {
+		return
+	}
+
+	athletes, err := srv.repo.GetAllAthletes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	seasonEnd := time.Date(season, time.December, 31, 0, 0, 0, 0, time.UTC)
+	rows, err := srv.repo.GetResultsThrough(c.Request.Context(), seasonEnd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	roster := make(map[string]int)
+	for _, a := range athletes {
+		roster[athleteDivision(a.Division.String)]++
+	}
+
+	results := parseTeamResults(rows)
+	byDivision := make(map[string][]teamResult)
+	for _, r := range results {
+		byDivision[r.Division] = append(byDivision[r.Division], r)
+	}
+
+	names := []string{divisionBoys, divisionGirls, divisionUnassigned}
+	if division != "" {
+		names = []string{division}
+	}
+
+	stats := make([]divisionStats, 0, len(names))
+	for _, name := range names {
+		if division == "" && roster[name] == 0 && len(byDivision[name]) == 0 {
+			continue
+		}
+		stats = append(stats, buildDivisionStats(name, season, roster[name], targets[name], byDivision[name]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":    season,
+		"divisions": stats,
+	})
+}
+
+// depthTargets resolves the target times, in seconds by division and event
+// type, that team depth is measured against. Each ?standard=<id> uses a time
+// standard for its event and division; ?target=<time> with ?eventTypeId=<id>
+// sets one event's target for every division. Events left without a target
+// fall back to defaultDepthTargets. It answers the request itself when the
+// parameters are invalid.
+func (srv *server) depthTargets(c *gin.Context) (map[string]map[int32]float64, bool) {
+	ctx := c.Request.Context()
+	targets := make(map[string]map[int32]float64)
+	set := func(division string, eventTypeID int32, seconds float64, replace bool) {
+		if targets[division] == nil {
+			targets[division] = make(map[int32]float64)
+		}
+		if _, ok := targets[division][eventTypeID]; replace || !ok {
+			targets[division][eventTypeID] = seconds
+		}
+	}
+	divisions := []string{divisionBoys, divisionGirls, divisionUnassigned}
+
+	for _, s := range c.QueryArray("standard") {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid standard ID"})
+			return nil, false
+		}
+		standard, err := srv.repo.GetTimeStandardByID(ctx, int32(id))
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Standard not found"})
+			return nil, false
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		seconds, err := parseRaceTime(standard.Time)
+		if err != nil {
+			continue
+		}
+		for _, d := range divisions {
+			if standardAppliesTo(standard, d) {
+				set(d, standard.EventTypeID, seconds, true)
+			}
+		}
+	}
+
+	if t := c.Query("target"); t != "" {
+		seconds, err := parseRaceTime(t)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target time"})
+			return nil, false
+		}
+		eventTypeID, err := strconv.Atoi(c.Query("eventTypeId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A target time needs an eventTypeId"})
+			return nil, false
+		}
+		for _, d := range divisions {
+			set(d, int32(eventTypeID), seconds, true)
+		}
+	}
+
+	eventTypes, err := srv.repo.GetAllEventTypes(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	for _, et := range eventTypes {
+		for d, t := range defaultDepthTargets[et.Name] {
+			seconds, _ := parseRaceTime(t)
+			set(d, et.ID, seconds, false)
+		}
+	}
+	return targets, true
+}
+
+func athleteDivision(division string) string {
+	if validDivision(division) {
+		return division
+	}
+	return divisionUnassigned
+}
+
+func parseTeamResults(rows []db.GetResultsThroughRow) []teamResult {
+	results := make([]teamResult, 0, len(rows))
+	for _, r := range rows {
+		seconds, err := parseRaceTime(r.Time)
+		if err != nil {
+			continue
+		}
+		results = append(results, teamResult{
+			raceTime: raceTime{
+				ResultID:    r.ID,
+				MeetID:      r.MeetID,
+				MeetName:    r.MeetName,
+				Date:        r.MeetDate,
+				EventTypeID: r.EventTypeID.Int32,
+				Event:       r.EventName.String,
+				Time:        r.Time,
+				Seconds:     seconds,
+			},
+			AthleteID: r.AthleteID,
+			Division:  athleteDivision(r.AthleteDivision.String),
+		})
+	}
+	return results
+}
+
+type meetEventKey struct {
+	MeetID      int32
+	EventTypeID int32
+}
+
+type athleteEventKey struct {
+	AthleteID   int32
+	EventTypeID int32
+}
+
+// buildDivisionStats expects results ordered by meet date. Results from
+// earlier seasons are only used to decide whether a season time is a PR.
+// targets maps event type IDs to the depth target in seconds.
+func buildDivisionStats(division string, season, rosterSize int, targets map[int32]float64, results []teamResult) divisionStats {
+	stats := divisionStats{
+		Division:   division,
+		RosterSize: rosterSize,
+		Depth:      make([]eventDepth, 0),
+		Meets:      make([]meetTeamStats, 0),
+	}
+	var order []meetEventKey
+	groups := make(map[meetEventKey][]teamResult)
+	prs := make(map[meetEventKey]int)
+	bestSoFar := make(map[athleteEventKey]float64)
+	seasonBest := make(map[athleteEventKey]teamResult)
+
+	for _, r := range results {
+		ak := athleteEventKey{r.AthleteID, r.EventTypeID}
+		prev, raced := bestSoFar[ak]
+		if !raced || r.Seconds < prev {
+			bestSoFar[ak] = r.Seconds
+		}
+		if r.season() != season {
+			continue
+		}
+
+		mk := meetEventKey{r.MeetID, r.EventTypeID}
+		if _, ok := groups[mk]; !ok {
+			order = append(order, mk)
+		}
+		groups[mk] = append(groups[mk], r)
+		if raced && r.Seconds < prev {
+			prs[mk]++
+		}
+		if best, ok := seasonBest[ak]; !ok || r.Seconds < best.Seconds {
+			seasonBest[ak] = r
+		}
+	}
+
+	attendanceTotal := 0.0
+	for _, mk := range order {
+		group := groups[mk]
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Seconds < group[j].Seconds })
+
+		scorers := group[:min(teamScorers, len(group))]
+		seconds := make([]float64, len(scorers))
+		for i, r := range scorers {
+			seconds[i] = r.Seconds
+		}
+		avg := mean(seconds)
+		spread := seconds[len(seconds)-1] - seconds[0]
+
+		attended := make(map[int32]bool)
+		for _, r := range group {
+			attended[r.AthleteID] = true
+		}
+		attendancePct := 0.0
+		if rosterSize > 0 {
+			attendancePct = float64(len(attended)) / float64(rosterSize) * 100
+		}
+		attendanceTotal += attendancePct
+
+		stats.Meets = append(stats.Meets, meetTeamStats{
+			MeetID:                mk.MeetID,
+			MeetName:              group[0].MeetName,
+			Date:                  group[0].Date.Format("2006-01-02"),
+			EventTypeID:           mk.EventTypeID,
+			Event:                 group[0].Event,
+			Finishers:             len(group),
+			AverageTopFive:        formatRaceTime(avg),
+			AverageTopFiveSeconds: round1(avg),
+			Spread:                formatRaceTime(spread),
+			SpreadSeconds:         round1(spread),
+			PRs:                   prs[mk],
+			Attendance:            len(attended),
+			AttendancePercent:     round1(attendancePct),
+		})
+	}
+
+	if n := len(stats.Meets); n > 0 {
+		stats.AverageAttendance = round1(attendanceTotal / float64(n))
+		stats.SpreadChange = round1(stats.Meets[n-1].SpreadSeconds - stats.Meets[0].SpreadSeconds)
+	}
+
+	depth := make(map[int32]*eventDepth)
+	var events []int32
+	for ak, best := range seasonBest {
+		d, ok := depth[ak.EventTypeID]
+		if !ok {
+			d = &eventDepth{EventTypeID: ak.EventTypeID, Event: best.Event}
+			if target := targets[ak.EventTypeID]; target > 0 {
+				d.TargetTime = formatRaceTime(target)
+			}
+			depth[ak.EventTypeID] = d
+			events = append(events, ak.EventTypeID)
+		}
+		d.AthletesRaced++
+		if target := targets[ak.EventTypeID]; target > 0 && best.Seconds < target {
+			d.AthletesUnderTarget++
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i] < events[j] })
+	for _, id := range events {
+		stats.Depth = append(stats.Depth, *depth[id])
+	}
+
+	return stats
+}
+
+// historyResult is a parsed result from the full result history, used by
+// reports that look across every season.
+type historyResult struct {
+	teamResult
+	AthleteName  string
+	Championship bool
+}
+
+func (srv *server) loadResultHistory(ctx context.Context) ([]historyResult, error) {
+	rows, err := srv.repo.GetResultHistory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]historyResult, 0, len(rows))
+	for _, r := range rows {
+		seconds, err := parseRaceTime(r.Time)
+		if err != nil || !r.EventTypeID.Valid {
+			continue
+		}
+		results = append(results, historyResult{
+			teamResult: teamResult{
+				raceTime: raceTime{
+					ResultID:    r.ID,
+					MeetID:      r.MeetID,
+					MeetName:    r.MeetName,
+					Date:        r.MeetDate,
+					EventTypeID: r.EventTypeID.Int32,
+					Event:       r.EventName.String,
+					Time:        r.Time,
+					Seconds:     seconds,
+				},
+				AthleteID: r.AthleteID,
+				Division:  athleteDivision(r.AthleteDivision.String),
+			},
+			AthleteName:  r.AthleteName,
+			Championship: r.MeetChampionship,
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// raceTimeFormat is minutes:seconds or hours:minutes:seconds, in plain
+// decimal digits with optional fractional seconds. ParseFloat alone would
+// also take "NaN", "Inf" and hex floats.
+var raceTimeFormat = regexp.MustCompile(`^\d+(:\d+){1,2}(\.\d+)?$`)
+
+// parseRaceTime converts a stored race time ("16:42", "16:42.3" or
+// "1:02:15") into seconds.
+func parseRaceTime(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if !raceTimeFormat.MatchString(s) {
+		return 0, fmt.Errorf("invalid race time %q", s)
+	}
+
+	parts := strings.Split(s, ":")
+	total := 0.0
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil || math.IsInf(v, 0) {
+			return 0, fmt.Errorf("invalid race time %q", s)
+		}
+		if i > 0 && v >= 60 {
+			return 0, fmt.Errorf("invalid race time %q", s)
+		}
+		total = total*60 + v
+	}
+	return total, nil
+}
+
+// formatRaceTime renders seconds in the same "m:ss" form used for stored
+// times, adding an hour component when needed.
+func formatRaceTime(seconds float64) string {
+	total := int(math.Round(seconds))
+	if total < 0 {
+		total = 0
+	}
+	h, m, s := total/3600, (total%3600)/60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// round1 rounds to one decimal place for JSON output.
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// round2 rounds to two decimal places for JSON output.
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
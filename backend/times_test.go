@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestParseRaceTime(t *testing.T) {
+	valid := map[string]float64{
+		"16:42":     1002,
+		"16:42.3":   1002.3,
+		" 16:42 ":   1002,
+		"1:02:15":   3735,
+		"1:02:15.5": 3735.5,
+		"0:59":      59,
+	}
+	for in, want := range valid {
+		got, err := parseRaceTime(in)
+		if err != nil || got != want {
+			t.Errorf("parseRaceTime(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+
+	invalid := []string{
+		"", "16", "1:2:3:4", "16:60", "1:60:00", "16:-1", "-16:42", "+16:42",
+		"16:NaN", "16:nan", "16:Inf", "16:+Inf", "NaN:00", "16:0x1p4", "16:1e1",
+		"16:42.", "16.5:42", "16: 42", "16:42:", ":42",
+	}
+	for _, in := range invalid {
+		if got, err := parseRaceTime(in); err == nil {
+			t.Errorf("parseRaceTime(%q) = %v, want an error", in, got)
+		}
+	}
+}
@@ -0,0 +1,583 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =====================
+// TWO-FACTOR AUTHENTICATION
+// =====================
+
+const (
+	totpIssuer = "Jones County XC"
+	totpPeriod = 30
+	totpDigits = 6
+	// totpSkew is how many periods either side of now are accepted, to allow
+	// for clock drift on the user's phone.
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+	challengeTTL      = 5 * time.Minute
+)
+
+// rolesRequiringTwoFactor must enroll before using anything beyond their own
+// account routes.
+var rolesRequiringTwoFactor = []string{roleHeadCoach}
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func newTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// totpCode computes the RFC 6238 code for a time step (RFC 4226 HOTP with
+// HMAC-SHA1 and dynamic truncation).
+func totpCode(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for range totpDigits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+func totpStep(now time.Time) int64 {
+	return now.Unix() / totpPeriod
+}
+
+// verifyTOTP checks a code against the steps around now. Steps at or before
+// lastStep have already been used and are rejected so a code can't be
+// replayed. It returns the matching step.
+func verifyTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+	code = strings.ReplaceAll(code, " ", "")
+	current := totpStep(now)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if hmac.Equal([]byte(code), []byte(totpCode(key, step))) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpURI is the otpauth:// provisioning URI authenticator apps read from a
+// QR code.
+func totpURI(secret, username string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", totpIssuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", strconv.Itoa(totpDigits))
+	v.Set("period", strconv.Itoa(totpPeriod))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + totpIssuer + ":" + username,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+// newRecoveryCodes returns codes like "k3m9q-7tx2p" along with the hashes
+// to store.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz234567"
+	for range recoveryCodeCount {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		var b strings.Builder
+		for i, r := range raw {
+			if i == 5 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(alphabet[int(r)%len(alphabet)])
+		}
+		codes = append(codes, b.String())
+		hashes = append(hashes, hashRecoveryCode(b.String()))
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// replaceRecoveryCodes swaps out all of a user's recovery codes for new ones.
+func replaceRecoveryCodes(ctx context.Context, q db.Querier, userID int32) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := q.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return nil, err
+	}
+	for _, hash := range hashes {
+		if err := q.CreateRecoveryCode(ctx, db.CreateRecoveryCodeParams{UserID: userID, CodeHash: hash}); err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+// twoFactorEnabled reports whether the user has a confirmed authenticator.
+func (srv *server) twoFactorEnabled(ctx context.Context, userID int32) (bool, error) {
+	totp, err := srv.repo.GetUserTOTP(ctx, userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return totp.ConfirmedAt.Valid, nil
+}
+
+func twoFactorRequired(role string) bool {
+	return slices.Contains(rolesRequiringTwoFactor, role)
+}
+
+// Login challenges carry a user who has passed the password step to the code
+// step. They are signed like session tokens and expire after challengeTTL.
+func (srv *server) newLoginChallenge(userID int32, now time.Time) (string, time.Time) {
+	expires := now.Add(challengeTTL)
+	payload := base64.RawURLEncoding.EncodeToString(
+		[]byte(strconv.Itoa(int(userID)) + ":" + strconv.FormatInt(expires.Unix(), 10)))
+	return payload + "." + srv.signChallenge(payload), expires
+}
+
+func (srv *server) signChallenge(payload string) string {
+	mac := hmac.New(sha256.New, srv.sessionSecret)
+	mac.Write([]byte("login-challenge:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (srv *server) verifyLoginChallenge(challenge string, now time.Time) (int32, bool) {
+	payload, sig, ok := strings.Cut(challenge, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(srv.signChallenge(payload))) {
+		return 0, false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return 0, false
+	}
+	idPart, expiresPart, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		return 0, false
+	}
+	expires, err := strconv.ParseInt(expiresPart, 10, 64)
+	if err != nil || now.Unix() > expires {
+		return 0, false
+	}
+	return int32(id), true
+}
+
+type TwoFactorLoginRequest struct {
+	Challenge    string `json:"challenge"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// loginTwoFactorHandler is the second login step for users with two-factor
+// authentication: it trades a login challenge and a code for a session. After
+// single sign-on the challenge comes in a cookie instead of the body.
+func (srv *server) loginTwoFactorHandler(c *gin.Context) {
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.Code == "") == (req.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either a code or a recovery code"})
+		return
+	}
+
+	if req.Challenge == "" {
+		req.Challenge, _ = c.Cookie(oidcChallengeCookie)
+	}
+	now := srv.now()
+	userID, ok := srv.verifyLoginChallenge(req.Challenge, now)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login challenge is invalid or has expired"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := srv.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Login challenge is invalid or has expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !user.Active {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login challenge is invalid or has expired"})
+		return
+	}
+	attempt, ok := srv.reserveLogin(c, user.Username)
+	if !ok {
+		return
+	}
+	defer attempt.release()
+
+	totp, err := srv.repo.GetUserTOTP(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Spending the code only updates a row if no other request has spent
+	// it since it was read, so two requests racing with one code can't both
+	// get in.
+	verified := false
+	if req.Code != "" {
+		if step, ok := verifyTOTP(totp.Secret, req.Code, now, totp.LastUsedStep); ok {
+			var n int64
+			n, err = srv.repo.UpdateTOTPStep(ctx, db.UpdateTOTPStepParams{LastUsedStep: step, UserID: user.ID})
+			verified = n == 1
+		}
+	} else {
+		var code db.RecoveryCode
+		code, err = srv.repo.GetRecoveryCode(ctx, db.GetRecoveryCodeParams{
+			UserID:   user.ID,
+			CodeHash: hashRecoveryCode(req.RecoveryCode),
+		})
+		if err == nil {
+			var n int64
+			n, err = srv.repo.UseRecoveryCode(ctx, code.ID)
+			verified = n == 1
+		} else if err == sql.ErrNoRows {
+			err = nil
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !verified {
+		attempt.failure()
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	attempt.success()
+	srv.setChallengeCookie(c, "", -1)
+	srv.completeLogin(c, user, false)
+}
+
+func (srv *server) getTwoFactorHandler(c *gin.Context) {
+	user, _ := currentUser(c)
+	ctx := c.Request.Context()
+
+	enabled, err := srv.twoFactorEnabled(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	remaining, err := srv.repo.CountRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                enabled,
+		"required":               twoFactorRequired(user.Role),
+		"recoveryCodesRemaining": remaining,
+	})
+}
+
+// setupTwoFactorHandler starts enrollment with a fresh secret. Two-factor
+// authentication isn't switched on until the user confirms a code from it.
+func (srv *server) setupTwoFactorHandler(c *gin.Context) {
+	user, _ := currentUser(c)
+	ctx := c.Request.Context()
+
+	enabled, err := srv.twoFactorEnabled(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := srv.repo.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := tx.DeleteUserTOTP(ctx, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.CreateUserTOTP(ctx, db.CreateUserTOTPParams{UserID: user.ID, Secret: secret}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":          secret,
+		"provisioningUri": totpURI(secret, user.Username),
+	})
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// confirmTwoFactorHandler finishes enrollment and returns the recovery codes.
+// They are only ever shown here.
+func (srv *server) confirmTwoFactorHandler(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := currentUser(c)
+	ctx := c.Request.Context()
+
+	totp, err := srv.repo.GetUserTOTP(ctx, user.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusConflict, gin.H{"error": "Start two-factor setup first"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if totp.ConfirmedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	step, ok := verifyTOTP(totp.Secret, req.Code, srv.now(), totp.LastUsedStep)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	tx, err := srv.repo.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	n, err := tx.ConfirmUserTOTP(ctx, db.ConfirmUserTOTPParams{LastUsedStep: step, UserID: user.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if n == 0 {
+		// Another request confirmed with this code first.
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+	codes, err := replaceRecoveryCodes(ctx, tx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "recoveryCodes": codes})
+}
+
+// regenerateRecoveryCodesHandler replaces all recovery codes. A current code
+// is required so a hijacked session alone can't take over the account.
+func (srv *server) regenerateRecoveryCodesHandler(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := currentUser(c)
+	ctx := c.Request.Context()
+
+	totp, err := srv.repo.GetUserTOTP(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err == sql.ErrNoRows || !totp.ConfirmedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	step, ok := verifyTOTP(totp.Secret, req.Code, srv.now(), totp.LastUsedStep)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	tx, err := srv.repo.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	n, err := tx.UpdateTOTPStep(ctx, db.UpdateTOTPStepParams{LastUsedStep: step, UserID: user.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if n == 0 {
+		// Another request spent this code first.
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+	codes, err := replaceRecoveryCodes(ctx, tx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// disableTwoFactorHandler turns two-factor authentication off for the
+// signed-in user, who must confirm their password. Roles that require it
+// can't turn it off; a head coach can reset it for them instead.
+func (srv *server) disableTwoFactorHandler(c *gin.Context) {
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := currentUser(c)
+	if twoFactorRequired(user.Role) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is required for your role"})
+		return
+	}
+	if !checkPassword(user.PasswordHash, req.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+		return
+	}
+
+	if err := srv.removeTwoFactor(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": false})
+}
+
+// resetUserTwoFactorHandler removes another user's authenticator, e.g. after
+// a lost phone. They are signed out and must enroll again if their role
+// requires it.
+func (srv *server) resetUserTwoFactorHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := srv.repo.GetUserByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := srv.removeTwoFactor(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := srv.repo.DeleteUserSessions(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset"})
+}
+
+func (srv *server) removeTwoFactor(ctx context.Context, userID int32) error {
+	tx, err := srv.repo.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.DeleteUserTOTP(ctx, userID); err != nil {
+		return err
+	}
+	if err := tx.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"jones-county-xc/backend/db"
+)
+
+// rfc6238Secret is the SHA-1 key from RFC 6238 appendix B, base32 encoded.
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// RFC 6238 appendix B gives 8-digit codes; ours are their last 6 digits.
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, v := range vectors {
+		now := time.Unix(v.unix, 0)
+		if got := totpCode([]byte("12345678901234567890"), totpStep(now)); got != v.code {
+			t.Errorf("at %d: got %s, want %s", v.unix, got, v.code)
+		}
+		if _, ok := verifyTOTP(rfc6238Secret, v.code, now, 0); !ok {
+			t.Errorf("at %d: %s not accepted", v.unix, v.code)
+		}
+	}
+}
+
+func TestVerifyTOTPSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := totpStep(now)
+	key := []byte("12345678901234567890")
+
+	for offset := int64(-2); offset <= 2; offset++ {
+		got, ok := verifyTOTP(rfc6238Secret, totpCode(key, step+offset), now, 0)
+		want := offset >= -totpSkew && offset <= totpSkew
+		if ok != want {
+			t.Errorf("code %d steps away: accepted %v, want %v", offset, ok, want)
+		}
+		if ok && got != step+offset {
+			t.Errorf("code %d steps away: matched step %d, want %d", offset, got, step+offset)
+		}
+	}
+
+	if _, ok := verifyTOTP(rfc6238Secret, "05 04 71", now, 0); !ok {
+		t.Error("code with spaces not accepted")
+	}
+	if _, ok := verifyTOTP("not base32!", "050471", now, 0); ok {
+		t.Error("code accepted for an invalid secret")
+	}
+}
+
+func TestVerifyTOTPReplay(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := totpStep(now)
+	key := []byte("12345678901234567890")
+	code := totpCode(key, step)
+
+	used, ok := verifyTOTP(rfc6238Secret, code, now, 0)
+	if !ok {
+		t.Fatal("code not accepted")
+	}
+	if _, ok := verifyTOTP(rfc6238Secret, code, now, used); ok {
+		t.Error("code accepted again after its step was used")
+	}
+	// An earlier code still inside the skew window is spent too.
+	if _, ok := verifyTOTP(rfc6238Secret, totpCode(key, step-1), now, used); ok {
+		t.Error("earlier code accepted after a later step was used")
+	}
+	if _, ok := verifyTOTP(rfc6238Secret, totpCode(key, step+1), now, used); !ok {
+		t.Error("next code not accepted")
+	}
+}
+
+func TestLoginChallengeExpiry(t *testing.T) {
+	ts := newTestServer(t)
+	challenge, expires := ts.newLoginChallenge(coachID, ts.now())
+	if !expires.Equal(ts.now().Add(challengeTTL)) {
+		t.Errorf("expires %v, want %v", expires, ts.now().Add(challengeTTL))
+	}
+
+	ts.clock.Advance(challengeTTL)
+	if id, ok := ts.verifyLoginChallenge(challenge, ts.now()); !ok || id != coachID {
+		t.Errorf("got %d, %v at expiry, want %d", id, ok, coachID)
+	}
+	ts.clock.Advance(time.Second)
+	if _, ok := ts.verifyLoginChallenge(challenge, ts.now()); ok {
+		t.Error("challenge accepted after it expired")
+	}
+
+	if _, ok := ts.verifyLoginChallenge(challenge+"x", ts.now().Add(-time.Minute)); ok {
+		t.Error("challenge with a bad signature accepted")
+	}
+}
+
+// racingRepository spends each code right after the login handler reads it,
+// as another request sending the same code at the same moment would.
+type racingRepository struct {
+	repository
+	now func() time.Time
+}
+
+func (r racingRepository) GetUserTOTP(ctx context.Context, userID int32) (db.UserTotp, error) {
+	totp, err := r.repository.GetUserTOTP(ctx, userID)
+	if err == nil {
+		_, err = r.repository.UpdateTOTPStep(ctx, db.UpdateTOTPStepParams{LastUsedStep: totpStep(r.now()), UserID: userID})
+	}
+	return totp, err
+}
+
+func (r racingRepository) GetRecoveryCode(ctx context.Context, arg db.GetRecoveryCodeParams) (db.RecoveryCode, error) {
+	code, err := r.repository.GetRecoveryCode(ctx, arg)
+	if err == nil {
+		_, err = r.repository.UseRecoveryCode(ctx, code.ID)
+	}
+	return code, err
+}
+
+// loginCoach does the password step for the head coach and returns the
+// challenge.
+func loginCoach(ts *testServer) string {
+	ts.t.Helper()
+	rec := ts.do(http.MethodPost, "/api/login", "", obj{"username": "coach", "password": testPassword})
+	var body struct{ Challenge string }
+	decode(ts.t, rec, &body)
+	if body.Challenge == "" {
+		ts.t.Fatalf("no challenge: %d %s", rec.Code, rec.Body)
+	}
+	return body.Challenge
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	t.Run("expired challenge", func(t *testing.T) {
+		ts := newTestServer(t)
+		challenge := loginCoach(ts)
+		ts.clock.Advance(challengeTTL + time.Second)
+		rec := ts.do(http.MethodPost, "/api/login/2fa", "", obj{"challenge": challenge, "code": ts.totp(testTOTPSecret)})
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("got %d %s, want 401", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("replayed code", func(t *testing.T) {
+		ts := newTestServer(t)
+		code := ts.totp(testTOTPSecret)
+		rec := ts.do(http.MethodPost, "/api/login/2fa", "", obj{"challenge": loginCoach(ts), "code": code})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("first login: got %d %s, want 200", rec.Code, rec.Body)
+		}
+		rec = ts.do(http.MethodPost, "/api/login/2fa", "", obj{"challenge": loginCoach(ts), "code": code})
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("replay: got %d %s, want 401", rec.Code, rec.Body)
+		}
+
+		// The next period's code works.
+		ts.clock.Advance(totpPeriod * time.Second)
+		rec = ts.do(http.MethodPost, "/api/login/2fa", "", obj{"challenge": loginCoach(ts), "code": ts.totp(testTOTPSecret)})
+		if rec.Code != http.StatusOK {
+			t.Errorf("next code: got %d %s, want 200", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("clock skew", func(t *testing.T) {
+		ts := newTestServer(t)
+		// A phone one period behind the server.
+		code := ts.totp(testTOTPSecret)
+		ts.clock.Advance(totpPeriod * time.Second)
+		rec := ts.do(http.MethodPost, "/api/login/2fa", "", obj{"challenge": loginCoach(ts), "code": code})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("one period behind: got %d %s, want 200", rec.Code, rec.Body)
+		}
+
+		// Two periods behind is too far.
+		ts.clock.Advance(totpPeriod * time.Second)
+		code = ts.totp(testTOTPSecret)
+		ts.clock.Advance(2 * totpPeriod * time.Second)
+		rec = ts.do(http.MethodPost, "/api/login/2fa", "", obj{"challenge": loginCoach(ts), "code": code})
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("two periods behind: got %d %s, want 401", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("recovery code works once", func(t *testing.T) {
+		ts := newTestServer(t)
+		rec := ts.do(http.MethodPost, "/api/account/2fa/recovery-codes", roleHeadCoach, obj{"code": ts.totp(testTOTPSecret)})
+		var body struct{ RecoveryCodes []string }
+		decode(t, rec, &body)
+		if len(body.RecoveryCodes) != recoveryCodeCount {
+			t.Fatalf("got %d recovery codes, want %d: %s", len(body.RecoveryCodes), recoveryCodeCount, rec.Body)
+		}
+		code := body.RecoveryCodes[0]
+
+		rec = ts.do(http.MethodPost, "/api/login/2fa", "", obj{"challenge": loginCoach(ts), "recoveryCode": code})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("first use: got %d %s, want 200", rec.Code, rec.Body)
+		}
+		rec = ts.do(http.MethodPost, "/api/login/2fa", "", obj{"challenge": loginCoach(ts), "recoveryCode": code})
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("second use: got %d %s, want 401", rec.Code, rec.Body)
+		}
+
+		var status struct{ RecoveryCodesRemaining int }
+		decode(t, ts.do(http.MethodGet, "/api/account/2fa", roleHeadCoach, nil), &status)
+		if status.RecoveryCodesRemaining != recoveryCodeCount-1 {
+			t.Errorf("%d recovery codes left, want %d", status.RecoveryCodesRemaining, recoveryCodeCount-1)
+		}
+	})
+
+	t.Run("code spent by a concurrent request", func(t *testing.T) {
+		ts := newTestServer(t)
+		challenge := loginCoach(ts)
+		ts.repo = racingRepository{repository: ts.repo, now: ts.now}
+		rec := ts.do(http.MethodPost, "/api/login/2fa", "", obj{"challenge": challenge, "code": ts.totp(testTOTPSecret)})
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("got %d %s, want 401", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("recovery code spent by a concurrent request", func(t *testing.T) {
+		ts := newTestServer(t)
+		rec := ts.do(http.MethodPost, "/api/account/2fa/recovery-codes", roleHeadCoach, obj{"code": ts.totp(testTOTPSecret)})
+		var body struct{ RecoveryCodes []string }
+		decode(t, rec, &body)
+		if len(body.RecoveryCodes) == 0 {
+			t.Fatalf("no recovery codes: %d %s", rec.Code, rec.Body)
+		}
+
+		challenge := loginCoach(ts)
+		ts.repo = racingRepository{repository: ts.repo, now: ts.now}
+		rec = ts.do(http.MethodPost, "/api/login/2fa", "", obj{"challenge": challenge, "recoveryCode": body.RecoveryCodes[0]})
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("got %d %s, want 401", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("challenge in cookie", func(t *testing.T) {
+		ts := newTestServer(t)
+		challenge, _ := ts.newLoginChallenge(coachID, ts.now())
+		req := httptest.NewRequest(http.MethodPost, "/api/login/2fa",
+			strings.NewReader(`{"code": "`+ts.totp(testTOTPSecret)+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: oidcChallengeCookie, Value: challenge})
+		rec := httptest.NewRecorder()
+		ts.handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d %s, want 200", rec.Code, rec.Body)
+		}
+		cleared := false
+		for _, c := range rec.Result().Cookies() {
+			if c.Name == oidcChallengeCookie && c.MaxAge < 0 {
+				cleared = true
+			}
+		}
+		if !cleared {
+			t.Error("challenge cookie not cleared")
+		}
+	})
+}
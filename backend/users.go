@@ -0,0 +1,570 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// =====================
+// USER ACCOUNTS
+// =====================
+
+const (
+	roleHeadCoach = "head_coach"
+	roleAssistant = "assistant"
+	roleAthlete   = "athlete"
+	roleParent    = "parent"
+
+	minPasswordLength = 8
+	// bcrypt ignores everything past 72 bytes, so longer passwords are
+	// rejected rather than silently truncated.
+	maxPasswordLength = 72
+)
+
+// dummyPasswordHash is compared against when a login names an unknown user so
+// that response times don't reveal which usernames exist.
+const dummyPasswordHash = "$2a$10$l9pV46tVtxuxkD07.cjMHutkltujva/SLvW51YjMlynXGoR0L3Jwi"
+
+var (
+	errPasswordTooShort = errors.New("password must be at least 8 characters")
+	errPasswordTooLong  = errors.New("password must be at most 72 bytes")
+	errLastHeadCoach    = errors.New("at least one active head coach account is required")
+	errUsernameTaken    = errors.New("username is already taken")
+)
+
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return errPasswordTooShort
+	}
+	if len(password) > maxPasswordLength {
+		return errPasswordTooLong
+	}
+	return nil
+}
+
+func hashPassword(password string) (string, error) {
+	if err := validatePassword(password); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func checkPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// authenticate looks up a user by username and verifies their password.
+// Unknown users, wrong passwords and deactivated accounts all return
+// sql.ErrNoRows so callers can't tell them apart.
+func (srv *server) authenticate(ctx context.Context, username, password string) (db.User, error) {
+	user, err := srv.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			checkPassword(dummyPasswordHash, password)
+		}
+		return db.User{}, err
+	}
+	if !checkPassword(user.PasswordHash, password) || !user.Active {
+		return db.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func userResponse(u db.User) gin.H {
+	return gin.H{
+		"id":                u.ID,
+		"username":          u.Username,
+		"displayName":       u.DisplayName.String,
+		"email":             u.Email.String,
+		"role":              u.Role,
+		"active":            u.Active,
+		"passwordChangedAt": u.PasswordChangedAt.Time,
+	}
+}
+
+// ensureHeadCoachRemains returns errLastHeadCoach when a change would leave
+// the site without an active head coach.
+func (srv *server) ensureHeadCoachRemains(ctx context.Context, current db.User, role string, active bool) error {
+	if current.Role != roleHeadCoach || !current.Active || (role == roleHeadCoach && active) {
+		return nil
+	}
+	coaches, err := srv.repo.CountHeadCoaches(ctx)
+	if err != nil {
+		return err
+	}
+	if coaches <= 1 {
+		return errLastHeadCoach
+	}
+	return nil
+}
+
+func (srv *server) getUsersHandler(c *gin.Context) {
+	users, err := srv.repo.GetAllUsers(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]gin.H, len(users))
+	for i, u := range users {
+		response[i] = userResponse(u)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (srv *server) getUserByIDHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := srv.repo.GetUserByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := userResponse(user)
+	response["lockedUntil"] = nil
+	if until, locked := srv.loginLimits.lockedUntil(user.Username); locked {
+		response["lockedUntil"] = until
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type CreateUserRequest struct {
+	Username    string `json:"username" binding:"required,max=50"`
+	Password    string `json:"password" binding:"required"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email" binding:"omitempty,email"`
+	Role        string `json:"role" binding:"omitempty,oneof=head_coach assistant athlete parent"`
+}
+
+func (srv *server) createUserHandler(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Role == "" {
+		req.Role = roleAssistant
+	}
+
+	user, err := createUser(c.Request.Context(), srv.repo, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errPasswordTooShort), errors.Is(err, errPasswordTooLong):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, errUsernameTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, userResponse(user))
+}
+
+// createUser hashes the password and stores a new account. It is shared by
+// the user admin API and the create-admin command.
+func createUser(ctx context.Context, q db.Querier, req CreateUserRequest) (db.User, error) {
+	if _, err := q.GetUserByUsername(ctx, req.Username); err == nil {
+		return db.User{}, errUsernameTaken
+	} else if err != sql.ErrNoRows {
+		return db.User{}, err
+	}
+
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	result, err := q.CreateUser(ctx, db.CreateUserParams{
+		Username:     req.Username,
+		PasswordHash: hash,
+		DisplayName:  sql.NullString{String: req.DisplayName, Valid: req.DisplayName != ""},
+		Email:        sql.NullString{String: req.Email, Valid: req.Email != ""},
+		Role:         req.Role,
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return db.User{}, err
+	}
+	return q.GetUserByID(ctx, int32(id))
+}
+
+type UpdateUserRequest struct {
+	Username    string `json:"username" binding:"required,max=50"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email" binding:"omitempty,email"`
+	Role        string `json:"role" binding:"required,oneof=head_coach assistant athlete parent"`
+	Active      *bool  `json:"active"`
+}
+
+func (srv *server) updateUserHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := srv.repo.GetUserByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	active := user.Active
+	if req.Active != nil {
+		active = *req.Active
+	}
+	if err := srv.ensureHeadCoachRemains(c.Request.Context(), user, req.Role, active); err != nil {
+		if err == errLastHeadCoach {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Username != user.Username {
+		if _, err := srv.repo.GetUserByUsername(c.Request.Context(), req.Username); err == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": errUsernameTaken.Error()})
+			return
+		} else if err != sql.ErrNoRows {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	err = srv.repo.UpdateUser(c.Request.Context(), db.UpdateUserParams{
+		ID:          user.ID,
+		Username:    req.Username,
+		DisplayName: sql.NullString{String: req.DisplayName, Valid: req.DisplayName != ""},
+		Email:       sql.NullString{String: req.Email, Valid: req.Email != ""},
+		Role:        req.Role,
+		Active:      active,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !active {
+		if err := srv.repo.DeleteUserSessions(c.Request.Context(), user.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	user.Username = req.Username
+	user.DisplayName = sql.NullString{String: req.DisplayName, Valid: req.DisplayName != ""}
+	user.Email = sql.NullString{String: req.Email, Valid: req.Email != ""}
+	user.Role = req.Role
+	user.Active = active
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+func (srv *server) deleteUserHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := srv.repo.GetUserByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := srv.ensureHeadCoachRemains(c.Request.Context(), user, "", false); err != nil {
+		if err == errLastHeadCoach {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := srv.repo.DeleteUser(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
+}
+
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// resetUserPasswordHandler lets a head coach set a new password for any account
+// and signs that account out everywhere.
+func (srv *server) resetUserPasswordHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := srv.repo.GetUserByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := setPassword(c, tx, user.ID, req.Password); err != nil {
+		return
+	}
+	// A reset is for a lost or leaked password, so nothing issued under
+	// the old one keeps working.
+	if err := tx.DeleteUserSessions(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.RevokeUserAPITokens(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated"})
+}
+
+// unlockUserHandler clears a user's failed login backoff or lockout.
+func (srv *server) unlockUserHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := srv.repo.GetUserByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	srv.loginLimits.unlock(user.Username)
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked"})
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+}
+
+// changePasswordHandler lets the signed-in user change their own password
+// after proving they know the current one. Their other sessions are signed
+// out.
+func (srv *server) changePasswordHandler(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := currentUser(c)
+	session, _ := currentSession(c)
+	if !checkPassword(user.PasswordHash, req.CurrentPassword) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	if err := setPassword(c, srv.repo, user.ID, req.NewPassword); err != nil {
+		return
+	}
+	err := srv.repo.DeleteOtherUserSessions(c.Request.Context(), db.DeleteOtherUserSessionsParams{
+		UserID: user.ID,
+		ID:     session.ID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated"})
+}
+
+// setPassword hashes and stores a password through q, writing the error
+// response itself when it fails.
+func setPassword(c *gin.Context, q db.Querier, userID int32, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err != errPasswordTooShort && err != errPasswordTooLong {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return err
+	}
+
+	err = q.UpdateUserPassword(c.Request.Context(), db.UpdateUserPasswordParams{
+		ID:           userID,
+		PasswordHash: hash,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return err
+	}
+	return nil
+}
+
+type UserAthletesRequest struct {
+	AthleteIDs []int32 `json:"athleteIds" binding:"required"`
+}
+
+// putUserAthletesHandler replaces the athletes linked to an athlete or parent
+// account. An athlete account can be linked to at most one athlete.
+func (srv *server) putUserAthletesHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UserAthletesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := srv.repo.GetUserByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if user.Role != roleAthlete && user.Role != roleParent {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only athlete and parent accounts are linked to athletes"})
+		return
+	}
+	if user.Role == roleAthlete && len(req.AthleteIDs) > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An athlete account can only be linked to one athlete"})
+		return
+	}
+
+	tx, err := srv.repo.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+	if err := tx.DeleteUserAthletes(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, athleteID := range req.AthleteIDs {
+		if _, err := tx.GetAthleteByID(c.Request.Context(), athleteID); err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Athlete " + strconv.Itoa(int(athleteID)) + " not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		err := tx.AddUserAthlete(c.Request.Context(), db.AddUserAthleteParams{
+			UserID:    user.ID,
+			AthleteID: athleteID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"userId":     user.ID,
+		"athleteIds": req.AthleteIDs,
+	})
+}
+
+// getMyAthletesHandler lists the athletes linked to the signed-in account.
+func (srv *server) getMyAthletesHandler(c *gin.Context) {
+	user, _ := currentUser(c)
+	athletes, err := srv.repo.GetUserAthletes(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]gin.H, len(athletes))
+	for i, a := range athletes {
+		result[i] = gin.H{
+			"id":             a.ID,
+			"name":           a.Name,
+			"grade":          a.Grade,
+			"division":       a.Division.String,
+			"personalRecord": a.PersonalRecord.String,
+			"events":         a.Events.String,
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
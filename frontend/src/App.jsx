@@ -8,6 +8,7 @@ import MeetsPage from './pages/MeetsPage'
 import ResultsPage from './pages/ResultsPage'
 import LoginPage from './pages/LoginPage'
 import AdminPage from './pages/AdminPage'
+import SetPasswordPage from './pages/SetPasswordPage'
 
 const queryClient = new QueryClient()
 
@@ -17,12 +18,16 @@ function App() {
     return saved ? JSON.parse(saved) : null
   })
 
-  function handleLogin(userData) {
+  function handleLogin({ id, username, role }) {
+    // The session itself lives in an HttpOnly cookie; only display details
+    // are kept client-side.
+    const userData = { id, username, role }
     setUser(userData)
     localStorage.setItem('user', JSON.stringify(userData))
   }
 
   function handleLogout() {
+    fetch('/api/logout', { method: 'POST' }).catch(() => {})
     setUser(null)
     localStorage.removeItem('user')
   }
@@ -67,6 +72,8 @@ function App() {
                   )
                 }
               />
+              <Route path="/accept-invite" element={<SetPasswordPage mode="invite" />} />
+              <Route path="/reset-password" element={<SetPasswordPage mode="reset" />} />
             </Routes>
           </div>
         </div>
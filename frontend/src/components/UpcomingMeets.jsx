@@ -323,6 +323,11 @@ function MeetCard({ meet, onViewDetails, index, totalCount, gridRef }) {
           >
             {meet.name}
           </h3>
+          {meet.postponed && (
+            <span className="inline-block mt-1 px-2 py-0.5 text-xs font-bold uppercase tracking-wide rounded bg-greyhound-gold/20 text-greyhound-gold">
+              Postponed
+            </span>
+          )}
           <div className="flex items-center gap-1 text-sm text-slate-300 mt-1">
             <LocationIcon />
             <span>{meet.location}</span>
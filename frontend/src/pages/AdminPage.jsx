@@ -122,7 +122,7 @@ async function fetchAthletes() {
 }
 
 async function fetchMeets() {
-  const response = await fetch('/api/meets')
+  const response = await fetch('/api/meets?includeCancelled=true')
   if (!response.ok) throw new Error('Failed to fetch meets')
   return response.json()
 }
@@ -285,12 +285,18 @@ function EventTypeList({ onEdit, onDelete }) {
 // ATHLETES MODALS
 // =====================
 
+function saveAthletePrivacy(id, privacy) {
+  return fetch(`/api/athletes/${id}/privacy`, { method: 'PUT', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(privacy) }).then(r => r.json())
+}
+
 function AthleteForm({ athlete, onSubmit, onClose, isLoading }) {
   const [formData, setFormData] = useState({
     name: athlete?.name || '',
     grade: athlete?.grade || '',
     personalRecord: athlete?.personalRecord || '',
     events: athlete?.events || '5K',
+    nameVisibility: athlete?.nameVisibility || 'initial',
+    parentalConsent: athlete?.parentalConsent || false,
   })
 
   function handleSubmit(e) {
@@ -345,6 +351,27 @@ function AthleteForm({ athlete, onSubmit, onClose, isLoading }) {
           placeholder="e.g., 5K, 3200m"
         />
       </div>
+      <div>
+        <label className="block text-sm font-medium text-slate-300 mb-1">Public Name</label>
+        <select
+          value={formData.nameVisibility}
+          onChange={e => setFormData(prev => ({ ...prev, nameVisibility: e.target.value }))}
+          className="w-full h-11 px-4 bg-slate-800 border border-slate-700 rounded-lg text-white focus:outline-none focus:ring-2 focus:ring-greyhound-green"
+        >
+          <option value="full">Full name</option>
+          <option value="initial">First name and last initial</option>
+          <option value="hidden">Hidden</option>
+        </select>
+      </div>
+      <label className="flex items-center gap-2 text-sm text-slate-300">
+        <input
+          type="checkbox"
+          checked={formData.parentalConsent}
+          onChange={e => setFormData(prev => ({ ...prev, parentalConsent: e.target.checked }))}
+          className="h-4 w-4 rounded border-slate-700 bg-slate-800 text-greyhound-green focus:ring-greyhound-green"
+        />
+        Parent has consented to publishing the full name
+      </label>
       <div className="flex gap-3 pt-2">
         <Button type="button" variant="outline" onClick={onClose} className="flex-1">Cancel</Button>
         <Button type="submit" disabled={isLoading} className="flex-1">
@@ -662,13 +689,19 @@ function AdminPage({ user, onLogout }) {
     onSuccess: () => { queryClient.invalidateQueries(['eventTypes']); setModal({ type: null }) }
   })
 
-  // Mutations for Athletes
+  // Mutations for Athletes (privacy settings are saved separately)
   const createAthlete = useMutation({
-    mutationFn: data => fetch('/api/athletes', { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => r.json()),
+    mutationFn: async ({ nameVisibility, parentalConsent, ...data }) => {
+      const athlete = await fetch('/api/athletes', { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => r.json())
+      return saveAthletePrivacy(athlete.id, { nameVisibility, parentalConsent })
+    },
     onSuccess: () => { queryClient.invalidateQueries(['athletes']); setModal({ type: null }) }
   })
   const updateAthlete = useMutation({
-    mutationFn: ({ id, ...data }) => fetch(`/api/athletes/${id}`, { method: 'PUT', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => r.json()),
+    mutationFn: async ({ id, nameVisibility, parentalConsent, ...data }) => {
+      await fetch(`/api/athletes/${id}`, { method: 'PUT', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) })
+      return saveAthletePrivacy(id, { nameVisibility, parentalConsent })
+    },
     onSuccess: () => { queryClient.invalidateQueries(['athletes']); setModal({ type: null }) }
   })
   const deleteAthlete = useMutation({
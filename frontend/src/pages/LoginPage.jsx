@@ -1,12 +1,42 @@
-import { useState } from 'react'
-import { useNavigate } from 'react-router-dom'
+import { useEffect, useState } from 'react'
+import { Link, useNavigate, useSearchParams } from 'react-router-dom'
 
 function LoginPage({ onLogin }) {
   const [username, setUsername] = useState('')
   const [password, setPassword] = useState('')
+  const [challenge, setChallenge] = useState('')
+  const [needsCode, setNeedsCode] = useState(false)
+  const [code, setCode] = useState('')
   const [error, setError] = useState('')
   const [isLoading, setIsLoading] = useState(false)
+  const [sso, setSso] = useState(null)
+  const [searchParams] = useSearchParams()
   const navigate = useNavigate()
+  const redirect = searchParams.get('redirect') || '/admin'
+
+  // Single sign-on comes back here from the identity provider with either a
+  // session cookie already set, a two-factor challenge in a cookie, or an
+  // error.
+  useEffect(() => {
+    fetch('/api/auth/oidc')
+      .then(r => r.json())
+      .then(data => setSso(data.enabled ? data : null))
+      .catch(() => setSso(null))
+
+    if (searchParams.get('error')) {
+      setError(searchParams.get('error'))
+    } else if (searchParams.get('twoFactor')) {
+      setNeedsCode(true)
+    } else if (searchParams.get('sso')) {
+      fetch('/api/session')
+        .then(r => (r.ok ? r.json() : Promise.reject()))
+        .then(data => {
+          onLogin(data)
+          navigate(redirect)
+        })
+        .catch(() => setError('Single sign-on failed, please try again'))
+    }
+  }, [])
 
   async function handleSubmit(e) {
     e.preventDefault()
@@ -14,17 +44,29 @@ function LoginPage({ onLogin }) {
     setIsLoading(true)
 
     try {
-      const response = await fetch('/api/login', {
-        method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify({ username, password }),
-      })
+      // Accounts with two-factor authentication get a challenge back from
+      // the password step, which is traded for a session along with a code.
+      const isRecoveryCode = code.includes('-')
+      const response = needsCode
+        ? await fetch('/api/login/2fa', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(isRecoveryCode ? { challenge, recoveryCode: code } : { challenge, code }),
+          })
+        : await fetch('/api/login', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ username, password }),
+          })
 
       const data = await response.json()
 
-      if (response.ok) {
+      if (response.ok && data.twoFactorRequired) {
+        setChallenge(data.challenge)
+        setNeedsCode(true)
+      } else if (response.ok) {
         onLogin(data)
-        navigate('/admin')
+        navigate(redirect)
       } else {
         setError(data.error || 'Login failed')
       }
@@ -50,47 +92,87 @@ function LoginPage({ onLogin }) {
               </div>
             )}
 
-            <div>
-              <label htmlFor="username" className="block text-sm font-medium text-slate-300 mb-1">
-                Username <span className="text-red-400" aria-hidden="true">*</span>
-              </label>
-              <input
-                type="text"
-                id="username"
-                value={username}
-                onChange={(e) => setUsername(e.target.value)}
-                className="w-full px-4 py-2 bg-slate-700 border border-slate-600 rounded-lg text-white placeholder-slate-400 focus:outline-none focus:ring-2 focus:ring-greyhound-green focus:border-transparent"
-                placeholder="Enter username"
-                required
-                aria-required="true"
-                aria-describedby={error ? 'login-error' : undefined}
-              />
-            </div>
+            {needsCode ? (
+              <div>
+                <label htmlFor="code" className="block text-sm font-medium text-slate-300 mb-1">
+                  Authentication code <span className="text-red-400" aria-hidden="true">*</span>
+                </label>
+                <input
+                  type="text"
+                  id="code"
+                  value={code}
+                  onChange={(e) => setCode(e.target.value)}
+                  className="w-full px-4 py-2 bg-slate-700 border border-slate-600 rounded-lg text-white placeholder-slate-400 focus:outline-none focus:ring-2 focus:ring-greyhound-green focus:border-transparent"
+                  placeholder="6-digit code or recovery code"
+                  autoComplete="one-time-code"
+                  autoFocus
+                  required
+                  aria-required="true"
+                  aria-describedby={error ? 'login-error' : undefined}
+                />
+              </div>
+            ) : (
+              <>
+                <div>
+                  <label htmlFor="username" className="block text-sm font-medium text-slate-300 mb-1">
+                    Username <span className="text-red-400" aria-hidden="true">*</span>
+                  </label>
+                  <input
+                    type="text"
+                    id="username"
+                    value={username}
+                    onChange={(e) => setUsername(e.target.value)}
+                    className="w-full px-4 py-2 bg-slate-700 border border-slate-600 rounded-lg text-white placeholder-slate-400 focus:outline-none focus:ring-2 focus:ring-greyhound-green focus:border-transparent"
+                    placeholder="Enter username"
+                    required
+                    aria-required="true"
+                    aria-describedby={error ? 'login-error' : undefined}
+                  />
+                </div>
 
-            <div>
-              <label htmlFor="password" className="block text-sm font-medium text-slate-300 mb-1">
-                Password <span className="text-red-400" aria-hidden="true">*</span>
-              </label>
-              <input
-                type="password"
-                id="password"
-                value={password}
-                onChange={(e) => setPassword(e.target.value)}
-                className="w-full px-4 py-2 bg-slate-700 border border-slate-600 rounded-lg text-white placeholder-slate-400 focus:outline-none focus:ring-2 focus:ring-greyhound-green focus:border-transparent"
-                placeholder="Enter password"
-                required
-                aria-required="true"
-                aria-describedby={error ? 'login-error' : undefined}
-              />
-            </div>
+                <div>
+                  <label htmlFor="password" className="block text-sm font-medium text-slate-300 mb-1">
+                    Password <span className="text-red-400" aria-hidden="true">*</span>
+                  </label>
+                  <input
+                    type="password"
+                    id="password"
+                    value={password}
+                    onChange={(e) => setPassword(e.target.value)}
+                    className="w-full px-4 py-2 bg-slate-700 border border-slate-600 rounded-lg text-white placeholder-slate-400 focus:outline-none focus:ring-2 focus:ring-greyhound-green focus:border-transparent"
+                    placeholder="Enter password"
+                    required
+                    aria-required="true"
+                    aria-describedby={error ? 'login-error' : undefined}
+                  />
+                </div>
+              </>
+            )}
 
             <button
               type="submit"
               disabled={isLoading}
               className="w-full py-3 bg-greyhound-green text-white font-bold rounded-lg hover:bg-greyhound-green/90 focus:outline-none focus:ring-2 focus:ring-greyhound-green focus:ring-offset-2 focus:ring-offset-slate-800 disabled:opacity-50 disabled:cursor-not-allowed transition-colors"
             >
-              {isLoading ? 'Logging in...' : 'Log In'}
+              {isLoading ? 'Logging in...' : needsCode ? 'Verify' : 'Log In'}
             </button>
+
+            {!needsCode && sso && (
+              <a
+                href={`${sso.loginUrl}?redirect=${encodeURIComponent(redirect)}`}
+                className="block w-full py-3 text-center bg-slate-700 text-white font-bold rounded-lg hover:bg-slate-600 focus:outline-none focus:ring-2 focus:ring-greyhound-green focus:ring-offset-2 focus:ring-offset-slate-800 transition-colors"
+              >
+                Sign in with {sso.name}
+              </a>
+            )}
+
+            {!needsCode && (
+              <p className="text-center text-sm">
+                <Link to="/reset-password" className="text-slate-400 hover:text-white hover:underline">
+                  Forgot your password?
+                </Link>
+              </p>
+            )}
           </form>
         </div>
       </div>
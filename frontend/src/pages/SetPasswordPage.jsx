@@ -0,0 +1,159 @@
+import { useState } from 'react'
+import { Link, useSearchParams } from 'react-router-dom'
+
+const inputClass =
+  'w-full px-4 py-2 bg-slate-700 border border-slate-600 rounded-lg text-white placeholder-slate-400 focus:outline-none focus:ring-2 focus:ring-greyhound-green focus:border-transparent'
+
+// SetPasswordPage serves the links from invitation and password reset
+// emails. Opened without a token in reset mode, it asks for a reset link.
+function SetPasswordPage({ mode }) {
+  const [searchParams] = useSearchParams()
+  const token = searchParams.get('token')
+  const [identifier, setIdentifier] = useState('')
+  const [password, setPassword] = useState('')
+  const [confirm, setConfirm] = useState('')
+  const [error, setError] = useState('')
+  const [message, setMessage] = useState('')
+  const [isLoading, setIsLoading] = useState(false)
+
+  const isInvite = mode === 'invite'
+  const isRequest = !token && !isInvite
+
+  async function handleSubmit(e) {
+    e.preventDefault()
+    setError('')
+    setMessage('')
+
+    if (!isRequest && password !== confirm) {
+      setError('Passwords do not match')
+      return
+    }
+
+    setIsLoading(true)
+    try {
+      const response = isRequest
+        ? await fetch('/api/password-reset', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ identifier }),
+          })
+        : await fetch(isInvite ? '/api/invites/accept' : '/api/password-reset/confirm', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ token, password }),
+          })
+
+      const data = await response.json()
+      if (response.ok) {
+        setMessage(data.message)
+      } else {
+        setError(data.error || 'Something went wrong')
+      }
+    } catch (err) {
+      setError('Failed to connect to server')
+    } finally {
+      setIsLoading(false)
+    }
+  }
+
+  const title = isInvite ? 'Set Up Your Account' : 'Reset Password'
+  const done = message && !isRequest
+
+  return (
+    <main className="min-h-screen flex items-center justify-center px-4">
+      <div className="w-full max-w-md">
+        <div className="bg-slate-800/50 backdrop-blur border border-slate-700 rounded-xl p-8">
+          <h1 className="text-2xl font-bold text-white text-center mb-6">{title}</h1>
+
+          {isInvite && !token ? (
+            <p className="text-slate-300 text-sm">
+              This invitation link is incomplete. Open the link from your email again, or ask a coach to resend it.
+            </p>
+          ) : done ? (
+            <div className="space-y-4">
+              <p role="status" className="text-slate-300 text-sm">{message}.</p>
+              <Link to="/login" className="block text-center text-greyhound-green hover:underline">
+                Go to login
+              </Link>
+            </div>
+          ) : (
+            <form onSubmit={handleSubmit} className="space-y-4" aria-busy={isLoading}>
+              {error && (
+                <div id="set-password-error" role="alert" className="bg-red-500/10 border border-red-500/50 rounded-lg p-3">
+                  <p className="text-red-400 text-sm">{error}</p>
+                </div>
+              )}
+              {message && (
+                <p role="status" className="text-slate-300 text-sm">{message}.</p>
+              )}
+
+              {isRequest ? (
+                <div>
+                  <label htmlFor="identifier" className="block text-sm font-medium text-slate-300 mb-1">
+                    Username or email <span className="text-red-400" aria-hidden="true">*</span>
+                  </label>
+                  <input
+                    type="text"
+                    id="identifier"
+                    value={identifier}
+                    onChange={(e) => setIdentifier(e.target.value)}
+                    className={inputClass}
+                    required
+                    aria-required="true"
+                    aria-describedby={error ? 'set-password-error' : undefined}
+                  />
+                </div>
+              ) : (
+                <>
+                  <div>
+                    <label htmlFor="password" className="block text-sm font-medium text-slate-300 mb-1">
+                      New password <span className="text-red-400" aria-hidden="true">*</span>
+                    </label>
+                    <input
+                      type="password"
+                      id="password"
+                      value={password}
+                      onChange={(e) => setPassword(e.target.value)}
+                      className={inputClass}
+                      placeholder="At least 8 characters"
+                      autoComplete="new-password"
+                      minLength={8}
+                      required
+                      aria-required="true"
+                      aria-describedby={error ? 'set-password-error' : undefined}
+                    />
+                  </div>
+                  <div>
+                    <label htmlFor="confirm" className="block text-sm font-medium text-slate-300 mb-1">
+                      Confirm password <span className="text-red-400" aria-hidden="true">*</span>
+                    </label>
+                    <input
+                      type="password"
+                      id="confirm"
+                      value={confirm}
+                      onChange={(e) => setConfirm(e.target.value)}
+                      className={inputClass}
+                      autoComplete="new-password"
+                      required
+                      aria-required="true"
+                    />
+                  </div>
+                </>
+              )}
+
+              <button
+                type="submit"
+                disabled={isLoading}
+                className="w-full py-3 bg-greyhound-green text-white font-bold rounded-lg hover:bg-greyhound-green/90 focus:outline-none focus:ring-2 focus:ring-greyhound-green focus:ring-offset-2 focus:ring-offset-slate-800 disabled:opacity-50 disabled:cursor-not-allowed transition-colors"
+              >
+                {isLoading ? 'Sending...' : isRequest ? 'Email Reset Link' : 'Set Password'}
+              </button>
+            </form>
+          )}
+        </div>
+      </div>
+    </main>
+  )
+}
+
+export default SetPasswordPage